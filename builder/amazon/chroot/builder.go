@@ -8,7 +8,10 @@ import (
 	"errors"
 	"log"
 	"runtime"
+	"strconv"
+	"strings"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/mitchellh/multistep"
@@ -30,13 +33,16 @@ type Config struct {
 	awscommon.AMIConfig       `mapstructure:",squash"`
 	awscommon.AccessConfig    `mapstructure:",squash"`
 
+	AMIArchitecture   string                     `mapstructure:"ami_architecture"`
 	ChrootMounts      [][]string                 `mapstructure:"chroot_mounts"`
 	CommandWrapper    string                     `mapstructure:"command_wrapper"`
 	CopyFiles         []string                   `mapstructure:"copy_files"`
 	DevicePath        string                     `mapstructure:"device_path"`
 	FromScratch       bool                       `mapstructure:"from_scratch"`
+	FromVolume        string                     `mapstructure:"from_volume"`
+	Isolation         string                     `mapstructure:"isolation"`
 	MountOptions      []string                   `mapstructure:"mount_options"`
-	MountPartition    int                        `mapstructure:"mount_partition"`
+	MountPartition    string                     `mapstructure:"mount_partition"`
 	MountPath         string                     `mapstructure:"mount_path"`
 	PostMountCommands []string                   `mapstructure:"post_mount_commands"`
 	PreMountCommands  []string                   `mapstructure:"pre_mount_commands"`
@@ -44,12 +50,51 @@ type Config struct {
 	RootVolumeSize    int64                      `mapstructure:"root_volume_size"`
 	SourceAmi         string                     `mapstructure:"source_ami"`
 	SourceAmiFilter   awscommon.AmiFilterOptions `mapstructure:"source_ami_filter"`
+	SweepOrphans      bool                       `mapstructure:"sweep_orphaned_resources"`
 
 	ctx interpolate.Context
 }
 
+// hasSourceImage reports whether the root volume and registration
+// parameters come from a source AMI, rather than from the template.
+func (c *Config) hasSourceImage() bool {
+	return !c.FromScratch && c.FromVolume == ""
+}
+
 type wrappedCommandTemplate struct {
+	buildInfoData
+
 	Command string
+	Device  string
+}
+
+// buildInfoData is embedded into the template data of command_wrapper,
+// mount_path and the mount commands so they can refer to the current build.
+type buildInfoData struct {
+	AMIName      string
+	Architecture string
+	BuildName    string
+}
+
+func newBuildInfoData(state multistep.StateBag) buildInfoData {
+	config := state.Get("config").(*Config)
+
+	// Without a source image the architecture comes from the template.
+	// Source images that don't report one are treated as x86_64.
+	arch := ec2.ArchitectureValuesX8664
+	if !config.hasSourceImage() {
+		arch = config.AMIArchitecture
+	} else if raw, ok := state.GetOk("source_image"); ok {
+		if a := aws.StringValue(raw.(*ec2.Image).Architecture); a != "" {
+			arch = a
+		}
+	}
+
+	return buildInfoData{
+		AMIName:      config.AMIName,
+		Architecture: arch,
+		BuildName:    config.PackerBuildName,
+	}
 }
 
 type Builder struct {
@@ -59,6 +104,7 @@ type Builder struct {
 
 func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 	b.config.ctx.Funcs = awscommon.TemplateFuncs
+	var unknownKeys []string
 	err := config.Decode(&b.config, &config.DecodeOpts{
 		Interpolate:        true,
 		InterpolateContext: &b.config.ctx,
@@ -73,6 +119,7 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 				"mount_path",
 			},
 		},
+		UnknownKeys: &unknownKeys,
 	}, raws...)
 	if err != nil {
 		return nil, err
@@ -87,7 +134,12 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 		b.config.CopyFiles = make([]string, 0)
 	}
 
-	if len(b.config.ChrootMounts) == 0 {
+	if b.config.Isolation == "" {
+		b.config.Isolation = "chroot"
+	}
+
+	// systemd-nspawn sets up /proc, /sys and /dev itself
+	if len(b.config.ChrootMounts) == 0 && b.config.Isolation != "nspawn" {
 		b.config.ChrootMounts = [][]string{
 			{"proc", "proc", "/proc"},
 			{"sysfs", "sysfs", "/sys"},
@@ -109,16 +161,27 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 		b.config.MountPath = "/mnt/packer-amazon-chroot-volumes/{{.Device}}"
 	}
 
-	if b.config.MountPartition == 0 {
-		b.config.MountPartition = 1
+	if b.config.MountPartition == "" {
+		b.config.MountPartition = "1"
 	}
 
 	// Accumulate any errors or warnings
 	var errs *packer.MultiError
-	var warns []string
+	warns := config.UnknownKeyWarnings(unknownKeys)
 
 	errs = packer.MultiErrorAppend(errs, b.config.AccessConfig.Prepare(&b.config.ctx)...)
 	errs = packer.MultiErrorAppend(errs, b.config.AMIConfig.Prepare(&b.config.ctx)...)
+	warns = append(warns, b.config.AMIConfig.Warnings()...)
+
+	if n, err := strconv.Atoi(b.config.MountPartition); err != nil || n < 0 {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("mount_partition must be a non-negative partition number."))
+	}
+
+	if b.config.Isolation != "chroot" && b.config.Isolation != "nspawn" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("isolation must be one of 'chroot' or 'nspawn'."))
+	}
 
 	for _, mounts := range b.config.ChrootMounts {
 		if len(mounts) != 3 {
@@ -128,7 +191,45 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 		}
 	}
 
-	if b.config.FromScratch {
+	if b.config.FromScratch && b.config.FromVolume != "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("from_scratch and from_volume can not be used together."))
+	}
+
+	if !b.config.hasSourceImage() {
+		if b.config.AMIArchitecture == "" {
+			b.config.AMIArchitecture = ec2.ArchitectureValuesX8664
+		}
+		if b.config.AMIArchitecture != ec2.ArchitectureValuesX8664 &&
+			b.config.AMIArchitecture != ec2.ArchitectureValuesI386 {
+			errs = packer.MultiErrorAppend(
+				errs, errors.New(`ami_architecture must be "x86_64" or "i386".`))
+		}
+	} else if b.config.AMIArchitecture != "" {
+		warns = append(warns, "ami_architecture is unused when from_scratch and from_volume are not set")
+	}
+
+	if b.config.FromVolume != "" {
+		if !strings.HasPrefix(b.config.FromVolume, "snap-") && !strings.HasPrefix(b.config.FromVolume, "vol-") {
+			errs = packer.MultiErrorAppend(
+				errs, errors.New("from_volume must be a snapshot or volume ID."))
+		}
+		if b.config.SourceAmi != "" || !b.config.SourceAmiFilter.Empty() {
+			warns = append(warns, "source_ami and source_ami_filter are unused when from_volume is set")
+		}
+		if b.config.AMIVirtType == "" {
+			errs = packer.MultiErrorAppend(
+				errs, errors.New("ami_virtualization_type is required with from_volume."))
+		}
+		if b.config.RootDeviceName == "" {
+			errs = packer.MultiErrorAppend(
+				errs, errors.New("root_device_name is required with from_volume."))
+		}
+		if len(b.config.AMIMappings) == 0 {
+			errs = packer.MultiErrorAppend(
+				errs, errors.New("ami_block_device_mappings is required with from_volume."))
+		}
+	} else if b.config.FromScratch {
 		if b.config.SourceAmi != "" || !b.config.SourceAmiFilter.Empty() {
 			warns = append(warns, "source_ami and source_ami_filter are unused when from_scratch is true")
 		}
@@ -183,22 +284,51 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 		return nil, err
 	}
 
+	// The volume is always created, attached and snapshotted by the account
+	// of this instance, even if the AMI is registered by an assumed role.
+	var instanceEc2conn *ec2.EC2
+	if b.config.RoleARN != "" {
+		sourceConfig, err := b.config.SourceConfig()
+		if err != nil {
+			return nil, err
+		}
+		sourceSession, err := session.NewSession(sourceConfig)
+		if err != nil {
+			return nil, err
+		}
+		instanceEc2conn = ec2.New(sourceSession)
+	}
+
 	session, err := session.NewSession(config)
 	if err != nil {
 		return nil, err
 	}
 	ec2conn := ec2.New(session)
+	if instanceEc2conn == nil {
+		instanceEc2conn = ec2conn
+	}
+
+	// Setup the state bag and initial state for the steps
+	state := new(multistep.BasicStateBag)
 
 	wrappedCommand := func(command string) (string, error) {
+		var device string
+		if raw, ok := state.GetOk("device"); ok {
+			device = raw.(string)
+		}
+
 		ctx := b.config.ctx
-		ctx.Data = &wrappedCommandTemplate{Command: command}
+		ctx.Data = &wrappedCommandTemplate{
+			buildInfoData: newBuildInfoData(state),
+			Command:       command,
+			Device:        device,
+		}
 		return interpolate.Render(b.config.CommandWrapper, &ctx)
 	}
 
-	// Setup the state bag and initial state for the steps
-	state := new(multistep.BasicStateBag)
 	state.Put("config", &b.config)
 	state.Put("ec2", ec2conn)
+	state.Put("instance_ec2", instanceEc2conn)
 	state.Put("hook", hook)
 	state.Put("ui", ui)
 	state.Put("wrappedCommand", CommandWrapper(wrappedCommand))
@@ -212,7 +342,11 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 		&StepInstanceInfo{},
 	}
 
-	if !b.config.FromScratch {
+	if b.config.SweepOrphans {
+		steps = append(steps, &StepSweepOrphans{})
+	}
+
+	if b.config.hasSourceImage() {
 		steps = append(steps,
 			&awscommon.StepSourceAMIInfo{
 				SourceAmi:          b.config.SourceAmi,
@@ -221,6 +355,12 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 			},
 			&StepCheckRootDevice{},
 		)
+	} else if b.config.FromVolume != "" {
+		steps = append(steps,
+			&StepSourceVolumeInfo{
+				FromVolume: b.config.FromVolume,
+			},
+		)
 	}
 
 	steps = append(steps,
@@ -238,6 +378,9 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 			MountOptions:   b.config.MountOptions,
 			MountPartition: b.config.MountPartition,
 		},
+		&StepGrowFilesystem{
+			MountPartition: b.config.MountPartition,
+		},
 		&StepPostMountCommands{
 			Commands: b.config.PostMountCommands,
 		},
@@ -246,10 +389,15 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 		&StepChrootProvision{},
 		&StepEarlyCleanup{},
 		&StepSnapshot{},
+		&StepShareSnapshot{
+			RoleARN: b.config.RoleARN,
+		},
 		&awscommon.StepDeregisterAMI{
+			AccessConfig:        &b.config.AccessConfig,
 			ForceDeregister:     b.config.AMIForceDeregister,
 			ForceDeleteSnapshot: b.config.AMIForceDeleteSnapshot,
 			AMIName:             b.config.AMIName,
+			Regions:             b.config.AMIRegions,
 		},
 		&StepRegisterAMI{
 			RootVolumeSize: b.config.RootVolumeSize,
@@ -257,6 +405,7 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 		&awscommon.StepAMIRegionCopy{
 			AccessConfig: &b.config.AccessConfig,
 			Regions:      b.config.AMIRegions,
+			RegionKeyIds: b.config.AMIRegionKMSKeyIDs,
 			Name:         b.config.AMIName,
 		},
 		&awscommon.StepModifyAMIAttributes{
@@ -294,11 +443,40 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 		Amis:           state.Get("amis").(map[string]string),
 		BuilderIdValue: BuilderId,
 		Conn:           ec2conn,
+		StateData:      artifactStateData(state),
 	}
 
 	return artifact, nil
 }
 
+// artifactStateData collects what the steps learned about the volume,
+// device and registration so post-processors can refer to it.
+func artifactStateData(state multistep.StateBag) map[string]interface{} {
+	data := make(map[string]interface{})
+	keys := map[string]string{
+		"device":       "device",
+		"device_mount": "deviceMount",
+		"mount_path":   "mount_path",
+		"snapshot_id":  "snapshot_id",
+		"snapshots":    "snapshots",
+		"volume_id":    "volume_id",
+	}
+	for name, key := range keys {
+		if v, ok := state.GetOk(key); ok {
+			data[name] = v
+		}
+	}
+
+	if raw, ok := state.GetOk("register_image_input"); ok {
+		input := raw.(*ec2.RegisterImageInput)
+		data["architecture"] = aws.StringValue(input.Architecture)
+		data["root_device_name"] = aws.StringValue(input.RootDeviceName)
+		data["virtualization_type"] = aws.StringValue(input.VirtualizationType)
+	}
+
+	return data
+}
+
 func (b *Builder) Cancel() {
 	if b.runner != nil {
 		log.Println("Cancelling the step runner...")
@@ -1,8 +1,13 @@
 package chroot
 
 import (
-	"github.com/mitchellh/packer/packer"
+	"reflect"
 	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/mitchellh/multistep"
+	"github.com/mitchellh/packer/packer"
 )
 
 func testConfig() map[string]interface{} {
@@ -117,3 +122,175 @@ func TestBuilderPrepare_CommandWrapper(t *testing.T) {
 		t.Errorf("err: %s", err)
 	}
 }
+
+func TestBuilderPrepare_MountPartition(t *testing.T) {
+	b := &Builder{}
+	config := testConfig()
+
+	warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if b.config.MountPartition != "1" {
+		t.Fatalf("bad: %s", b.config.MountPartition)
+	}
+
+	config["mount_partition"] = 0
+	b = &Builder{}
+	warnings, err = b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if b.config.MountPartition != "0" {
+		t.Fatalf("bad: %s", b.config.MountPartition)
+	}
+
+	config["mount_partition"] = "foo"
+	b = &Builder{}
+	warnings, err = b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestBuilderPrepare_FromVolume(t *testing.T) {
+	b := &Builder{}
+	config := testConfig()
+	delete(config, "source_ami")
+	config["from_volume"] = "snap-1234"
+
+	// Missing registration settings
+	warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+
+	config["ami_virtualization_type"] = "hvm"
+	config["root_device_name"] = "/dev/sda1"
+	config["ami_block_device_mappings"] = []map[string]interface{}{
+		{"device_name": "/dev/sda1", "delete_on_termination": true},
+	}
+	b = &Builder{}
+	warnings, err = b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if b.config.AMIArchitecture != "x86_64" {
+		t.Fatalf("bad: %s", b.config.AMIArchitecture)
+	}
+
+	config["ami_architecture"] = "sparc"
+	b = &Builder{}
+	_, err = b.Prepare(config)
+	if err == nil {
+		t.Fatal("should have error")
+	}
+
+	config["ami_architecture"] = "i386"
+	config["from_volume"] = "vol-1234"
+	b = &Builder{}
+	warnings, err = b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if b.config.AMIArchitecture != "i386" {
+		t.Fatalf("bad: %s", b.config.AMIArchitecture)
+	}
+
+	config["from_volume"] = "ami-1234"
+	b = &Builder{}
+	_, err = b.Prepare(config)
+	if err == nil {
+		t.Fatal("should have error")
+	}
+
+	config["from_volume"] = "snap-1234"
+	config["from_scratch"] = true
+	config["pre_mount_commands"] = []string{"true"}
+	b = &Builder{}
+	_, err = b.Prepare(config)
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestBuilderPrepare_Isolation(t *testing.T) {
+	b := &Builder{}
+	config := testConfig()
+
+	warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if b.config.Isolation != "chroot" {
+		t.Fatalf("bad: %s", b.config.Isolation)
+	}
+	if len(b.config.ChrootMounts) == 0 {
+		t.Fatal("should have default chroot mounts")
+	}
+
+	config["isolation"] = "nspawn"
+	b = &Builder{}
+	warnings, err = b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(b.config.ChrootMounts) != 0 {
+		t.Fatalf("bad: %#v", b.config.ChrootMounts)
+	}
+
+	config["isolation"] = "jail"
+	b = &Builder{}
+	_, err = b.Prepare(config)
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestArtifactStateData(t *testing.T) {
+	state := new(multistep.BasicStateBag)
+	state.Put("device", "/dev/xvdf")
+	state.Put("volume_id", "vol-1234")
+	state.Put("register_image_input", &ec2.RegisterImageInput{
+		Architecture:       aws.String("x86_64"),
+		RootDeviceName:     aws.String("/dev/sda1"),
+		VirtualizationType: aws.String("hvm"),
+	})
+
+	data := artifactStateData(state)
+	expected := map[string]interface{}{
+		"device":              "/dev/xvdf",
+		"volume_id":           "vol-1234",
+		"architecture":        "x86_64",
+		"root_device_name":    "/dev/sda1",
+		"virtualization_type": "hvm",
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Fatalf("bad: %#v", data)
+	}
+}
@@ -20,11 +20,14 @@ import (
 type Communicator struct {
 	Chroot     string
 	CmdWrapper CommandWrapper
+
+	// Isolation is "chroot" (the default) or "nspawn" to run commands in
+	// a systemd-nspawn container rooted at Chroot instead.
+	Isolation string
 }
 
 func (c *Communicator) Start(cmd *packer.RemoteCmd) error {
-	command, err := c.CmdWrapper(
-		fmt.Sprintf("chroot %s /bin/sh -c \"%s\"", c.Chroot, cmd.Command))
+	command, err := c.CmdWrapper(c.isolatedCommand(cmd.Command))
 	if err != nil {
 		return err
 	}
@@ -61,6 +64,18 @@ func (c *Communicator) Start(cmd *packer.RemoteCmd) error {
 	return nil
 }
 
+// isolatedCommand returns the host command that runs command within the
+// chroot, using the configured isolation.
+func (c *Communicator) isolatedCommand(command string) string {
+	if c.Isolation == "nspawn" {
+		return fmt.Sprintf(
+			"systemd-nspawn --quiet --register=no --as-pid2 --directory=%s /bin/sh -c \"%s\"",
+			c.Chroot, command)
+	}
+
+	return fmt.Sprintf("chroot %s /bin/sh -c \"%s\"", c.Chroot, command)
+}
+
 func (c *Communicator) Upload(dst string, r io.Reader, fi *os.FileInfo) error {
 	dst = filepath.Join(c.Chroot, dst)
 	log.Printf("Uploading to chroot dir: %s", dst)
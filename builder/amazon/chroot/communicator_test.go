@@ -12,3 +12,16 @@ func TestCommunicator_ImplementsCommunicator(t *testing.T) {
 		t.Fatalf("Communicator should be a communicator")
 	}
 }
+
+func TestCommunicator_isolatedCommand(t *testing.T) {
+	c := &Communicator{Chroot: "/mnt/foo"}
+	if cmd := c.isolatedCommand("ls"); cmd != `chroot /mnt/foo /bin/sh -c "ls"` {
+		t.Fatalf("bad: %s", cmd)
+	}
+
+	c.Isolation = "nspawn"
+	expected := `systemd-nspawn --quiet --register=no --as-pid2 --directory=/mnt/foo /bin/sh -c "ls"`
+	if cmd := c.isolatedCommand("ls"); cmd != expected {
+		t.Fatalf("bad: %s", cmd)
+	}
+}
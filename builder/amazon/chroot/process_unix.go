@@ -0,0 +1,13 @@
+// +build !windows
+
+package chroot
+
+import (
+	"syscall"
+)
+
+// processExists reports whether a process with the given PID is running.
+func processExists(pid int) bool {
+	// Signal 0 only checks whether the process exists
+	return syscall.Kill(pid, syscall.Signal(0)) != syscall.ESRCH
+}
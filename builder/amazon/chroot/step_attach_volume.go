@@ -24,7 +24,7 @@ type StepAttachVolume struct {
 }
 
 func (s *StepAttachVolume) Run(state multistep.StateBag) multistep.StepAction {
-	ec2conn := state.Get("ec2").(*ec2.EC2)
+	ec2conn := state.Get("instance_ec2").(*ec2.EC2)
 	device := state.Get("device").(string)
 	instance := state.Get("instance").(*ec2.Instance)
 	ui := state.Get("ui").(packer.Ui)
@@ -34,10 +34,13 @@ func (s *StepAttachVolume) Run(state multistep.StateBag) multistep.StepAction {
 	attachVolume := strings.Replace(device, "/xvd", "/sd", 1)
 
 	ui.Say(fmt.Sprintf("Attaching the root volume to %s", attachVolume))
-	_, err := ec2conn.AttachVolume(&ec2.AttachVolumeInput{
-		InstanceId: instance.InstanceId,
-		VolumeId:   &volumeId,
-		Device:     &attachVolume,
+	err := awscommon.RetryEventualConsistency(func() error {
+		_, err := ec2conn.AttachVolume(&ec2.AttachVolumeInput{
+			InstanceId: instance.InstanceId,
+			VolumeId:   &volumeId,
+			Device:     &attachVolume,
+		})
+		return err
 	})
 	if err != nil {
 		err := fmt.Errorf("Error attaching volume: %s", err)
@@ -103,11 +106,14 @@ func (s *StepAttachVolume) CleanupFunc(state multistep.StateBag) error {
 		return nil
 	}
 
-	ec2conn := state.Get("ec2").(*ec2.EC2)
+	ec2conn := state.Get("instance_ec2").(*ec2.EC2)
 	ui := state.Get("ui").(packer.Ui)
 
 	ui.Say("Detaching EBS volume...")
-	_, err := ec2conn.DetachVolume(&ec2.DetachVolumeInput{VolumeId: &s.volumeId})
+	err := awscommon.RetryEventualConsistency(func() error {
+		_, err := ec2conn.DetachVolume(&ec2.DetachVolumeInput{VolumeId: &s.volumeId})
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("Error detaching EBS volume: %s", err)
 	}
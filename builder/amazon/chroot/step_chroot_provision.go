@@ -12,6 +12,7 @@ type StepChrootProvision struct {
 }
 
 func (s *StepChrootProvision) Run(state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
 	hook := state.Get("hook").(packer.Hook)
 	mountPath := state.Get("mount_path").(string)
 	ui := state.Get("ui").(packer.Ui)
@@ -21,6 +22,7 @@ func (s *StepChrootProvision) Run(state multistep.StateBag) multistep.StepAction
 	comm := &Communicator{
 		Chroot:     mountPath,
 		CmdWrapper: wrappedCommand,
+		Isolation:  config.Isolation,
 	}
 
 	// Provision
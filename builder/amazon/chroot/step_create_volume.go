@@ -12,10 +12,11 @@ import (
 )
 
 // StepCreateVolume creates a new volume from the snapshot of the root
-// device of the AMI.
+// device of the AMI, or from the source snapshot when using from_volume.
 //
 // Produces:
 //   volume_id string - The ID of the created volume
+//   root_volume_resized bool - Whether the volume is larger than its source
 type StepCreateVolume struct {
 	volumeId       string
 	RootVolumeSize int64
@@ -23,7 +24,7 @@ type StepCreateVolume struct {
 
 func (s *StepCreateVolume) Run(state multistep.StateBag) multistep.StepAction {
 	config := state.Get("config").(*Config)
-	ec2conn := state.Get("ec2").(*ec2.EC2)
+	ec2conn := state.Get("instance_ec2").(*ec2.EC2)
 	instance := state.Get("instance").(*ec2.Instance)
 	ui := state.Get("ui").(packer.Ui)
 
@@ -34,6 +35,22 @@ func (s *StepCreateVolume) Run(state multistep.StateBag) multistep.StepAction {
 			Size:             aws.Int64(s.RootVolumeSize),
 			VolumeType:       aws.String(ec2.VolumeTypeGp2),
 		}
+	} else if config.FromVolume != "" {
+		snapshot := state.Get("source_snapshot").(*ec2.Snapshot)
+
+		ui.Say("Creating the root volume...")
+		vs := *snapshot.VolumeSize
+		if s.RootVolumeSize > vs {
+			vs = s.RootVolumeSize
+			state.Put("root_volume_resized", true)
+		}
+
+		createVolume = &ec2.CreateVolumeInput{
+			AvailabilityZone: instance.Placement.AvailabilityZone,
+			Size:             aws.Int64(vs),
+			SnapshotId:       snapshot.SnapshotId,
+			VolumeType:       aws.String(ec2.VolumeTypeGp2),
+		}
 	} else {
 		// Determine the root device snapshot
 		image := state.Get("source_image").(*ec2.Image)
@@ -57,6 +74,7 @@ func (s *StepCreateVolume) Run(state multistep.StateBag) multistep.StepAction {
 		vs := *rootDevice.Ebs.VolumeSize
 		if s.RootVolumeSize > *rootDevice.Ebs.VolumeSize {
 			vs = s.RootVolumeSize
+			state.Put("root_volume_resized", true)
 		}
 
 		createVolume = &ec2.CreateVolumeInput{
@@ -82,6 +100,10 @@ func (s *StepCreateVolume) Run(state multistep.StateBag) multistep.StepAction {
 	s.volumeId = *createVolumeResp.VolumeId
 	log.Printf("Volume ID: %s", s.volumeId)
 
+	if err := tagBuildResource(ec2conn, s.volumeId, *instance.InstanceId); err != nil {
+		log.Printf("Error tagging volume, it can't be swept if orphaned: %s", err)
+	}
+
 	// Wait for the volume to become ready
 	stateChange := awscommon.StateChangeConf{
 		Pending:   []string{"creating"},
@@ -115,7 +137,7 @@ func (s *StepCreateVolume) Cleanup(state multistep.StateBag) {
 		return
 	}
 
-	ec2conn := state.Get("ec2").(*ec2.EC2)
+	ec2conn := state.Get("instance_ec2").(*ec2.EC2)
 	ui := state.Get("ui").(packer.Ui)
 
 	ui.Say("Deleting the created EBS volume...")
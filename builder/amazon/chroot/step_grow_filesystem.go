@@ -0,0 +1,107 @@
+package chroot
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/mitchellh/multistep"
+	"github.com/mitchellh/packer/packer"
+)
+
+// StepGrowFilesystem grows the root partition and filesystem to fill the
+// volume when root_volume_size made the volume larger than its source.
+// Otherwise the extra space would be unusable in launched instances.
+type StepGrowFilesystem struct {
+	MountPartition string
+}
+
+func (s *StepGrowFilesystem) Run(state multistep.StateBag) multistep.StepAction {
+	if resized, ok := state.GetOk("root_volume_resized"); !ok || !resized.(bool) {
+		return multistep.ActionContinue
+	}
+
+	device := state.Get("device").(string)
+	deviceMount := state.Get("deviceMount").(string)
+	mountPath := state.Get("mount_path").(string)
+	ui := state.Get("ui").(packer.Ui)
+	wrappedCommand := state.Get("wrappedCommand").(CommandWrapper)
+
+	ui.Say("Growing the root filesystem to fill the volume...")
+
+	if deviceMount != device {
+		// growpart exits with 1 when there was nothing to grow, which is fine
+		_, status, err := runWrappedCommand(wrappedCommand,
+			fmt.Sprintf("growpart %s %s", device, s.MountPartition))
+		if err != nil && status != 1 {
+			err := fmt.Errorf("Error growing root partition: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+	}
+
+	fsType, _, err := runWrappedCommand(wrappedCommand,
+		fmt.Sprintf("blkid -o value -s TYPE %s", deviceMount))
+	if err != nil {
+		err := fmt.Errorf("Error detecting root filesystem type: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	var growCommand string
+	switch fsType = strings.TrimSpace(fsType); fsType {
+	case "ext2", "ext3", "ext4":
+		growCommand = fmt.Sprintf("resize2fs %s", deviceMount)
+	case "xfs":
+		growCommand = fmt.Sprintf("xfs_growfs %s", mountPath)
+	default:
+		ui.Message(fmt.Sprintf(
+			"Not growing unsupported filesystem type '%s'", fsType))
+		return multistep.ActionContinue
+	}
+
+	ui.Message(fmt.Sprintf("Growing %s filesystem on %s", fsType, deviceMount))
+	if _, _, err := runWrappedCommand(wrappedCommand, growCommand); err != nil {
+		err := fmt.Errorf("Error growing root filesystem: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *StepGrowFilesystem) Cleanup(state multistep.StateBag) {}
+
+// runWrappedCommand runs the wrapped form of command and returns its
+// standard output and exit status.
+func runWrappedCommand(wrappedCommand CommandWrapper, command string) (string, int, error) {
+	command, err := wrappedCommand(command)
+	if err != nil {
+		return "", 0, err
+	}
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := ShellCommand(command)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	log.Printf("Executing: %s", command)
+	if err := cmd.Run(); err != nil {
+		exitStatus := 0
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+				exitStatus = status.ExitStatus()
+			}
+		}
+		return stdout.String(), exitStatus, fmt.Errorf(
+			"%s\nStderr: %s", err, stderr.String())
+	}
+
+	return stdout.String(), 0, nil
+}
@@ -0,0 +1,39 @@
+package chroot
+
+import (
+	"testing"
+
+	"github.com/mitchellh/multistep"
+)
+
+func TestStepGrowFilesystem_notResized(t *testing.T) {
+	state := new(multistep.BasicStateBag)
+	step := &StepGrowFilesystem{MountPartition: "1"}
+
+	// Nothing else is in the state bag, so this would panic if it did work
+	if action := step.Run(state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+}
+
+func TestRunWrappedCommand(t *testing.T) {
+	wrapper := func(command string) (string, error) {
+		return command, nil
+	}
+
+	stdout, status, err := runWrappedCommand(wrapper, "echo foo")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if stdout != "foo\n" || status != 0 {
+		t.Fatalf("bad: %q %d", stdout, status)
+	}
+
+	_, status, err = runWrappedCommand(wrapper, "exit 3")
+	if err == nil {
+		t.Fatal("should have error")
+	}
+	if status != 3 {
+		t.Fatalf("bad status: %d", status)
+	}
+}
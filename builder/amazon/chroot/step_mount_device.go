@@ -15,6 +15,8 @@ import (
 )
 
 type mountPathData struct {
+	buildInfoData
+
 	Device string
 }
 
@@ -25,7 +27,7 @@ type mountPathData struct {
 //   mount_device_cleanup CleanupFunc - To perform early cleanup
 type StepMountDevice struct {
 	MountOptions   []string
-	MountPartition int
+	MountPartition string
 
 	mountPath string
 }
@@ -37,7 +39,7 @@ func (s *StepMountDevice) Run(state multistep.StateBag) multistep.StepAction {
 	wrappedCommand := state.Get("wrappedCommand").(CommandWrapper)
 
 	var virtualizationType string
-	if config.FromScratch {
+	if !config.hasSourceImage() {
 		virtualizationType = config.AMIVirtType
 	} else {
 		image := state.Get("source_image").(*ec2.Image)
@@ -46,7 +48,10 @@ func (s *StepMountDevice) Run(state multistep.StateBag) multistep.StepAction {
 	}
 
 	ctx := config.ctx
-	ctx.Data = &mountPathData{Device: filepath.Base(device)}
+	ctx.Data = &mountPathData{
+		buildInfoData: newBuildInfoData(state),
+		Device:        filepath.Base(device),
+	}
 	mountPath, err := interpolate.Render(config.MountPath, &ctx)
 
 	if err != nil {
@@ -73,9 +78,11 @@ func (s *StepMountDevice) Run(state multistep.StateBag) multistep.StepAction {
 		return multistep.ActionHalt
 	}
 
+	// A partition of "0" means the filesystem lives directly on the
+	// device rather than inside a partition table.
 	deviceMount := device
-	if virtualizationType == "hvm" {
-		deviceMount = fmt.Sprintf("%s%d", device, s.MountPartition)
+	if virtualizationType == "hvm" && s.MountPartition != "0" {
+		deviceMount = fmt.Sprintf("%s%s", device, s.MountPartition)
 	}
 	state.Put("deviceMount", deviceMount)
 
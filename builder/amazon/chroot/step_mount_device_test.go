@@ -1,6 +1,13 @@
 package chroot
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/mitchellh/multistep"
+	"github.com/mitchellh/packer/template/interpolate"
+)
 
 func TestMountDeviceCleanupFunc_ImplementsCleanupFunc(t *testing.T) {
 	var raw interface{}
@@ -9,3 +16,39 @@ func TestMountDeviceCleanupFunc_ImplementsCleanupFunc(t *testing.T) {
 		t.Fatalf("cleanup func should be a CleanupFunc")
 	}
 }
+
+func TestMountPathData_BuildInfo(t *testing.T) {
+	config := &Config{}
+	config.PackerBuildName = "chroot"
+	config.AMIName = "my-ami"
+
+	state := new(multistep.BasicStateBag)
+	state.Put("config", config)
+	state.Put("source_image", &ec2.Image{Architecture: aws.String("arm64")})
+
+	ctx := &interpolate.Context{
+		Data: &mountPathData{
+			buildInfoData: newBuildInfoData(state),
+			Device:        "xvdf",
+		},
+	}
+	result, err := interpolate.Render(
+		"/mnt/{{.BuildName}}/{{.AMIName}}/{{.Architecture}}/{{.Device}}", ctx)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if result != "/mnt/chroot/my-ami/arm64/xvdf" {
+		t.Fatalf("bad: %s", result)
+	}
+}
+
+func TestNewBuildInfoData_noArchitecture(t *testing.T) {
+	state := new(multistep.BasicStateBag)
+	state.Put("config", &Config{})
+	state.Put("source_image", &ec2.Image{})
+
+	data := newBuildInfoData(state)
+	if data.Architecture != ec2.ArchitectureValuesX8664 {
+		t.Fatalf("bad: %s", data.Architecture)
+	}
+}
@@ -6,6 +6,8 @@ import (
 )
 
 type postMountCommandsData struct {
+	buildInfoData
+
 	Device    string
 	MountPath string
 }
@@ -29,8 +31,9 @@ func (s *StepPostMountCommands) Run(state multistep.StateBag) multistep.StepActi
 
 	ctx := config.ctx
 	ctx.Data = &postMountCommandsData{
-		Device:    device,
-		MountPath: mountPath,
+		buildInfoData: newBuildInfoData(state),
+		Device:        device,
+		MountPath:     mountPath,
 	}
 
 	ui.Say("Running post-mount commands...")
@@ -6,6 +6,8 @@ import (
 )
 
 type preMountCommandsData struct {
+	buildInfoData
+
 	Device string
 }
 
@@ -25,7 +27,10 @@ func (s *StepPreMountCommands) Run(state multistep.StateBag) multistep.StepActio
 	}
 
 	ctx := config.ctx
-	ctx.Data = &preMountCommandsData{Device: device}
+	ctx.Data = &preMountCommandsData{
+		buildInfoData: newBuildInfoData(state),
+		Device:        device,
+	}
 
 	ui.Say("Running device setup commands...")
 	if err := RunLocalCommands(s.Commands, wrappedCommand, ctx, ui); err != nil {
@@ -2,6 +2,7 @@ package chroot
 
 import (
 	"fmt"
+	"log"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
@@ -11,6 +12,10 @@ import (
 )
 
 // StepRegisterAMI creates the AMI.
+//
+// Produces:
+//   amis map[string]string - The AMI ID, keyed by region
+//   register_image_input *ec2.RegisterImageInput - The registration parameters
 type StepRegisterAMI struct {
 	RootVolumeSize int64
 }
@@ -30,7 +35,7 @@ func (s *StepRegisterAMI) Run(state multistep.StateBag) multistep.StepAction {
 		rootDeviceName string
 	)
 
-	if config.FromScratch {
+	if !config.hasSourceImage() {
 		mappings = config.AMIBlockDevices.BuildAMIDevices()
 		rootDeviceName = config.RootDeviceName
 	} else {
@@ -49,7 +54,7 @@ func (s *StepRegisterAMI) Run(state multistep.StateBag) multistep.StepAction {
 				newDevice.Ebs = &ec2.EbsBlockDevice{SnapshotId: aws.String(snapshotId)}
 			}
 
-			if config.FromScratch || s.RootVolumeSize > *newDevice.Ebs.VolumeSize {
+			if config.FromScratch || s.RootVolumeSize > aws.Int64Value(newDevice.Ebs.VolumeSize) {
 				newDevice.Ebs.VolumeSize = aws.Int64(s.RootVolumeSize)
 			}
 		}
@@ -63,14 +68,8 @@ func (s *StepRegisterAMI) Run(state multistep.StateBag) multistep.StepAction {
 		newMappings[i] = newDevice
 	}
 
-	if config.FromScratch {
-		registerOpts = &ec2.RegisterImageInput{
-			Name:                &config.AMIName,
-			Architecture:        aws.String(ec2.ArchitectureValuesX8664),
-			RootDeviceName:      aws.String(rootDeviceName),
-			VirtualizationType:  aws.String(config.AMIVirtType),
-			BlockDeviceMappings: newMappings,
-		}
+	if !config.hasSourceImage() {
+		registerOpts = buildTemplateRegisterOpts(config, newMappings)
 	} else {
 		registerOpts = buildRegisterOpts(config, image, newMappings)
 	}
@@ -80,6 +79,8 @@ func (s *StepRegisterAMI) Run(state multistep.StateBag) multistep.StepAction {
 		registerOpts.SriovNetSupport = aws.String("simple")
 	}
 
+	state.Put("register_image_input", registerOpts)
+
 	registerResp, err := ec2conn.RegisterImage(registerOpts)
 	if err != nil {
 		state.Put("error", fmt.Errorf("Error registering AMI: %s", err))
@@ -87,6 +88,11 @@ func (s *StepRegisterAMI) Run(state multistep.StateBag) multistep.StepAction {
 		return multistep.ActionHalt
 	}
 
+	// The snapshot now backs the AMI and must not be swept as an orphan
+	if err := untagBuildResource(ec2conn, snapshotId); err != nil {
+		log.Printf("Error untagging snapshot %s: %s", snapshotId, err)
+	}
+
 	// Set the AMI ID in the state
 	ui.Say(fmt.Sprintf("AMI: %s", *registerResp.ImageId))
 	amis := make(map[string]string)
@@ -114,6 +120,18 @@ func (s *StepRegisterAMI) Run(state multistep.StateBag) multistep.StepAction {
 
 func (s *StepRegisterAMI) Cleanup(state multistep.StateBag) {}
 
+// buildTemplateRegisterOpts returns the registration parameters of an AMI
+// without a source image, which all come from the template.
+func buildTemplateRegisterOpts(config *Config, mappings []*ec2.BlockDeviceMapping) *ec2.RegisterImageInput {
+	return &ec2.RegisterImageInput{
+		Name:                &config.AMIName,
+		Architecture:        aws.String(config.AMIArchitecture),
+		RootDeviceName:      aws.String(config.RootDeviceName),
+		VirtualizationType:  aws.String(config.AMIVirtType),
+		BlockDeviceMappings: mappings,
+	}
+}
+
 func buildRegisterOpts(config *Config, image *ec2.Image, mappings []*ec2.BlockDeviceMapping) *ec2.RegisterImageInput {
 	registerOpts := &ec2.RegisterImageInput{
 		Name:                &config.AMIName,
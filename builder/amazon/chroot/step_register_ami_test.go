@@ -71,3 +71,24 @@ func TestStepRegisterAmi_buildRegisterOpts_hvm(t *testing.T) {
 		t.Fatalf("Unexpected KernelId value: expected nil got %s\n", *opts.KernelId)
 	}
 }
+
+func TestStepRegisterAmi_buildTemplateRegisterOpts(t *testing.T) {
+	config := Config{}
+	config.AMIName = "test_ami_name"
+	config.AMIVirtType = "hvm"
+	config.AMIArchitecture = "i386"
+	config.FromVolume = "snap-1234"
+	config.RootDeviceName = "/dev/sda1"
+
+	opts := buildTemplateRegisterOpts(&config, []*ec2.BlockDeviceMapping{})
+
+	expected := config.AMIArchitecture
+	if *opts.Architecture != expected {
+		t.Fatalf("Unexpected Architecture value: expected %s got %s\n", expected, *opts.Architecture)
+	}
+
+	expected = config.RootDeviceName
+	if *opts.RootDeviceName != expected {
+		t.Fatalf("Unexpected RootDeviceName value: expected %s got %s\n", expected, *opts.RootDeviceName)
+	}
+}
@@ -0,0 +1,156 @@
+package chroot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/mitchellh/multistep"
+	awscommon "github.com/mitchellh/packer/builder/amazon/common"
+	"github.com/mitchellh/packer/packer"
+)
+
+// StepShareSnapshot copies the snapshot into the account of the assumed
+// role, since an AMI can only be registered from a snapshot the account
+// owns. The snapshot is shared with the account for the copy, and deleted
+// once the copy is complete.
+//
+// Uses:
+//   snapshot_id string - ID of the snapshot of this instance's account
+//
+// Produces:
+//   snapshot_id string - ID of the copy in the role's account
+type StepShareSnapshot struct {
+	RoleARN string
+
+	snapshotId string
+}
+
+func (s *StepShareSnapshot) Run(state multistep.StateBag) multistep.StepAction {
+	if s.RoleARN == "" {
+		return multistep.ActionContinue
+	}
+
+	ec2conn := state.Get("ec2").(*ec2.EC2)
+	instanceEc2conn := state.Get("instance_ec2").(*ec2.EC2)
+	sourceSnapshotId := state.Get("snapshot_id").(string)
+	ui := state.Get("ui").(packer.Ui)
+
+	accountId, err := roleAccountId(s.RoleARN)
+	if err != nil {
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	ui.Say(fmt.Sprintf("Sharing snapshot with account %s...", accountId))
+	_, err = instanceEc2conn.ModifySnapshotAttribute(&ec2.ModifySnapshotAttributeInput{
+		SnapshotId: aws.String(sourceSnapshotId),
+		CreateVolumePermission: &ec2.CreateVolumePermissionModifications{
+			Add: []*ec2.CreateVolumePermission{{UserId: aws.String(accountId)}},
+		},
+	})
+	if err != nil {
+		err := fmt.Errorf("Error sharing snapshot: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	ui.Say(fmt.Sprintf("Copying snapshot into account %s...", accountId))
+	var copyResp *ec2.CopySnapshotOutput
+	err = awscommon.RetryEventualConsistency(func() error {
+		var err error
+		copyResp, err = ec2conn.CopySnapshot(&ec2.CopySnapshotInput{
+			SourceRegion:     instanceEc2conn.Config.Region,
+			SourceSnapshotId: aws.String(sourceSnapshotId),
+			Description:      aws.String(fmt.Sprintf("Packer: copy of %s", sourceSnapshotId)),
+		})
+		return err
+	})
+	if err != nil {
+		err := fmt.Errorf("Error copying snapshot: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	s.snapshotId = *copyResp.SnapshotId
+	ui.Message(fmt.Sprintf("Snapshot ID: %s", s.snapshotId))
+
+	stateChange := awscommon.StateChangeConf{
+		Pending:   []string{"pending"},
+		StepState: state,
+		Target:    "completed",
+		Refresh: func() (interface{}, string, error) {
+			resp, err := ec2conn.DescribeSnapshots(&ec2.DescribeSnapshotsInput{
+				SnapshotIds: []*string{aws.String(s.snapshotId)},
+			})
+			if err != nil {
+				return nil, "", err
+			}
+
+			if len(resp.Snapshots) == 0 {
+				return nil, "", errors.New("No snapshots found.")
+			}
+
+			s := resp.Snapshots[0]
+			return s, *s.State, nil
+		},
+	}
+
+	if _, err := awscommon.WaitForState(&stateChange); err != nil {
+		err := fmt.Errorf("Error waiting for snapshot copy: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	// The copy replaces the snapshot of this instance's account
+	ui.Say("Deleting the source snapshot...")
+	_, err = instanceEc2conn.DeleteSnapshot(&ec2.DeleteSnapshotInput{
+		SnapshotId: aws.String(sourceSnapshotId),
+	})
+	if err != nil {
+		ui.Error(fmt.Sprintf("Error deleting snapshot %s: %s", sourceSnapshotId, err))
+	}
+
+	state.Put("snapshot_id", s.snapshotId)
+	state.Put("snapshots", map[string][]string{
+		*ec2conn.Config.Region: {s.snapshotId},
+	})
+
+	return multistep.ActionContinue
+}
+
+func (s *StepShareSnapshot) Cleanup(state multistep.StateBag) {
+	if s.snapshotId == "" {
+		return
+	}
+
+	_, cancelled := state.GetOk(multistep.StateCancelled)
+	_, halted := state.GetOk(multistep.StateHalted)
+
+	if cancelled || halted {
+		ec2conn := state.Get("ec2").(*ec2.EC2)
+		ui := state.Get("ui").(packer.Ui)
+		ui.Say("Removing snapshot copy since we cancelled or halted...")
+		_, err := ec2conn.DeleteSnapshot(&ec2.DeleteSnapshotInput{SnapshotId: &s.snapshotId})
+		if err != nil {
+			ui.Error(fmt.Sprintf("Error: %s", err))
+		}
+	}
+}
+
+// roleAccountId returns the account ID of a role ARN such as
+// arn:aws:iam::123456789012:role/packer.
+func roleAccountId(arn string) (string, error) {
+	parts := strings.Split(arn, ":")
+	if len(parts) < 6 || parts[0] != "arn" || parts[4] == "" {
+		return "", fmt.Errorf("Invalid role_arn: %s", arn)
+	}
+
+	return parts[4], nil
+}
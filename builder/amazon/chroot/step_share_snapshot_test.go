@@ -0,0 +1,161 @@
+package chroot
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/mitchellh/multistep"
+	"github.com/mitchellh/packer/packer"
+)
+
+// testEC2 is a fake EC2 API of one account that records the actions
+// called on it.
+type testEC2 struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	actions []string
+	params  map[string]string
+}
+
+func newTestEC2() *testEC2 {
+	e := &testEC2{params: make(map[string]string)}
+	e.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		action := r.Form.Get("Action")
+
+		e.mu.Lock()
+		e.actions = append(e.actions, action)
+		for k := range r.Form {
+			e.params[action+"."+k] = r.Form.Get(k)
+		}
+		e.mu.Unlock()
+
+		var body string
+		switch action {
+		case "CopySnapshot":
+			body = "<snapshotId>snap-copy</snapshotId>"
+		case "DescribeSnapshots":
+			body = fmt.Sprintf(
+				"<snapshotSet><item><snapshotId>%s</snapshotId><status>completed</status></item></snapshotSet>",
+				r.Form.Get("SnapshotId.1"))
+		default:
+			body = "<return>true</return>"
+		}
+
+		w.Header().Set("Content-Type", "text/xml")
+		fmt.Fprintf(w, "<%sResponse><requestId>1</requestId>%s</%sResponse>", action, body, action)
+	}))
+
+	return e
+}
+
+func (e *testEC2) conn(t *testing.T) *ec2.EC2 {
+	sess, err := session.NewSession(&aws.Config{
+		Credentials: credentials.NewStaticCredentials("access", "secret", ""),
+		Endpoint:    aws.String(e.URL),
+		MaxRetries:  aws.Int(0),
+		Region:      aws.String("us-east-1"),
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return ec2.New(sess)
+}
+
+func TestStepShareSnapshot(t *testing.T) {
+	instanceEC2 := newTestEC2()
+	defer instanceEC2.Close()
+	roleEC2 := newTestEC2()
+	defer roleEC2.Close()
+
+	state := new(multistep.BasicStateBag)
+	state.Put("ec2", roleEC2.conn(t))
+	state.Put("instance_ec2", instanceEC2.conn(t))
+	state.Put("snapshot_id", "snap-source")
+	state.Put("ui", &packer.BasicUi{
+		Reader: new(bytes.Buffer),
+		Writer: new(bytes.Buffer),
+	})
+
+	step := &StepShareSnapshot{RoleARN: "arn:aws:iam::123456789012:role/packer"}
+	if action := step.Run(state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v, %s", action, state.Get("error"))
+	}
+
+	expected := []string{"ModifySnapshotAttribute", "DeleteSnapshot"}
+	if !reflect.DeepEqual(instanceEC2.actions, expected) {
+		t.Fatalf("bad instance actions: %#v", instanceEC2.actions)
+	}
+	if v := instanceEC2.params["ModifySnapshotAttribute.CreateVolumePermission.Add.1.UserId"]; v != "123456789012" {
+		t.Fatalf("bad user: %s", v)
+	}
+	if v := instanceEC2.params["DeleteSnapshot.SnapshotId"]; v != "snap-source" {
+		t.Fatalf("bad deleted snapshot: %s", v)
+	}
+
+	expected = []string{"CopySnapshot", "DescribeSnapshots"}
+	if !reflect.DeepEqual(roleEC2.actions, expected) {
+		t.Fatalf("bad role actions: %#v", roleEC2.actions)
+	}
+	if v := roleEC2.params["CopySnapshot.SourceSnapshotId"]; v != "snap-source" {
+		t.Fatalf("bad source snapshot: %s", v)
+	}
+
+	if v := state.Get("snapshot_id"); v != "snap-copy" {
+		t.Fatalf("bad snapshot_id: %#v", v)
+	}
+	snapshots := state.Get("snapshots").(map[string][]string)
+	if !reflect.DeepEqual(snapshots, map[string][]string{"us-east-1": {"snap-copy"}}) {
+		t.Fatalf("bad snapshots: %#v", snapshots)
+	}
+
+	// The copy is removed if a later step halts, the source snapshot is
+	// already gone.
+	state.Put(multistep.StateHalted, true)
+	step.Cleanup(state)
+	(&StepSnapshot{snapshotId: "snap-source"}).Cleanup(state)
+
+	expected = []string{"CopySnapshot", "DescribeSnapshots", "DeleteSnapshot"}
+	if !reflect.DeepEqual(roleEC2.actions, expected) {
+		t.Fatalf("bad role actions: %#v", roleEC2.actions)
+	}
+	if len(instanceEC2.actions) != 2 {
+		t.Fatalf("bad instance actions: %#v", instanceEC2.actions)
+	}
+}
+
+func TestStepShareSnapshot_noRole(t *testing.T) {
+	state := new(multistep.BasicStateBag)
+	step := new(StepShareSnapshot)
+	if action := step.Run(state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+}
+
+func TestRoleAccountId(t *testing.T) {
+	id, err := roleAccountId("arn:aws:iam::123456789012:role/packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if id != "123456789012" {
+		t.Fatalf("bad: %s", id)
+	}
+
+	if _, err := roleAccountId("packer"); err == nil {
+		t.Fatal("should have error")
+	}
+}
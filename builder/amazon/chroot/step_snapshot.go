@@ -3,6 +3,7 @@ package chroot
 import (
 	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/aws/aws-sdk-go/service/ec2"
@@ -20,16 +21,22 @@ type StepSnapshot struct {
 }
 
 func (s *StepSnapshot) Run(state multistep.StateBag) multistep.StepAction {
-	ec2conn := state.Get("ec2").(*ec2.EC2)
+	ec2conn := state.Get("instance_ec2").(*ec2.EC2)
+	instance := state.Get("instance").(*ec2.Instance)
 	ui := state.Get("ui").(packer.Ui)
 	volumeId := state.Get("volume_id").(string)
 
 	ui.Say("Creating snapshot...")
 	description := fmt.Sprintf("Packer: %s", time.Now().String())
 
-	createSnapResp, err := ec2conn.CreateSnapshot(&ec2.CreateSnapshotInput{
-		VolumeId:    &volumeId,
-		Description: &description,
+	var createSnapResp *ec2.Snapshot
+	err := awscommon.RetryEventualConsistency(func() error {
+		var err error
+		createSnapResp, err = ec2conn.CreateSnapshot(&ec2.CreateSnapshotInput{
+			VolumeId:    &volumeId,
+			Description: &description,
+		})
+		return err
 	})
 	if err != nil {
 		err := fmt.Errorf("Error creating snapshot: %s", err)
@@ -42,6 +49,10 @@ func (s *StepSnapshot) Run(state multistep.StateBag) multistep.StepAction {
 	s.snapshotId = *createSnapResp.SnapshotId
 	ui.Message(fmt.Sprintf("Snapshot ID: %s", s.snapshotId))
 
+	if err := tagBuildResource(ec2conn, s.snapshotId, *instance.InstanceId); err != nil {
+		log.Printf("Error tagging snapshot, it can't be swept if orphaned: %s", err)
+	}
+
 	// Wait for the snapshot to be ready
 	stateChange := awscommon.StateChangeConf{
 		Pending:   []string{"pending"},
@@ -85,11 +96,17 @@ func (s *StepSnapshot) Cleanup(state multistep.StateBag) {
 		return
 	}
 
+	// The snapshot was replaced by a copy in the account of the assumed
+	// role, see StepShareSnapshot.
+	if id, ok := state.GetOk("snapshot_id"); ok && id.(string) != s.snapshotId {
+		return
+	}
+
 	_, cancelled := state.GetOk(multistep.StateCancelled)
 	_, halted := state.GetOk(multistep.StateHalted)
 
 	if cancelled || halted {
-		ec2conn := state.Get("ec2").(*ec2.EC2)
+		ec2conn := state.Get("instance_ec2").(*ec2.EC2)
 		ui := state.Get("ui").(packer.Ui)
 		ui.Say("Removing snapshot since we cancelled or halted...")
 		_, err := ec2conn.DeleteSnapshot(&ec2.DeleteSnapshotInput{SnapshotId: &s.snapshotId})
@@ -0,0 +1,102 @@
+package chroot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/mitchellh/multistep"
+	awscommon "github.com/mitchellh/packer/builder/amazon/common"
+	"github.com/mitchellh/packer/packer"
+)
+
+// StepSourceVolumeInfo resolves from_volume to the snapshot the root volume
+// will be created from. If a volume ID was given, a temporary snapshot of
+// that volume is taken so the source volume itself is never modified.
+//
+// Produces:
+//   source_snapshot *ec2.Snapshot - The snapshot to create the root volume from
+type StepSourceVolumeInfo struct {
+	FromVolume string
+
+	snapshotId string
+}
+
+func (s *StepSourceVolumeInfo) Run(state multistep.StateBag) multistep.StepAction {
+	ec2conn := state.Get("instance_ec2").(*ec2.EC2)
+	instance := state.Get("instance").(*ec2.Instance)
+	ui := state.Get("ui").(packer.Ui)
+
+	snapshotId := s.FromVolume
+	if strings.HasPrefix(s.FromVolume, "vol-") {
+		ui.Say(fmt.Sprintf("Creating snapshot of source volume (%s)...", s.FromVolume))
+		description := fmt.Sprintf("Packer: source volume %s", s.FromVolume)
+		createSnapResp, err := ec2conn.CreateSnapshot(&ec2.CreateSnapshotInput{
+			VolumeId:    &s.FromVolume,
+			Description: &description,
+		})
+		if err != nil {
+			err := fmt.Errorf("Error creating snapshot of source volume: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		// Remember the snapshot so it is removed on cleanup
+		s.snapshotId = *createSnapResp.SnapshotId
+		snapshotId = s.snapshotId
+		ui.Message(fmt.Sprintf("Snapshot ID: %s", s.snapshotId))
+
+		if err := tagBuildResource(ec2conn, s.snapshotId, *instance.InstanceId); err != nil {
+			log.Printf("Error tagging snapshot, it can't be swept if orphaned: %s", err)
+		}
+	}
+
+	ui.Say(fmt.Sprintf("Inspecting the source snapshot (%s)...", snapshotId))
+	stateChange := awscommon.StateChangeConf{
+		Pending:   []string{"pending"},
+		StepState: state,
+		Target:    "completed",
+		Refresh: func() (interface{}, string, error) {
+			resp, err := ec2conn.DescribeSnapshots(&ec2.DescribeSnapshotsInput{SnapshotIds: []*string{&snapshotId}})
+			if err != nil {
+				return nil, "", err
+			}
+
+			if len(resp.Snapshots) == 0 {
+				return nil, "", errors.New("No snapshots found.")
+			}
+
+			s := resp.Snapshots[0]
+			return s, *s.State, nil
+		},
+	}
+
+	snapshot, err := awscommon.WaitForState(&stateChange)
+	if err != nil {
+		err := fmt.Errorf("Error waiting for source snapshot: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	state.Put("source_snapshot", snapshot.(*ec2.Snapshot))
+	return multistep.ActionContinue
+}
+
+func (s *StepSourceVolumeInfo) Cleanup(state multistep.StateBag) {
+	if s.snapshotId == "" {
+		return
+	}
+
+	ec2conn := state.Get("instance_ec2").(*ec2.EC2)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Removing snapshot of source volume...")
+	_, err := ec2conn.DeleteSnapshot(&ec2.DeleteSnapshotInput{SnapshotId: &s.snapshotId})
+	if err != nil {
+		ui.Error(fmt.Sprintf("Error deleting snapshot of source volume: %s", err))
+	}
+}
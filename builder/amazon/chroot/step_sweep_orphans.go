@@ -0,0 +1,196 @@
+package chroot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/mitchellh/multistep"
+	awscommon "github.com/mitchellh/packer/builder/amazon/common"
+	"github.com/mitchellh/packer/packer"
+)
+
+// Tags put on the temporary volumes and snapshots of a build so that they
+// can be found again if the Packer process is killed before cleaning up.
+const (
+	orphanInstanceTag = "packer-chroot-instance-id"
+	orphanPidTag      = "packer-chroot-pid"
+)
+
+// tagBuildResource marks a temporary resource as belonging to this Packer
+// process on the given instance.
+func tagBuildResource(ec2conn *ec2.EC2, resourceId string, instanceId string) error {
+	return awscommon.RetryEventualConsistency(func() error {
+		_, err := ec2conn.CreateTags(&ec2.CreateTagsInput{
+			Resources: []*string{aws.String(resourceId)},
+			Tags: []*ec2.Tag{
+				{Key: aws.String(orphanInstanceTag), Value: aws.String(instanceId)},
+				{Key: aws.String(orphanPidTag), Value: aws.String(strconv.Itoa(os.Getpid()))},
+			},
+		})
+		return err
+	})
+}
+
+// untagBuildResource removes the marks added by tagBuildResource once a
+// resource outlives the build, e.g. a snapshot that now backs an AMI.
+func untagBuildResource(ec2conn *ec2.EC2, resourceId string) error {
+	return awscommon.RetryEventualConsistency(func() error {
+		_, err := ec2conn.DeleteTags(&ec2.DeleteTagsInput{
+			Resources: []*string{aws.String(resourceId)},
+			Tags: []*ec2.Tag{
+				{Key: aws.String(orphanInstanceTag)},
+				{Key: aws.String(orphanPidTag)},
+			},
+		})
+		return err
+	})
+}
+
+// StepSweepOrphans deletes volumes and snapshots left behind on this
+// instance by chroot builds whose Packer process no longer runs, for
+// example because it was killed before it could clean up.
+type StepSweepOrphans struct{}
+
+func (s *StepSweepOrphans) Run(state multistep.StateBag) multistep.StepAction {
+	ec2conn := state.Get("instance_ec2").(*ec2.EC2)
+	instance := state.Get("instance").(*ec2.Instance)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Sweeping orphaned resources of earlier builds...")
+	filters := []*ec2.Filter{
+		{
+			Name:   aws.String("tag:" + orphanInstanceTag),
+			Values: []*string{instance.InstanceId},
+		},
+	}
+
+	volumesResp, err := ec2conn.DescribeVolumes(&ec2.DescribeVolumesInput{Filters: filters})
+	if err != nil {
+		err := fmt.Errorf("Error searching for orphaned volumes: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	for _, volume := range volumesResp.Volumes {
+		if !orphaned(volume.Tags) {
+			continue
+		}
+
+		if err := sweepVolume(ec2conn, volume, state); err != nil {
+			ui.Error(fmt.Sprintf("Error removing orphaned volume %s: %s", *volume.VolumeId, err))
+			continue
+		}
+		ui.Message(fmt.Sprintf("Removed orphaned volume: %s", *volume.VolumeId))
+	}
+
+	snapshotsResp, err := ec2conn.DescribeSnapshots(&ec2.DescribeSnapshotsInput{
+		Filters:  filters,
+		OwnerIds: []*string{aws.String("self")},
+	})
+	if err != nil {
+		err := fmt.Errorf("Error searching for orphaned snapshots: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	for _, snapshot := range snapshotsResp.Snapshots {
+		if !orphaned(snapshot.Tags) {
+			continue
+		}
+
+		// Snapshots still backing an AMI fail to delete, which is fine
+		_, err := ec2conn.DeleteSnapshot(&ec2.DeleteSnapshotInput{SnapshotId: snapshot.SnapshotId})
+		if err != nil {
+			ui.Error(fmt.Sprintf("Error removing orphaned snapshot %s: %s", *snapshot.SnapshotId, err))
+			continue
+		}
+		ui.Message(fmt.Sprintf("Removed orphaned snapshot: %s", *snapshot.SnapshotId))
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *StepSweepOrphans) Cleanup(state multistep.StateBag) {}
+
+// orphaned reports whether the Packer process that tagged a resource is
+// gone. Resources without a valid PID tag are never considered orphaned.
+func orphaned(tags []*ec2.Tag) bool {
+	for _, tag := range tags {
+		if *tag.Key != orphanPidTag {
+			continue
+		}
+
+		pid, err := strconv.Atoi(*tag.Value)
+		if err != nil || pid <= 0 {
+			return false
+		}
+
+		return !processExists(pid)
+	}
+
+	return false
+}
+
+func sweepVolume(ec2conn *ec2.EC2, volume *ec2.Volume, state multistep.StateBag) error {
+	if len(volume.Attachments) > 0 {
+		device := *volume.Attachments[0].Device
+		if deviceMounted(device) {
+			return fmt.Errorf("%s is still mounted, unmount it first", device)
+		}
+
+		log.Printf("Detaching orphaned volume %s from %s", *volume.VolumeId, device)
+		_, err := ec2conn.DetachVolume(&ec2.DetachVolumeInput{VolumeId: volume.VolumeId})
+		if err != nil {
+			return err
+		}
+
+		stateChange := awscommon.StateChangeConf{
+			Pending:   []string{"in-use"},
+			StepState: state,
+			Target:    "available",
+			Refresh: func() (interface{}, string, error) {
+				resp, err := ec2conn.DescribeVolumes(&ec2.DescribeVolumesInput{VolumeIds: []*string{volume.VolumeId}})
+				if err != nil {
+					return nil, "", err
+				}
+
+				v := resp.Volumes[0]
+				return v, *v.State, nil
+			},
+		}
+
+		if _, err := awscommon.WaitForState(&stateChange); err != nil {
+			return err
+		}
+	}
+
+	_, err := ec2conn.DeleteVolume(&ec2.DeleteVolumeInput{VolumeId: volume.VolumeId})
+	return err
+}
+
+// deviceMounted reports whether the attachment device, or its "xvd" name,
+// has anything mounted from it on this host.
+func deviceMounted(device string) bool {
+	mounts, err := ioutil.ReadFile("/proc/mounts")
+	if err != nil {
+		// Err on the side of not detaching anything
+		return true
+	}
+
+	xvdDevice := strings.Replace(device, "/sd", "/xvd", 1)
+	for _, line := range strings.Split(string(mounts), "\n") {
+		if strings.HasPrefix(line, device) || strings.HasPrefix(line, xvdDevice) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,37 @@
+package chroot
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+func TestOrphaned(t *testing.T) {
+	pidTags := func(pid string) []*ec2.Tag {
+		return []*ec2.Tag{
+			{Key: aws.String(orphanInstanceTag), Value: aws.String("i-1234")},
+			{Key: aws.String(orphanPidTag), Value: aws.String(pid)},
+		}
+	}
+
+	// Our own process is alive
+	if orphaned(pidTags(strconv.Itoa(os.Getpid()))) {
+		t.Fatal("running process should not be orphaned")
+	}
+
+	// The largest PID on Linux is 2^22, so this process can't exist
+	if !orphaned(pidTags("4194305")) {
+		t.Fatal("missing process should be orphaned")
+	}
+
+	if orphaned(pidTags("foo")) {
+		t.Fatal("invalid pid should not be orphaned")
+	}
+
+	if orphaned(nil) {
+		t.Fatal("untagged resource should not be orphaned")
+	}
+}
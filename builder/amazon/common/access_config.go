@@ -9,25 +9,58 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/credentials/ec2rolecreds"
-	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/mitchellh/packer/template/interpolate"
 )
 
 // AccessConfig is for common configuration related to AWS access
 type AccessConfig struct {
-	AccessKey      string `mapstructure:"access_key"`
-	SecretKey      string `mapstructure:"secret_key"`
-	RawRegion      string `mapstructure:"region"`
-	SkipValidation bool   `mapstructure:"skip_region_validation"`
-	Token          string `mapstructure:"token"`
-	ProfileName    string `mapstructure:"profile"`
+	AccessKey       string `mapstructure:"access_key"`
+	SecretKey       string `mapstructure:"secret_key"`
+	RawRegion       string `mapstructure:"region"`
+	SkipValidation  bool   `mapstructure:"skip_region_validation"`
+	Token           string `mapstructure:"token"`
+	ProfileName     string `mapstructure:"profile"`
+	RoleARN         string `mapstructure:"role_arn"`
+	ExternalID      string `mapstructure:"external_id"`
+	RoleSessionName string `mapstructure:"role_session_name"`
 }
 
 // Config returns a valid aws.Config object for access to AWS services, or
-// an error if the authentication and region couldn't be resolved
+// an error if the authentication and region couldn't be resolved. If
+// role_arn is set, the credentials are those of the assumed role.
 func (c *AccessConfig) Config() (*aws.Config, error) {
+	config, err := c.SourceConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.RoleARN == "" {
+		return config, nil
+	}
+
+	sessName, err := getSessionName(c.RoleSessionName)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := session.NewSession(config)
+	if err != nil {
+		return nil, err
+	}
+	creds := stscreds.NewCredentials(session, c.RoleARN, func(p *stscreds.AssumeRoleProvider) {
+		p.RoleSessionName = sessName
+		if c.ExternalID != "" {
+			p.ExternalID = aws.String(c.ExternalID)
+		}
+	})
+	return config.Copy().WithCredentials(creds), nil
+}
+
+// SourceConfig is like Config, but never assumes role_arn. These are the
+// credentials used to assume the role.
+func (c *AccessConfig) SourceConfig() (*aws.Config, error) {
 	var creds *credentials.Credentials
 
 	region, err := c.Region()
@@ -53,9 +86,7 @@ func (c *AccessConfig) Config() (*aws.Config, error) {
 			}},
 			&credentials.EnvProvider{},
 			&credentials.SharedCredentialsProvider{Filename: "", Profile: ""},
-			&ec2rolecreds.EC2RoleProvider{
-				Client: ec2metadata.New(session.New(config)),
-			},
+			&MetadataRoleProvider{},
 		})
 	}
 	return config.WithCredentials(creds), nil
@@ -90,6 +121,10 @@ func (c *AccessConfig) Prepare(ctx *interpolate.Context) []error {
 		}
 	}
 
+	if c.RoleARN == "" && (c.ExternalID != "" || c.RoleSessionName != "") {
+		errs = append(errs, fmt.Errorf("external_id and role_session_name require role_arn"))
+	}
+
 	if len(errs) > 0 {
 		return errs
 	}
@@ -97,10 +132,25 @@ func (c *AccessConfig) Prepare(ctx *interpolate.Context) []error {
 	return nil
 }
 
+// GetInstanceMetaData reads path from the instance metadata service. A
+// session token is used when the service supports IMDSv2.
 func GetInstanceMetaData(path string) (contents []byte, err error) {
-	url := "http://169.254.169.254/latest/meta-data/" + path
+	url := metadataURL + "meta-data/" + path
+
+	token, err := metadataToken()
+	if err != nil {
+		return
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return
+	}
+	if token != "" {
+		req.Header.Set("X-aws-ec2-metadata-token", token)
+	}
 
-	resp, err := http.Get(url)
+	resp, err := metadataClient.Do(req)
 	if err != nil {
 		return
 	}
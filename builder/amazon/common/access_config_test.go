@@ -38,3 +38,17 @@ func TestAccessConfigPrepare_Region(t *testing.T) {
 	c.SkipValidation = false
 
 }
+
+func TestAccessConfigPrepare_AssumeRole(t *testing.T) {
+	c := testAccessConfig()
+	c.ExternalID = "foo"
+	if err := c.Prepare(nil); err == nil {
+		t.Fatal("should have error")
+	}
+
+	c.RoleARN = "arn:aws:iam::123456789012:role/packer"
+	c.RoleSessionName = "bar"
+	if err := c.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have err: %s", err)
+	}
+}
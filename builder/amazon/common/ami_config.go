@@ -15,6 +15,7 @@ type AMIConfig struct {
 	AMIGroups               []string          `mapstructure:"ami_groups"`
 	AMIProductCodes         []string          `mapstructure:"ami_product_codes"`
 	AMIRegions              []string          `mapstructure:"ami_regions"`
+	AMIRegionKMSKeyIDs      map[string]string `mapstructure:"region_kms_key_ids"`
 	AMISkipRegionValidation bool              `mapstructure:"skip_region_validation"`
 	AMITags                 map[string]string `mapstructure:"tags"`
 	AMIEnhancedNetworking   bool              `mapstructure:"enhanced_networking"`
@@ -27,12 +28,28 @@ type AMIConfig struct {
 	SnapshotGroups          []string          `mapstructure:"snapshot_groups"`
 }
 
+// Warnings returns the warnings about settings that are valid but have no
+// effect.
+func (c *AMIConfig) Warnings() []string {
+	var warns []string
+	if c.AMIForceDeleteSnapshot && !c.AMIForceDeregister {
+		warns = append(warns, "force_delete_snapshot has no effect without force_deregister")
+	}
+
+	return warns
+}
+
 func (c *AMIConfig) Prepare(ctx *interpolate.Context) []error {
 	var errs []error
 	if c.AMIName == "" {
 		errs = append(errs, fmt.Errorf("ami_name must be specified"))
 	}
 
+	if c.AMIVirtType != "" && c.AMIVirtType != "hvm" && c.AMIVirtType != "paravirtual" {
+		errs = append(errs, fmt.Errorf(
+			"ami_virtualization_type must be one of 'hvm' or 'paravirtual', got '%s'", c.AMIVirtType))
+	}
+
 	if len(c.AMIRegions) > 0 {
 		regionSet := make(map[string]struct{})
 		regions := make([]string, 0, len(c.AMIRegions))
@@ -60,6 +77,21 @@ func (c *AMIConfig) Prepare(ctx *interpolate.Context) []error {
 		c.AMIRegions = regions
 	}
 
+	// Every region with a KMS key must also be a region the AMI is copied to
+	for region := range c.AMIRegionKMSKeyIDs {
+		found := false
+		for _, r := range c.AMIRegions {
+			if r == region {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Errorf(
+				"Region %s is in region_kms_key_ids but not in ami_regions", region))
+		}
+	}
+
 	if len(c.AMIUsers) > 0 && c.AMIEncryptBootVolume {
 		errs = append(errs, fmt.Errorf("Cannot share AMI with encrypted boot volume"))
 	}
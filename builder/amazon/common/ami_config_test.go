@@ -23,6 +23,21 @@ func TestAMIConfigPrepare_name(t *testing.T) {
 	}
 }
 
+func TestAMIConfigPrepare_virtualizationType(t *testing.T) {
+	c := testAMIConfig()
+	for _, v := range []string{"", "hvm", "paravirtual"} {
+		c.AMIVirtType = v
+		if err := c.Prepare(nil); err != nil {
+			t.Fatalf("shouldn't have err for %q: %s", v, err)
+		}
+	}
+
+	c.AMIVirtType = "uefi"
+	if err := c.Prepare(nil); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
 func TestAMIConfigPrepare_regions(t *testing.T) {
 	c := testAMIConfig()
 	c.AMIRegions = nil
@@ -59,6 +74,42 @@ func TestAMIConfigPrepare_regions(t *testing.T) {
 
 }
 
+func TestAMIConfigPrepare_RegionKMSKeyIDs(t *testing.T) {
+	c := testAMIConfig()
+	c.AMIRegions = []string{"us-east-1", "us-west-1"}
+	c.AMIRegionKMSKeyIDs = map[string]string{
+		"us-east-1": "alias/foo",
+		"us-west-1": "",
+	}
+	if err := c.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have err: %s", err)
+	}
+
+	c.AMIRegionKMSKeyIDs["eu-west-1"] = "alias/bar"
+	if err := c.Prepare(nil); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestAMIConfigPrepare_ForceDeleteSnapshot(t *testing.T) {
+	c := testAMIConfig()
+	c.AMIForceDeleteSnapshot = true
+	if err := c.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have err: %s", err)
+	}
+	if len(c.Warnings()) != 1 {
+		t.Fatalf("bad: %#v", c.Warnings())
+	}
+
+	c.AMIForceDeregister = true
+	if err := c.Prepare(nil); err != nil {
+		t.Fatalf("shouldn't have err: %s", err)
+	}
+	if len(c.Warnings()) != 0 {
+		t.Fatalf("bad: %#v", c.Warnings())
+	}
+}
+
 func TestAMIConfigPrepare_Share_EncryptedBoot(t *testing.T) {
 	c := testAMIConfig()
 	c.AMIUsers = []string{"testAccountID"}
@@ -20,6 +20,10 @@ type Artifact struct {
 	// BuilderId is the unique ID for the builder that created this AMI
 	BuilderIdValue string
 
+	// StateData should store data such as snapshot or volume IDs which
+	// post-processors can retrieve through State.
+	StateData map[string]interface{}
+
 	// EC2 connection for performing API stuff.
 	Conn *ec2.EC2
 }
@@ -59,7 +63,7 @@ func (a *Artifact) State(name string) interface{} {
 	case "atlas.artifact.metadata":
 		return a.stateAtlasMetadata()
 	default:
-		return nil
+		return a.StateData[name]
 	}
 }
 
@@ -46,6 +46,27 @@ func TestArtifactState_atlasMetadata(t *testing.T) {
 	}
 }
 
+func TestArtifactState_StateData(t *testing.T) {
+	expected := "volume-1234"
+	a := &Artifact{
+		StateData: map[string]interface{}{"volume_id": expected},
+	}
+
+	if result := a.State("volume_id"); result != expected {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	if result := a.State("invalid_key"); result != nil {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	// Nil StateData should not fail and should return nil
+	a = &Artifact{}
+	if result := a.State("key"); result != nil {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
 func TestArtifactString(t *testing.T) {
 	expected := `AMIs were created:
 
@@ -32,7 +32,7 @@ func NewFromProfile(name string) (*CLIConfig, error) {
 	if err != nil {
 		return nil, err
 	}
-	sessName, err := c.getSessionName(c.profileCfg.Key("role_session_name").Value())
+	sessName, err := getSessionName(c.profileCfg.Key("role_session_name").Value())
 	if err != nil {
 		return nil, err
 	}
@@ -96,7 +96,7 @@ func (c *CLIConfig) Prepare(name string) error {
 	return nil
 }
 
-func (c *CLIConfig) getSessionName(rawName string) (string, error) {
+func getSessionName(rawName string) (string, error) {
 	if rawName == "" {
 		name := "packer-"
 		host, err := os.Hostname()
@@ -0,0 +1,110 @@
+package common
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+// metadataURL is the base URL of the EC2 instance metadata service. It is a
+// variable so tests can point it at a local server.
+var metadataURL = "http://169.254.169.254/latest/"
+
+// metadataClient is used for all metadata requests. The timeout keeps token
+// requests from hanging when the PUT response is dropped because the
+// instance's hop limit is too low (e.g. when running inside a container).
+var metadataClient = &http.Client{Timeout: 5 * time.Second}
+
+// metadataTokenTTL returns the lifetime in seconds requested for IMDSv2
+// session tokens. It can be overridden with AWS_METADATA_TOKEN_TTL.
+func metadataTokenTTL() string {
+	if ttl := os.Getenv("AWS_METADATA_TOKEN_TTL"); ttl != "" {
+		return ttl
+	}
+	return "21600"
+}
+
+// metadataToken requests an IMDSv2 session token. An empty token and no
+// error is returned when the metadata service doesn't support tokens, in
+// which case requests fall back to IMDSv1.
+func metadataToken() (string, error) {
+	req, err := http.NewRequest("PUT", metadataURL+"api/token", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", metadataTokenTTL())
+
+	resp, err := metadataClient.Do(req)
+	if err != nil {
+		return "", nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return "", nil
+	}
+
+	token, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(token), nil
+}
+
+// MetadataRoleProvider retrieves credentials from the instance profile
+// through the metadata service. Unlike ec2rolecreds.EC2RoleProvider, it
+// works on instances that require IMDSv2.
+type MetadataRoleProvider struct {
+	credentials.Expiry
+}
+
+// Retrieve fetches the credentials of the first role in the instance
+// profile.
+func (p *MetadataRoleProvider) Retrieve() (credentials.Value, error) {
+	value := credentials.Value{ProviderName: "MetadataRoleProvider"}
+
+	list, err := GetInstanceMetaData("iam/security-credentials/")
+	if err != nil {
+		return value, fmt.Errorf("no EC2 instance role found: %s", err)
+	}
+
+	s := bufio.NewScanner(strings.NewReader(string(list)))
+	if !s.Scan() {
+		return value, fmt.Errorf("empty EC2 role list")
+	}
+	role := s.Text()
+
+	body, err := GetInstanceMetaData("iam/security-credentials/" + role)
+	if err != nil {
+		return value, fmt.Errorf("failed to get %s EC2 instance role credentials: %s", role, err)
+	}
+
+	var creds struct {
+		Code            string
+		Message         string
+		Expiration      time.Time
+		AccessKeyID     string `json:"AccessKeyId"`
+		SecretAccessKey string
+		Token           string
+	}
+	if err := json.Unmarshal(body, &creds); err != nil {
+		return value, fmt.Errorf("failed to decode %s EC2 instance role credentials: %s", role, err)
+	}
+	if creds.Code != "Success" {
+		return value, fmt.Errorf("%s: %s", creds.Code, creds.Message)
+	}
+
+	p.SetExpiration(creds.Expiration, time.Minute)
+
+	value.AccessKeyID = creds.AccessKeyID
+	value.SecretAccessKey = creds.SecretAccessKey
+	value.SessionToken = creds.Token
+	return value, nil
+}
@@ -0,0 +1,75 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testMetadataServer(tokens bool) func() {
+	const token = "sometoken"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/latest/api/token" {
+			if !tokens || r.Method != "PUT" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(token))
+			return
+		}
+
+		if tokens && r.Header.Get("X-aws-ec2-metadata-token") != token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/latest/meta-data/placement/availability-zone":
+			w.Write([]byte("us-west-2a"))
+		case "/latest/meta-data/iam/security-credentials/":
+			w.Write([]byte("packer-role\n"))
+		case "/latest/meta-data/iam/security-credentials/packer-role":
+			w.Write([]byte(`{"Code": "Success", "AccessKeyId": "access", "SecretAccessKey": "secret", "Token": "session", "Expiration": "2100-01-01T00:00:00Z"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	old := metadataURL
+	metadataURL = server.URL + "/latest/"
+	return func() {
+		metadataURL = old
+		server.Close()
+	}
+}
+
+func TestGetInstanceMetaData(t *testing.T) {
+	for _, tokens := range []bool{true, false} {
+		closer := testMetadataServer(tokens)
+		md, err := GetInstanceMetaData("placement/availability-zone")
+		closer()
+		if err != nil {
+			t.Fatalf("tokens %t: err: %s", tokens, err)
+		}
+		if string(md) != "us-west-2a" {
+			t.Fatalf("tokens %t: bad: %s", tokens, md)
+		}
+	}
+}
+
+func TestMetadataRoleProvider(t *testing.T) {
+	defer testMetadataServer(true)()
+
+	p := &MetadataRoleProvider{}
+	v, err := p.Retrieve()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if v.AccessKeyID != "access" || v.SecretAccessKey != "secret" || v.SessionToken != "session" {
+		t.Fatalf("bad: %#v", v)
+	}
+	if p.IsExpired() {
+		t.Fatal("should not be expired")
+	}
+}
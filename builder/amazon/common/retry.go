@@ -0,0 +1,70 @@
+package common
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	retry "github.com/mitchellh/packer/common"
+)
+
+// eventualConsistencyErrorCodes are the EC2 error codes returned for
+// resources that were just created but are not yet visible to every API
+// endpoint.
+var eventualConsistencyErrorCodes = []string{
+	"InvalidAMIID.NotFound",
+	"InvalidInstanceID.NotFound",
+	"InvalidSnapshot.NotFound",
+	"InvalidVolume.NotFound",
+}
+
+// IsEventualConsistencyError reports whether err is caused by EC2 eventual
+// consistency, in which case the request is likely to succeed if retried.
+func IsEventualConsistencyError(err error) bool {
+	awsErr, ok := err.(awserr.Error)
+	if !ok {
+		return false
+	}
+
+	for _, code := range eventualConsistencyErrorCodes {
+		if awsErr.Code() == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RetryEventualConsistency calls f until it succeeds or fails with an error
+// that is not caused by eventual consistency, backing off exponentially
+// between attempts. The number of attempts is given by RetryAttempts.
+func RetryEventualConsistency(f func() error) error {
+	c := &retry.RetryConfig{
+		Tries:          RetryAttempts(),
+		InitialBackoff: 1 * time.Second,
+		MaxBackoff:     30 * time.Second,
+		Jitter:         0.2,
+		ShouldRetry:    IsEventualConsistencyError,
+	}
+	return c.Run(f)
+}
+
+// Returns 10 attempts by default
+// Allow user to override with AWS_RETRY_ATTEMPTS environment variable
+func RetryAttempts() (attempts uint) {
+	attempts = 10
+
+	override := os.Getenv("AWS_RETRY_ATTEMPTS")
+	if override != "" {
+		n, err := strconv.ParseUint(override, 10, 0)
+		if err != nil {
+			log.Printf("Invalid retry attempts '%s', using default", override)
+		} else {
+			attempts = uint(n)
+		}
+	}
+
+	return attempts
+}
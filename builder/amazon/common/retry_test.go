@@ -0,0 +1,64 @@
+package common
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+func TestIsEventualConsistencyError(t *testing.T) {
+	if !IsEventualConsistencyError(awserr.New("InvalidVolume.NotFound", "", nil)) {
+		t.Fatal("should be eventual consistency error")
+	}
+
+	if IsEventualConsistencyError(awserr.New("UnauthorizedOperation", "", nil)) {
+		t.Fatal("should not be eventual consistency error")
+	}
+
+	if IsEventualConsistencyError(awserr.New("IncorrectState", "", nil)) {
+		t.Fatal("should not be eventual consistency error")
+	}
+
+	if IsEventualConsistencyError(errors.New("foo")) {
+		t.Fatal("should not be eventual consistency error")
+	}
+}
+
+func TestRetryEventualConsistency(t *testing.T) {
+	os.Setenv("AWS_RETRY_ATTEMPTS", "2")
+	defer os.Unsetenv("AWS_RETRY_ATTEMPTS")
+
+	notFound := awserr.New("InvalidSnapshot.NotFound", "", nil)
+
+	// Succeeds once the resource shows up
+	calls := 0
+	err := RetryEventualConsistency(func() error {
+		calls++
+		if calls == 1 {
+			return notFound
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if calls != 2 {
+		t.Fatalf("bad calls: %d", calls)
+	}
+
+	// Other errors are returned immediately
+	calls = 0
+	expected := errors.New("foo")
+	err = RetryEventualConsistency(func() error {
+		calls++
+		return expected
+	})
+	if err != expected {
+		t.Fatalf("bad: %#v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("bad calls: %d", calls)
+	}
+}
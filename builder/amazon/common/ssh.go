@@ -9,6 +9,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/mitchellh/multistep"
+	commonssh "github.com/mitchellh/packer/common/ssh"
 	packerssh "github.com/mitchellh/packer/communicator/ssh"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
@@ -69,8 +70,8 @@ func SSHHost(e ec2Describer, private bool) func(multistep.StateBag) (string, err
 
 // SSHConfig returns a function that can be used for the SSH communicator
 // config for connecting to the instance created over SSH using the private key
-// or password.
-func SSHConfig(useAgent bool, username, password string) func(multistep.StateBag) (*ssh.ClientConfig, error) {
+// or password. The passphrase decrypts a password protected private key.
+func SSHConfig(useAgent bool, username, password, passphrase string) func(multistep.StateBag) (*ssh.ClientConfig, error) {
 	return func(state multistep.StateBag) (*ssh.ClientConfig, error) {
 		if useAgent {
 			authSock := os.Getenv("SSH_AUTH_SOCK")
@@ -94,9 +95,9 @@ func SSHConfig(useAgent bool, username, password string) func(multistep.StateBag
 		privateKey, hasKey := state.GetOk("privateKey")
 		if hasKey {
 
-			signer, err := ssh.ParsePrivateKey([]byte(privateKey.(string)))
+			signer, err := commonssh.EncryptedSigner([]byte(privateKey.(string)), passphrase)
 			if err != nil {
-				return nil, fmt.Errorf("Error setting up SSH config: %s", err)
+				return nil, err
 			}
 			return &ssh.ClientConfig{
 				User: username,
@@ -170,7 +170,13 @@ func WaitForState(conf *StateChangeConf) (i interface{}, err error) {
 		var currentState string
 		i, currentState, err = conf.Refresh()
 		if err != nil {
-			return
+			if !IsEventualConsistencyError(err) {
+				return
+			}
+
+			// The resource is not visible yet, treat it as not found
+			log.Printf("Resource not found yet: %s", err)
+			i, err = nil, nil
 		}
 
 		if i == nil {
@@ -14,9 +14,11 @@ import (
 )
 
 type StepAMIRegionCopy struct {
-	AccessConfig *AccessConfig
-	Regions      []string
-	Name         string
+	AccessConfig      *AccessConfig
+	Regions           []string
+	RegionKeyIds      map[string]string
+	EncryptBootVolume bool
+	Name              string
 }
 
 func (s *StepAMIRegionCopy) Run(state multistep.StateBag) multistep.StepAction {
@@ -45,9 +47,14 @@ func (s *StepAMIRegionCopy) Run(state multistep.StateBag) multistep.StepAction {
 		wg.Add(1)
 		ui.Message(fmt.Sprintf("Copying to: %s", region))
 
+		// A region listed in RegionKeyIds is always encrypted, with the
+		// region's default key if the given key ID is empty.
+		keyId, encrypt := s.RegionKeyIds[region]
+		encrypt = encrypt || s.EncryptBootVolume
+
 		go func(region string) {
 			defer wg.Done()
-			id, snapshotIds, err := amiRegionCopy(state, s.AccessConfig, s.Name, ami, region, *ec2conn.Config.Region)
+			id, snapshotIds, err := amiRegionCopy(state, s.AccessConfig, s.Name, ami, region, *ec2conn.Config.Region, encrypt, keyId)
 
 			lock.Lock()
 			defer lock.Unlock()
@@ -81,7 +88,7 @@ func (s *StepAMIRegionCopy) Cleanup(state multistep.StateBag) {
 // amiRegionCopy does a copy for the given AMI to the target region and
 // returns the resulting ID and snapshot IDs, or error.
 func amiRegionCopy(state multistep.StateBag, config *AccessConfig, name string, imageId string,
-	target string, source string) (string, []string, error) {
+	target string, source string, encrypt bool, keyId string) (string, []string, error) {
 	snapshotIds := []string{}
 
 	// Connect to the region where the AMI will be copied to
@@ -97,11 +104,19 @@ func amiRegionCopy(state multistep.StateBag, config *AccessConfig, name string,
 	}
 	regionconn := ec2.New(session)
 
-	resp, err := regionconn.CopyImage(&ec2.CopyImageInput{
+	input := &ec2.CopyImageInput{
 		SourceRegion:  &source,
 		SourceImageId: &imageId,
 		Name:          &name,
-	})
+	}
+	if encrypt {
+		input.Encrypted = aws.Bool(true)
+		if keyId != "" {
+			input.KmsKeyId = aws.String(keyId)
+		}
+	}
+
+	resp, err := regionconn.CopyImage(input)
 
 	if err != nil {
 		return "", snapshotIds, fmt.Errorf("Error Copying AMI (%s) to region (%s): %s",
@@ -116,6 +116,9 @@ func (s *StepCreateTags) Run(state multistep.StateBag) multistep.StepAction {
 					return false, nil
 				}
 			}
+			if err != nil {
+				return true, err
+			}
 
 			// Override tags on snapshots
 			if len(snapshotTags) > 0 {
@@ -158,14 +161,18 @@ func ConvertToEC2Tags(tags map[string]string, region, sourceAmiId string, ctx in
 			SourceAMI:   sourceAmiId,
 			BuildRegion: region,
 		}
+		interpolatedKey, err := interpolate.Render(key, &ctx)
+		if err != nil {
+			return ec2Tags, fmt.Errorf("Error processing tag: %s:%s - %s", key, value, err)
+		}
 		interpolatedValue, err := interpolate.Render(value, &ctx)
 		if err != nil {
 			return ec2Tags, fmt.Errorf("Error processing tag: %s:%s - %s", key, value, err)
 		}
 
-		log.Printf("Adding tag: \"%s\": \"%s\"", key, interpolatedValue)
+		log.Printf("Adding tag: \"%s\": \"%s\"", interpolatedKey, interpolatedValue)
 		ec2Tags = append(ec2Tags, &ec2.Tag{
-			Key:   aws.String(key),
+			Key:   aws.String(interpolatedKey),
 			Value: aws.String(interpolatedValue),
 		})
 	}
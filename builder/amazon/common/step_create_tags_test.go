@@ -0,0 +1,32 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/mitchellh/packer/template/interpolate"
+)
+
+func TestConvertToEC2Tags(t *testing.T) {
+	tags := map[string]string{
+		"{{ .BuildRegion }}-source": "{{ .SourceAMI }}",
+	}
+
+	ec2Tags, err := ConvertToEC2Tags(tags, "us-east-1", "ami-1234", interpolate.Context{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(ec2Tags) != 1 {
+		t.Fatalf("bad: %#v", ec2Tags)
+	}
+	if *ec2Tags[0].Key != "us-east-1-source" {
+		t.Fatalf("bad key: %s", *ec2Tags[0].Key)
+	}
+	if *ec2Tags[0].Value != "ami-1234" {
+		t.Fatalf("bad value: %s", *ec2Tags[0].Value)
+	}
+
+	tags = map[string]string{"{{": "foo"}
+	if _, err := ConvertToEC2Tags(tags, "us-east-1", "ami-1234", interpolate.Context{}); err == nil {
+		t.Fatal("should have error")
+	}
+}
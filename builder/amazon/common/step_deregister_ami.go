@@ -4,72 +4,112 @@ import (
 	"fmt"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/mitchellh/multistep"
 	"github.com/mitchellh/packer/packer"
 )
 
 type StepDeregisterAMI struct {
+	AccessConfig        *AccessConfig
 	ForceDeregister     bool
 	ForceDeleteSnapshot bool
 	AMIName             string
+	Regions             []string
 }
 
 func (s *StepDeregisterAMI) Run(state multistep.StateBag) multistep.StepAction {
+	// Check for force deregister
+	if !s.ForceDeregister {
+		return multistep.ActionContinue
+	}
+
 	ec2conn := state.Get("ec2").(*ec2.EC2)
 	ui := state.Get("ui").(packer.Ui)
 
-	// Check for force deregister
-	if s.ForceDeregister {
-		resp, err := ec2conn.DescribeImages(&ec2.DescribeImagesInput{
-			Filters: []*ec2.Filter{{
-				Name:   aws.String("name"),
-				Values: []*string{aws.String(s.AMIName)},
-			}}})
-
-		if err != nil {
-			err := fmt.Errorf("Error describing AMI: %s", err)
-			state.Put("error", err)
-			ui.Error(err.Error())
-			return multistep.ActionHalt
+	// Images with the same name in the regions the AMI is copied to would
+	// make the copy fail, so deregister those as well.
+	regions := []string{*ec2conn.Config.Region}
+	for _, region := range s.Regions {
+		if region != *ec2conn.Config.Region {
+			regions = append(regions, region)
 		}
+	}
 
-		// Deregister image(s) by name
-		for _, i := range resp.Images {
-			_, err := ec2conn.DeregisterImage(&ec2.DeregisterImageInput{
-				ImageId: i.ImageId,
-			})
+	for _, region := range regions {
+		regionconn := ec2conn
+		if region != *ec2conn.Config.Region {
+			awsConfig, err := s.AccessConfig.Config()
+			if err != nil {
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+			awsConfig.Region = aws.String(region)
 
+			session, err := session.NewSession(awsConfig)
 			if err != nil {
-				err := fmt.Errorf("Error deregistering existing AMI: %s", err)
+				err := fmt.Errorf("Error creating AWS session: %s", err)
 				state.Put("error", err)
 				ui.Error(err.Error())
 				return multistep.ActionHalt
 			}
-			ui.Say(fmt.Sprintf("Deregistered AMI %s, id: %s", s.AMIName, *i.ImageId))
-
-			// Delete snapshot(s) by image
-			if s.ForceDeleteSnapshot {
-				for _, b := range i.BlockDeviceMappings {
-					if b.Ebs != nil {
-						_, err := ec2conn.DeleteSnapshot(&ec2.DeleteSnapshotInput{
-							SnapshotId: b.Ebs.SnapshotId,
-						})
-
-						if err != nil {
-							err := fmt.Errorf("Error deleting existing snapshot: %s", err)
-							state.Put("error", err)
-							ui.Error(err.Error())
-							return multistep.ActionHalt
-						}
-						ui.Say(fmt.Sprintf("Deleted snapshot: %s", *b.Ebs.SnapshotId))
+			regionconn = ec2.New(session)
+		}
+
+		if err := s.deregister(regionconn, ui); err != nil {
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+	}
+
+	return multistep.ActionContinue
+}
+
+// deregister deregisters our own images named AMIName, and optionally
+// deletes their snapshots, using the given connection.
+func (s *StepDeregisterAMI) deregister(ec2conn *ec2.EC2, ui packer.Ui) error {
+	resp, err := ec2conn.DescribeImages(&ec2.DescribeImagesInput{
+		Owners: []*string{aws.String("self")},
+		Filters: []*ec2.Filter{{
+			Name:   aws.String("name"),
+			Values: []*string{aws.String(s.AMIName)},
+		}}})
+
+	if err != nil {
+		return fmt.Errorf("Error describing AMI: %s", err)
+	}
+
+	// Deregister image(s) by name
+	for _, i := range resp.Images {
+		_, err := ec2conn.DeregisterImage(&ec2.DeregisterImageInput{
+			ImageId: i.ImageId,
+		})
+
+		if err != nil {
+			return fmt.Errorf("Error deregistering existing AMI: %s", err)
+		}
+		ui.Say(fmt.Sprintf("Deregistered AMI %s, id: %s", s.AMIName, *i.ImageId))
+
+		// Delete snapshot(s) by image
+		if s.ForceDeleteSnapshot {
+			for _, b := range i.BlockDeviceMappings {
+				if b.Ebs != nil && b.Ebs.SnapshotId != nil {
+					_, err := ec2conn.DeleteSnapshot(&ec2.DeleteSnapshotInput{
+						SnapshotId: b.Ebs.SnapshotId,
+					})
+
+					if err != nil {
+						return fmt.Errorf("Error deleting existing snapshot: %s", err)
 					}
+					ui.Say(fmt.Sprintf("Deleted snapshot: %s", *b.Ebs.SnapshotId))
 				}
 			}
 		}
 	}
 
-	return multistep.ActionContinue
+	return nil
 }
 
 func (s *StepDeregisterAMI) Cleanup(state multistep.StateBag) {
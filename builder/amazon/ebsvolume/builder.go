@@ -39,6 +39,7 @@ type Builder struct {
 
 func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 	b.config.ctx.Funcs = awscommon.TemplateFuncs
+	var unknownKeys []string
 	err := config.Decode(&b.config, &config.DecodeOpts{
 		Interpolate:        true,
 		InterpolateContext: &b.config.ctx,
@@ -48,10 +49,12 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 				"ebs_volumes",
 			},
 		},
+		UnknownKeys: &unknownKeys,
 	}, raws...)
 	if err != nil {
 		return nil, err
 	}
+	warnings := config.UnknownKeyWarnings(unknownKeys)
 
 	// Accumulate any errors
 	var errs *packer.MultiError
@@ -64,11 +67,11 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 	}
 
 	if errs != nil && len(errs.Errors) > 0 {
-		return nil, errs
+		return warnings, errs
 	}
 
 	log.Println(common.ScrubConfig(b.config, b.config.AccessKey, b.config.SecretKey))
-	return nil, nil
+	return warnings, nil
 }
 
 func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packer.Artifact, error) {
@@ -158,7 +161,8 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 			SSHConfig: awscommon.SSHConfig(
 				b.config.RunConfig.Comm.SSHAgentAuth,
 				b.config.RunConfig.Comm.SSHUsername,
-				b.config.RunConfig.Comm.SSHPassword),
+				b.config.RunConfig.Comm.SSHPassword,
+				b.config.RunConfig.Comm.SSHPrivateKeyPass),
 		},
 		&common.StepProvision{},
 		&awscommon.StepStopEBSBackedInstance{
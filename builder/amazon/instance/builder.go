@@ -58,6 +58,7 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 	copy(configs[1:], raws)
 
 	b.config.ctx.Funcs = awscommon.TemplateFuncs
+	var unknownKeys []string
 	err := config.Decode(&b.config, &config.DecodeOpts{
 		Interpolate:        true,
 		InterpolateContext: &b.config.ctx,
@@ -72,6 +73,7 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 				"tags",
 			},
 		},
+		UnknownKeys: &unknownKeys,
 	}, configs...)
 	if err != nil {
 		return nil, err
@@ -122,6 +124,8 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 
 	// Accumulate any errors
 	var errs *packer.MultiError
+	warns := b.config.AMIConfig.Warnings()
+	warns = append(warns, config.UnknownKeyWarnings(unknownKeys)...)
 	errs = packer.MultiErrorAppend(errs, b.config.AccessConfig.Prepare(&b.config.ctx)...)
 	errs = packer.MultiErrorAppend(errs, b.config.BlockDevices.Prepare(&b.config.ctx)...)
 	errs = packer.MultiErrorAppend(errs, b.config.AMIConfig.Prepare(&b.config.ctx)...)
@@ -152,11 +156,11 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 	}
 
 	if errs != nil && len(errs.Errors) > 0 {
-		return nil, errs
+		return warns, errs
 	}
 
 	log.Println(common.ScrubConfig(b.config, b.config.AccessKey, b.config.SecretKey))
-	return nil, nil
+	return warns, nil
 }
 
 func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packer.Artifact, error) {
@@ -243,7 +247,8 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 			SSHConfig: awscommon.SSHConfig(
 				b.config.RunConfig.Comm.SSHAgentAuth,
 				b.config.RunConfig.Comm.SSHUsername,
-				b.config.RunConfig.Comm.SSHPassword),
+				b.config.RunConfig.Comm.SSHPassword,
+				b.config.RunConfig.Comm.SSHPrivateKeyPass),
 		},
 		&common.StepProvision{},
 		&StepUploadX509Cert{},
@@ -254,9 +259,11 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 			Debug: b.config.PackerDebug,
 		},
 		&awscommon.StepDeregisterAMI{
+			AccessConfig:        &b.config.AccessConfig,
 			ForceDeregister:     b.config.AMIForceDeregister,
 			ForceDeleteSnapshot: b.config.AMIForceDeleteSnapshot,
 			AMIName:             b.config.AMIName,
+			Regions:             b.config.AMIRegions,
 		},
 		&StepRegisterAMI{},
 		&awscommon.StepAMIRegionCopy{
@@ -25,12 +25,11 @@ import (
 	"github.com/mitchellh/packer/builder/azure/common/constants"
 	"github.com/mitchellh/packer/builder/azure/pkcs12"
 	"github.com/mitchellh/packer/common"
+	commonssh "github.com/mitchellh/packer/common/ssh"
 	"github.com/mitchellh/packer/helper/communicator"
 	"github.com/mitchellh/packer/helper/config"
 	"github.com/mitchellh/packer/packer"
 	"github.com/mitchellh/packer/template/interpolate"
-
-	"golang.org/x/crypto/ssh"
 )
 
 const (
@@ -189,14 +188,17 @@ func (c *Config) createCertificate() (string, error) {
 func newConfig(raws ...interface{}) (*Config, []string, error) {
 	var c Config
 
+	var unknownKeys []string
 	err := config.Decode(&c, &config.DecodeOpts{
 		Interpolate:        true,
 		InterpolateContext: c.ctx,
+		UnknownKeys:        &unknownKeys,
 	}, raws...)
 
 	if err != nil {
 		return nil, nil, err
 	}
+	warnings := config.UnknownKeyWarnings(unknownKeys)
 
 	provideDefaultValues(&c)
 	setRuntimeValues(&c)
@@ -234,10 +236,10 @@ func newConfig(raws ...interface{}) (*Config, []string, error) {
 	assertRequiredParametersSet(&c, errs)
 	assertTagProperties(&c, errs)
 	if errs != nil && len(errs.Errors) > 0 {
-		return nil, nil, errs
+		return nil, warnings, errs
 	}
 
-	return &c, nil, nil
+	return &c, warnings, nil
 }
 
 func setSshValues(c *Config) error {
@@ -250,7 +252,7 @@ func setSshValues(c *Config) error {
 		if err != nil {
 			panic(err)
 		}
-		signer, err := ssh.ParsePrivateKey(privateKeyBytes)
+		signer, err := commonssh.EncryptedSigner(privateKeyBytes, c.Comm.SSHPrivateKeyPass)
 		if err != nil {
 			panic(err)
 		}
@@ -4,9 +4,9 @@
 package lin
 
 import (
-	"fmt"
 	"github.com/mitchellh/multistep"
 	"github.com/mitchellh/packer/builder/azure/common/constants"
+	commonssh "github.com/mitchellh/packer/common/ssh"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -17,14 +17,14 @@ func SSHHost(state multistep.StateBag) (string, error) {
 
 // SSHConfig returns a function that can be used for the SSH communicator
 // config for connecting to the instance created over SSH using the generated
-// private key.
-func SSHConfig(username string) func(multistep.StateBag) (*ssh.ClientConfig, error) {
+// private key. The passphrase decrypts a password protected private key.
+func SSHConfig(username, passphrase string) func(multistep.StateBag) (*ssh.ClientConfig, error) {
 	return func(state multistep.StateBag) (*ssh.ClientConfig, error) {
 		privateKey := state.Get(constants.PrivateKey).(string)
 
-		signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+		signer, err := commonssh.EncryptedSigner([]byte(privateKey), passphrase)
 		if err != nil {
-			return nil, fmt.Errorf("Error setting up SSH config: %s", err)
+			return nil, err
 		}
 
 		return &ssh.ClientConfig{
@@ -19,13 +19,13 @@ type Builder struct {
 
 // Prepare implements the packer.Builder interface.
 func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
-	config, errs := NewConfig(raws...)
+	config, warnings, errs := NewConfig(raws...)
 	if errs != nil {
-		return nil, errs
+		return warnings, errs
 	}
 	b.config = config
 
-	return nil, nil
+	return warnings, nil
 }
 
 // Run implements the packer.Builder interface.
@@ -69,14 +69,7 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 	}
 
 	// Configure the runner.
-	if b.config.PackerDebug {
-		b.runner = &multistep.DebugRunner{
-			Steps:   steps,
-			PauseFn: common.MultistepDebugFn(ui),
-		}
-	} else {
-		b.runner = &multistep.BasicRunner{Steps: steps}
-	}
+	b.runner = common.NewRunner(steps, b.config.PackerConfig, ui)
 
 	// Run the steps.
 	b.runner.Run(state)
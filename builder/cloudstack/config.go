@@ -59,15 +59,18 @@ type Config struct {
 }
 
 // NewConfig parses and validates the given config.
-func NewConfig(raws ...interface{}) (*Config, error) {
+func NewConfig(raws ...interface{}) (*Config, []string, error) {
 	c := new(Config)
+	var unknownKeys []string
 	err := config.Decode(c, &config.DecodeOpts{
 		Interpolate:        true,
 		InterpolateContext: &c.ctx,
+		UnknownKeys:        &unknownKeys,
 	}, raws...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	warnings := config.UnknownKeyWarnings(unknownKeys)
 
 	var errs *packer.MultiError
 
@@ -165,8 +168,8 @@ func NewConfig(raws ...interface{}) (*Config, error) {
 
 	// Check for errors and return if we have any.
 	if errs != nil && len(errs.Errors) > 0 {
-		return nil, errs
+		return nil, warnings, errs
 	}
 
-	return c, nil
+	return c, warnings, nil
 }
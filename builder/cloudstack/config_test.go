@@ -132,7 +132,7 @@ func TestNewConfig(t *testing.T) {
 			raw[tc.Nullify] = nil
 		}
 
-		_, errs := NewConfig(raw)
+		_, _, errs := NewConfig(raw)
 
 		if tc.Err {
 			if errs == nil {
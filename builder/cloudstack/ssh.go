@@ -2,9 +2,9 @@ package cloudstack
 
 import (
 	"fmt"
-	"io/ioutil"
 
 	"github.com/mitchellh/multistep"
+	commonssh "github.com/mitchellh/packer/common/ssh"
 	packerssh "github.com/mitchellh/packer/communicator/ssh"
 	"github.com/xanzy/go-cloudstack/cloudstack"
 	"golang.org/x/crypto/ssh"
@@ -39,16 +39,12 @@ func sshConfig(state multistep.StateBag) (*ssh.ClientConfig, error) {
 	}
 
 	if config.Comm.SSHPrivateKey != "" {
-		privateKey, err := ioutil.ReadFile(config.Comm.SSHPrivateKey)
+		signer, err := commonssh.EncryptedFileSigner(
+			config.Comm.SSHPrivateKey, config.Comm.SSHPrivateKeyPass)
 		if err != nil {
 			return nil, fmt.Errorf("Error loading configured private key file: %s", err)
 		}
 
-		signer, err := ssh.ParsePrivateKey([]byte(privateKey))
-		if err != nil {
-			return nil, fmt.Errorf("Error setting up SSH config: %s", err)
-		}
-
 		clientConfig.Auth = []ssh.AuthMethod{ssh.PublicKeys(signer)}
 	}
 
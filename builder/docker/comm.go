@@ -2,9 +2,9 @@ package docker
 
 import (
 	"fmt"
-	"io/ioutil"
 
 	"github.com/mitchellh/multistep"
+	commonssh "github.com/mitchellh/packer/common/ssh"
 	"github.com/mitchellh/packer/communicator/ssh"
 	"github.com/mitchellh/packer/helper/communicator"
 	gossh "golang.org/x/crypto/ssh"
@@ -20,13 +20,8 @@ func sshConfig(comm *communicator.Config) func(state multistep.StateBag) (*gossh
 	return func(state multistep.StateBag) (*gossh.ClientConfig, error) {
 		if comm.SSHPrivateKey != "" {
 			// key based auth
-			bytes, err := ioutil.ReadFile(comm.SSHPrivateKey)
-			if err != nil {
-				return nil, fmt.Errorf("Error setting up SSH config: %s", err)
-			}
-			privateKey := string(bytes)
-
-			signer, err := gossh.ParsePrivateKey([]byte(privateKey))
+			signer, err := commonssh.EncryptedFileSigner(
+				comm.SSHPrivateKey, comm.SSHPrivateKeyPass)
 			if err != nil {
 				return nil, fmt.Errorf("Error setting up SSH config: %s", err)
 			}
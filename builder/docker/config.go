@@ -53,6 +53,7 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 	c := new(Config)
 
 	var md mapstructure.Metadata
+	var unknownKeys []string
 	err := config.Decode(c, &config.DecodeOpts{
 		Metadata:           &md,
 		Interpolate:        true,
@@ -62,10 +63,12 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 				"run_command",
 			},
 		},
+		UnknownKeys: &unknownKeys,
 	}, raws...)
 	if err != nil {
 		return nil, nil, err
 	}
+	warnings := config.UnknownKeyWarnings(unknownKeys)
 
 	// Defaults
 	if len(c.RunCommand) == 0 {
@@ -117,8 +120,8 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 	}
 
 	if errs != nil && len(errs.Errors) > 0 {
-		return nil, nil, errs
+		return nil, warnings, errs
 	}
 
-	return c, nil, nil
+	return c, warnings, nil
 }
@@ -19,8 +19,9 @@ type Driver interface {
 	// Export exports the container with the given ID to the given writer.
 	Export(id string, dst io.Writer) error
 
-	// Import imports a container from a tar file
-	Import(path, repo string) (string, error)
+	// Import imports a container from a tar file, applying the given
+	// Dockerfile instructions to the image.
+	Import(path string, changes []string, repo string) (string, error)
 
 	// IPAddress returns the address of the container that can be used
 	// for external access.
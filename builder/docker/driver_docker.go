@@ -10,8 +10,10 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/go-version"
+	"github.com/mitchellh/packer/common"
 	"github.com/mitchellh/packer/packer"
 	"github.com/mitchellh/packer/template/interpolate"
 )
@@ -96,9 +98,16 @@ func (d *DockerDriver) Export(id string, dst io.Writer) error {
 	return nil
 }
 
-func (d *DockerDriver) Import(path string, repo string) (string, error) {
+func (d *DockerDriver) Import(path string, changes []string, repo string) (string, error) {
 	var stdout, stderr bytes.Buffer
-	cmd := exec.Command("docker", "import", "-", repo)
+
+	args := []string{"import"}
+	for _, change := range changes {
+		args = append(args, "--change", change)
+	}
+	args = append(args, "-", repo)
+
+	cmd := exec.Command("docker", args...)
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 	stdin, err := cmd.StdinPipe()
@@ -184,14 +193,26 @@ func (d *DockerDriver) Logout(repo string) error {
 	return err
 }
 
+// registryRetry retries pulls and pushes, which often fail because of
+// transient network or registry errors.
+var registryRetry = &common.RetryConfig{
+	Tries:          3,
+	InitialBackoff: 5 * time.Second,
+	Jitter:         0.2,
+}
+
 func (d *DockerDriver) Pull(image string) error {
-	cmd := exec.Command("docker", "pull", image)
-	return runAndStream(cmd, d.Ui)
+	return registryRetry.Run(func() error {
+		cmd := exec.Command("docker", "pull", image)
+		return runAndStream(cmd, d.Ui)
+	})
 }
 
 func (d *DockerDriver) Push(name string) error {
-	cmd := exec.Command("docker", "push", name)
-	return runAndStream(cmd, d.Ui)
+	return registryRetry.Run(func() error {
+		cmd := exec.Command("docker", "push", name)
+		return runAndStream(cmd, d.Ui)
+	})
 }
 
 func (d *DockerDriver) SaveImage(id string, dst io.Writer) error {
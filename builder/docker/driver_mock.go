@@ -17,11 +17,12 @@ type MockDriver struct {
 	DeleteImageId     string
 	DeleteImageErr    error
 
-	ImportCalled bool
-	ImportPath   string
-	ImportRepo   string
-	ImportId     string
-	ImportErr    error
+	ImportCalled  bool
+	ImportPath    string
+	ImportChanges []string
+	ImportRepo    string
+	ImportId      string
+	ImportErr     error
 
 	IPAddressCalled bool
 	IPAddressID     string
@@ -102,9 +103,10 @@ func (d *MockDriver) Export(id string, dst io.Writer) error {
 	return d.ExportError
 }
 
-func (d *MockDriver) Import(path, repo string) (string, error) {
+func (d *MockDriver) Import(path string, changes []string, repo string) (string, error) {
 	d.ImportCalled = true
 	d.ImportPath = path
+	d.ImportChanges = changes
 	d.ImportRepo = repo
 	return d.ImportId, d.ImportErr
 }
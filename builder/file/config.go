@@ -24,15 +24,18 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 	c := new(Config)
 	warnings := []string{}
 
+	var unknownKeys []string
 	err := config.Decode(c, &config.DecodeOpts{
 		Interpolate: true,
 		InterpolateFilter: &interpolate.RenderFilter{
 			Exclude: []string{},
 		},
+		UnknownKeys: &unknownKeys,
 	}, raws...)
 	if err != nil {
 		return nil, warnings, err
 	}
+	warnings = append(warnings, config.UnknownKeyWarnings(unknownKeys)...)
 
 	var errs *packer.MultiError
 
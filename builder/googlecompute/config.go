@@ -62,6 +62,7 @@ type Config struct {
 
 func NewConfig(raws ...interface{}) (*Config, []string, error) {
 	c := new(Config)
+	var unknownKeys []string
 	err := config.Decode(c, &config.DecodeOpts{
 		Interpolate:        true,
 		InterpolateContext: &c.ctx,
@@ -70,10 +71,12 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 				"run_command",
 			},
 		},
+		UnknownKeys: &unknownKeys,
 	}, raws...)
 	if err != nil {
 		return nil, nil, err
 	}
+	warnings := config.UnknownKeyWarnings(unknownKeys)
 
 	var errs *packer.MultiError
 
@@ -203,10 +206,10 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 
 	// Check for any errors.
 	if errs != nil && len(errs.Errors) > 0 {
-		return nil, nil, errs
+		return nil, warnings, errs
 	}
 
-	return c, nil, nil
+	return c, warnings, nil
 }
 
 func (c *Config) CalcTimeout() error {
@@ -1,9 +1,8 @@
 package googlecompute
 
 import (
-	"fmt"
-
 	"github.com/mitchellh/multistep"
+	commonssh "github.com/mitchellh/packer/common/ssh"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -17,9 +16,10 @@ func sshConfig(state multistep.StateBag) (*ssh.ClientConfig, error) {
 	config := state.Get("config").(*Config)
 	privateKey := state.Get("ssh_private_key").(string)
 
-	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+	signer, err := commonssh.EncryptedSigner(
+		[]byte(privateKey), config.Comm.SSHPrivateKeyPass)
 	if err != nil {
-		return nil, fmt.Errorf("Error setting up SSH config: %s", err)
+		return nil, err
 	}
 
 	return &ssh.ClientConfig{
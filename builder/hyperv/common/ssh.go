@@ -26,6 +26,18 @@ func CommHost(state multistep.StateBag) (string, error) {
 
 func SSHConfigFunc(config *SSHConfig) func(multistep.StateBag) (*gossh.ClientConfig, error) {
 	return func(state multistep.StateBag) (*gossh.ClientConfig, error) {
+		if config.Comm.SSHAgentAuth {
+			agentAuth, err := commonssh.AgentAuth()
+			if err != nil {
+				return nil, err
+			}
+
+			return &gossh.ClientConfig{
+				User: config.Comm.SSHUsername,
+				Auth: []gossh.AuthMethod{agentAuth},
+			}, nil
+		}
+
 		auth := []gossh.AuthMethod{
 			gossh.Password(config.Comm.SSHPassword),
 			gossh.KeyboardInteractive(
@@ -33,7 +45,8 @@ func SSHConfigFunc(config *SSHConfig) func(multistep.StateBag) (*gossh.ClientCon
 		}
 
 		if config.Comm.SSHPrivateKey != "" {
-			signer, err := commonssh.FileSigner(config.Comm.SSHPrivateKey)
+			signer, err := commonssh.EncryptedFileSigner(
+				config.Comm.SSHPrivateKey, config.Comm.SSHPrivateKeyPass)
 			if err != nil {
 				return nil, err
 			}
@@ -100,6 +100,7 @@ type Config struct {
 
 // Prepare processes the build configuration parameters.
 func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
+	var unknownKeys []string
 	err := config.Decode(&b.config, &config.DecodeOpts{
 		Interpolate: true,
 		InterpolateFilter: &interpolate.RenderFilter{
@@ -107,6 +108,7 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 				"boot_command",
 			},
 		},
+		UnknownKeys: &unknownKeys,
 	}, raws...)
 	if err != nil {
 		return nil, err
@@ -114,7 +116,7 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 
 	// Accumulate any errors and warnings
 	var errs *packer.MultiError
-	warnings := make([]string, 0)
+	warnings := config.UnknownKeyWarnings(unknownKeys)
 
 	isoWarnings, isoErrs := b.config.ISOConfig.Prepare(&b.config.ctx)
 	warnings = append(warnings, isoWarnings...)
@@ -309,19 +311,24 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 			Path:  b.config.OutputDir,
 		},
 		&common.StepDownload{
-			Checksum:     b.config.ISOChecksum,
-			ChecksumType: b.config.ISOChecksumType,
-			Description:  "ISO",
-			ResultKey:    "iso_path",
-			Url:          b.config.ISOUrls,
-			Extension:    b.config.TargetExtension,
-			TargetPath:   b.config.TargetPath,
+			CAFile:             b.config.ISOCAFile,
+			Checksum:           b.config.ISOChecksum,
+			ChecksumType:       b.config.ISOChecksumType,
+			Description:        "ISO",
+			ProbeMirrors:       b.config.ISOProbeMirrors,
+			ResultKey:          "iso_path",
+			Url:                b.config.ISOUrls,
+			Extension:          b.config.TargetExtension,
+			InsecureSkipVerify: b.config.ISOInsecureSkipVerify,
+			LinkLocalFile:      b.config.ISOLocalMode == "symlink",
+			TargetPath:         b.config.TargetPath,
 		},
 		&common.StepCreateFloppy{
 			Files: b.config.FloppyFiles,
 		},
 		&common.StepHTTPServer{
 			HTTPDir:     b.config.HTTPDir,
+			HTTPAddress: b.config.HTTPAddress,
 			HTTPPortMin: b.config.HTTPPortMin,
 			HTTPPortMax: b.config.HTTPPortMax,
 		},
@@ -380,6 +387,8 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 			Config:    &b.config.SSHConfig.Comm,
 			Host:      hypervcommon.CommHost,
 			SSHConfig: hypervcommon.SSHConfigFunc(&b.config.SSHConfig),
+			Debug:     b.config.PackerDebug,
+			DebugDir:  b.config.OutputDir,
 		},
 
 		// provision requires communicator to be setup
@@ -410,16 +419,7 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 	}
 
 	// Run the steps.
-	if b.config.PackerDebug {
-		pauseFn := common.MultistepDebugFn(ui)
-		state.Put("pauseFn", pauseFn)
-		b.runner = &multistep.DebugRunner{
-			Steps:   steps,
-			PauseFn: pauseFn,
-		}
-	} else {
-		b.runner = &multistep.BasicRunner{Steps: steps}
-	}
+	b.runner = common.NewRunnerWithPauseFn(steps, b.config.PackerConfig, ui, state)
 
 	b.runner.Run(state)
 
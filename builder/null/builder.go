@@ -31,10 +31,7 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 		&communicator.StepConnect{
 			Config: &b.config.CommConfig,
 			Host:   CommHost(b.config.CommConfig.Host()),
-			SSHConfig: SSHConfig(
-				b.config.CommConfig.SSHUsername,
-				b.config.CommConfig.SSHPassword,
-				b.config.CommConfig.SSHPrivateKey),
+			SSHConfig: SSHConfig(&b.config.CommConfig),
 		},
 		&common.StepProvision{},
 	}
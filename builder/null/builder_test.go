@@ -1,10 +1,32 @@
 package null
 
 import (
-	"github.com/mitchellh/packer/packer"
+	"strings"
 	"testing"
+
+	"github.com/mitchellh/packer/packer"
 )
 
 func TestBuilder_implBuilder(t *testing.T) {
 	var _ packer.Builder = new(Builder)
 }
+
+func TestBuilderPrepare_unknownKeys(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	config["ssh_hots"] = "foo"
+
+	warnings, err := b.Prepare(config)
+	if err == nil {
+		t.Fatal("should have error")
+	}
+
+	config["unknown_keys"] = "warn"
+	warnings, err = b.Prepare(config)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "ssh_hots") {
+		t.Fatalf("bad: %#v", warnings)
+	}
+}
@@ -19,41 +19,70 @@ type Config struct {
 func NewConfig(raws ...interface{}) (*Config, []string, error) {
 	var c Config
 
+	var unknownKeys []string
 	err := config.Decode(&c, &config.DecodeOpts{
 		Interpolate:       true,
 		InterpolateFilter: &interpolate.RenderFilter{},
+		UnknownKeys:       &unknownKeys,
 	}, raws...)
 	if err != nil {
 		return nil, nil, err
 	}
+	warnings := config.UnknownKeyWarnings(unknownKeys)
 
 	var errs *packer.MultiError
 	if es := c.CommConfig.Prepare(nil); len(es) > 0 {
 		errs = packer.MultiErrorAppend(errs, es...)
 	}
+	if c.CommConfig.Type != "none" {
+		errs = packer.MultiErrorAppend(errs, c.prepareComm()...)
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, warnings, errs
+	}
+
+	return &c, warnings, nil
+}
+
+// prepareComm validates that enough of the communicator configuration is
+// set to connect to the existing host. The "none" communicator needs no
+// host at all and is skipped entirely.
+func (c *Config) prepareComm() []error {
+	var errs []error
 	if c.CommConfig.Host() == "" {
-		errs = packer.MultiErrorAppend(errs,
+		errs = append(errs,
 			fmt.Errorf("a Host must be specified, please reference your communicator documentation"))
 	}
 
 	if c.CommConfig.User() == "" {
-		errs = packer.MultiErrorAppend(errs,
+		errs = append(errs,
 			fmt.Errorf("a Username must be specified, please reference your communicator documentation"))
 	}
 
-	if c.CommConfig.Password() == "" && c.CommConfig.SSHPrivateKey == "" {
-		errs = packer.MultiErrorAppend(errs,
+	if c.CommConfig.Type == "winrm" {
+		if c.CommConfig.WinRMPassword == "" {
+			errs = append(errs,
+				fmt.Errorf("a winrm_password must be specified"))
+		}
+
+		return errs
+	}
+
+	if c.CommConfig.SSHPassword == "" && c.CommConfig.SSHPrivateKey == "" && !c.CommConfig.SSHAgentAuth {
+		errs = append(errs,
 			fmt.Errorf("one authentication method must be specified, please reference your communicator documentation"))
 	}
 
 	if c.CommConfig.SSHPassword != "" && c.CommConfig.SSHPrivateKey != "" {
-		errs = packer.MultiErrorAppend(errs,
+		errs = append(errs,
 			fmt.Errorf("only one of ssh_password and ssh_private_key_file must be specified"))
 	}
 
-	if errs != nil && len(errs.Errors) > 0 {
-		return nil, nil, errs
+	if c.CommConfig.SSHAgentAuth && (c.CommConfig.SSHPassword != "" || c.CommConfig.SSHPrivateKey != "") {
+		errs = append(errs,
+			fmt.Errorf("ssh_agent_auth can not be combined with ssh_password or ssh_private_key_file"))
 	}
 
-	return &c, nil, nil
+	return errs
 }
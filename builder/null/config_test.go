@@ -98,3 +98,50 @@ func TestConfigPrepare_sshCredential(t *testing.T) {
 	_, warns, errs = NewConfig(raw)
 	testConfigErr(t, warns, errs)
 }
+
+func TestConfigPrepare_sshAgentAuth(t *testing.T) {
+	raw := testConfig()
+
+	// only ssh_agent_auth
+	delete(raw, "ssh_password")
+	raw["ssh_agent_auth"] = true
+	_, warns, errs := NewConfig(raw)
+	testConfigOk(t, warns, errs)
+
+	// ssh_agent_auth and ssh_password set
+	raw["ssh_password"] = "bad"
+	_, warns, errs = NewConfig(raw)
+	testConfigErr(t, warns, errs)
+}
+
+func TestConfigPrepare_winrm(t *testing.T) {
+	raw := map[string]interface{}{
+		"communicator":   "winrm",
+		"winrm_host":     "foo",
+		"winrm_username": "bar",
+		"winrm_password": "baz",
+	}
+
+	_, warns, errs := NewConfig(raw)
+	testConfigOk(t, warns, errs)
+
+	// No password
+	delete(raw, "winrm_password")
+	_, warns, errs = NewConfig(raw)
+	testConfigErr(t, warns, errs)
+
+	// No host
+	raw["winrm_password"] = "baz"
+	delete(raw, "winrm_host")
+	_, warns, errs = NewConfig(raw)
+	testConfigErr(t, warns, errs)
+}
+
+func TestConfigPrepare_none(t *testing.T) {
+	raw := map[string]interface{}{
+		"communicator": "none",
+	}
+
+	_, warns, errs := NewConfig(raw)
+	testConfigOk(t, warns, errs)
+}
@@ -2,10 +2,12 @@ package null
 
 import (
 	"fmt"
+
 	"github.com/mitchellh/multistep"
+	commonssh "github.com/mitchellh/packer/common/ssh"
 	"github.com/mitchellh/packer/communicator/ssh"
+	"github.com/mitchellh/packer/helper/communicator"
 	gossh "golang.org/x/crypto/ssh"
-	"io/ioutil"
 )
 
 func CommHost(host string) func(multistep.StateBag) (string, error) {
@@ -15,42 +17,51 @@ func CommHost(host string) func(multistep.StateBag) (string, error) {
 }
 
 // SSHConfig returns a function that can be used for the SSH communicator
-// config for connecting to the specified host via SSH
-// private_key_file has precedence over password!
-func SSHConfig(username string, password string, privateKeyFile string) func(multistep.StateBag) (*gossh.ClientConfig, error) {
+// config for connecting to the specified host via SSH.
+// ssh_agent_auth has precedence over private_key_file, which in turn
+// has precedence over password!
+func SSHConfig(config *communicator.Config) func(multistep.StateBag) (*gossh.ClientConfig, error) {
 	return func(state multistep.StateBag) (*gossh.ClientConfig, error) {
+		if config.SSHAgentAuth {
+			// agent based auth
 
-		if privateKeyFile != "" {
-			// key based auth
-
-			bytes, err := ioutil.ReadFile(privateKeyFile)
+			agentAuth, err := commonssh.AgentAuth()
 			if err != nil {
 				return nil, fmt.Errorf("Error setting up SSH config: %s", err)
 			}
-			privateKey := string(bytes)
 
-			signer, err := gossh.ParsePrivateKey([]byte(privateKey))
+			return &gossh.ClientConfig{
+				User: config.SSHUsername,
+				Auth: []gossh.AuthMethod{agentAuth},
+			}, nil
+		}
+
+		if config.SSHPrivateKey != "" {
+			// key based auth
+
+			signer, err := commonssh.EncryptedFileSigner(
+				config.SSHPrivateKey, config.SSHPrivateKeyPass)
 			if err != nil {
 				return nil, fmt.Errorf("Error setting up SSH config: %s", err)
 			}
 
 			return &gossh.ClientConfig{
-				User: username,
+				User: config.SSHUsername,
 				Auth: []gossh.AuthMethod{
 					gossh.PublicKeys(signer),
 				},
 			}, nil
-		} else {
-			// password based auth
-
-			return &gossh.ClientConfig{
-				User: username,
-				Auth: []gossh.AuthMethod{
-					gossh.Password(password),
-					gossh.KeyboardInteractive(
-						ssh.PasswordKeyboardInteractive(password)),
-				},
-			}, nil
 		}
+
+		// password based auth
+
+		return &gossh.ClientConfig{
+			User: config.SSHUsername,
+			Auth: []gossh.AuthMethod{
+				gossh.Password(config.SSHPassword),
+				gossh.KeyboardInteractive(
+					ssh.PasswordKeyboardInteractive(config.SSHPassword)),
+			},
+		}, nil
 	}
 }
@@ -34,6 +34,7 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 	var c Config
 
 	var md mapstructure.Metadata
+	var unknownKeys []string
 	err := config.Decode(&c, &config.DecodeOpts{
 		Metadata:           &md,
 		Interpolate:        true,
@@ -43,10 +44,12 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 				"run_command",
 			},
 		},
+		UnknownKeys: &unknownKeys,
 	}, raws...)
 	if err != nil {
 		return nil, nil, err
 	}
+	warnings := config.UnknownKeyWarnings(unknownKeys)
 
 	var errs *packer.MultiError
 
@@ -106,9 +109,9 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 	}
 
 	if errs != nil && len(errs.Errors) > 0 {
-		return nil, nil, errs
+		return nil, warnings, errs
 	}
 	common.ScrubConfig(c, c.Token)
 
-	return &c, nil, nil
+	return &c, warnings, nil
 }
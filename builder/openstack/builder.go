@@ -34,13 +34,16 @@ type Builder struct {
 }
 
 func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
+	var unknownKeys []string
 	err := config.Decode(&b.config, &config.DecodeOpts{
 		Interpolate:        true,
 		InterpolateContext: &b.config.ctx,
+		UnknownKeys:        &unknownKeys,
 	}, raws...)
 	if err != nil {
 		return nil, err
 	}
+	warnings := config.UnknownKeyWarnings(unknownKeys)
 
 	// Accumulate any errors
 	var errs *packer.MultiError
@@ -49,11 +52,11 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 	errs = packer.MultiErrorAppend(errs, b.config.RunConfig.Prepare(&b.config.ctx)...)
 
 	if errs != nil && len(errs.Errors) > 0 {
-		return nil, errs
+		return warnings, errs
 	}
 
 	log.Println(common.ScrubConfig(b.config, b.config.Password))
-	return nil, nil
+	return warnings, nil
 }
 
 func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packer.Artifact, error) {
@@ -111,7 +114,8 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 				b.config.SSHInterface,
 				b.config.SSHIPVersion),
 			SSHConfig: SSHConfig(b.config.RunConfig.Comm.SSHUsername,
-				b.config.RunConfig.Comm.SSHPassword),
+				b.config.RunConfig.Comm.SSHPassword,
+				b.config.RunConfig.Comm.SSHPrivateKeyPass),
 		},
 		&common.StepProvision{},
 		&StepStopServer{},
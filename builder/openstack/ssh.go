@@ -10,6 +10,7 @@ import (
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/floatingips"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
 	"github.com/mitchellh/multistep"
+	commonssh "github.com/mitchellh/packer/common/ssh"
 	packerssh "github.com/mitchellh/packer/communicator/ssh"
 	"golang.org/x/crypto/ssh"
 )
@@ -62,17 +63,17 @@ func CommHost(
 
 // SSHConfig returns a function that can be used for the SSH communicator
 // config for connecting to the instance created over SSH using a private key
-// or a password.
-func SSHConfig(username, password string) func(multistep.StateBag) (*ssh.ClientConfig, error) {
+// or a password. The passphrase decrypts a password protected private key.
+func SSHConfig(username, password, passphrase string) func(multistep.StateBag) (*ssh.ClientConfig, error) {
 	return func(state multistep.StateBag) (*ssh.ClientConfig, error) {
 
 		privateKey, hasKey := state.GetOk("privateKey")
 
 		if hasKey {
 
-			signer, err := ssh.ParsePrivateKey([]byte(privateKey.(string)))
+			signer, err := commonssh.EncryptedSigner([]byte(privateKey.(string)), passphrase)
 			if err != nil {
-				return nil, fmt.Errorf("Error setting up SSH config: %s", err)
+				return nil, err
 			}
 
 			return &ssh.ClientConfig{
@@ -28,6 +28,18 @@ func CommHost(state multistep.StateBag) (string, error) {
 // SSHConfigFunc returns SSH credentials to access the VM by SSH.
 func SSHConfigFunc(config SSHConfig) func(multistep.StateBag) (*ssh.ClientConfig, error) {
 	return func(state multistep.StateBag) (*ssh.ClientConfig, error) {
+		if config.Comm.SSHAgentAuth {
+			agentAuth, err := commonssh.AgentAuth()
+			if err != nil {
+				return nil, err
+			}
+
+			return &ssh.ClientConfig{
+				User: config.Comm.SSHUsername,
+				Auth: []ssh.AuthMethod{agentAuth},
+			}, nil
+		}
+
 		auth := []ssh.AuthMethod{
 			ssh.Password(config.Comm.SSHPassword),
 			ssh.KeyboardInteractive(
@@ -35,7 +47,8 @@ func SSHConfigFunc(config SSHConfig) func(multistep.StateBag) (*ssh.ClientConfig
 		}
 
 		if config.Comm.SSHPrivateKey != "" {
-			signer, err := commonssh.FileSigner(config.Comm.SSHPrivateKey)
+			signer, err := commonssh.EncryptedFileSigner(
+				config.Comm.SSHPrivateKey, config.Comm.SSHPrivateKeyPass)
 			if err != nil {
 				return nil, err
 			}
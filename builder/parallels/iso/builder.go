@@ -47,6 +47,7 @@ type Config struct {
 }
 
 func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
+	var unknownKeys []string
 	err := config.Decode(&b.config, &config.DecodeOpts{
 		Interpolate:        true,
 		InterpolateContext: &b.config.ctx,
@@ -58,6 +59,7 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 				"parallels_tools_guest_path",
 			},
 		},
+		UnknownKeys: &unknownKeys,
 	}, raws...)
 	if err != nil {
 		return nil, err
@@ -65,7 +67,7 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 
 	// Accumulate any errors and warnings
 	var errs *packer.MultiError
-	warnings := make([]string, 0)
+	warnings := config.UnknownKeyWarnings(unknownKeys)
 
 	isoWarnings, isoErrs := b.config.ISOConfig.Prepare(&b.config.ctx)
 	warnings = append(warnings, isoWarnings...)
@@ -136,13 +138,17 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 			ParallelsToolsMode:   b.config.ParallelsToolsMode,
 		},
 		&common.StepDownload{
-			Checksum:     b.config.ISOChecksum,
-			ChecksumType: b.config.ISOChecksumType,
-			Description:  "ISO",
-			Extension:    b.config.TargetExtension,
-			ResultKey:    "iso_path",
-			TargetPath:   b.config.TargetPath,
-			Url:          b.config.ISOUrls,
+			CAFile:             b.config.ISOCAFile,
+			Checksum:           b.config.ISOChecksum,
+			ChecksumType:       b.config.ISOChecksumType,
+			Description:        "ISO",
+			Extension:          b.config.TargetExtension,
+			InsecureSkipVerify: b.config.ISOInsecureSkipVerify,
+			LinkLocalFile:      b.config.ISOLocalMode == "symlink",
+			ProbeMirrors:       b.config.ISOProbeMirrors,
+			ResultKey:          "iso_path",
+			TargetPath:         b.config.TargetPath,
+			Url:                b.config.ISOUrls,
 		},
 		&parallelscommon.StepOutputDir{
 			Force: b.config.PackerForce,
@@ -151,9 +157,12 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 		&common.StepCreateFloppy{
 			Files:       b.config.FloppyConfig.FloppyFiles,
 			Directories: b.config.FloppyConfig.FloppyDirectories,
+			Label:       b.config.FloppyConfig.FloppyLabel,
+			Size:        b.config.FloppyConfig.FloppySize,
 		},
 		&common.StepHTTPServer{
 			HTTPDir:     b.config.HTTPDir,
+			HTTPAddress: b.config.HTTPAddress,
 			HTTPPortMin: b.config.HTTPPortMin,
 			HTTPPortMax: b.config.HTTPPortMax,
 		},
@@ -182,6 +191,8 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 			Config:    &b.config.SSHConfig.Comm,
 			Host:      parallelscommon.CommHost,
 			SSHConfig: parallelscommon.SSHConfigFunc(b.config.SSHConfig),
+			Debug:     b.config.PackerDebug,
+			DebugDir:  b.config.OutputDir,
 		},
 		&parallelscommon.StepUploadVersion{
 			Path: b.config.PrlctlVersionFile,
@@ -61,6 +61,8 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 		&common.StepCreateFloppy{
 			Files:       b.config.FloppyConfig.FloppyFiles,
 			Directories: b.config.FloppyConfig.FloppyDirectories,
+			Label:       b.config.FloppyConfig.FloppyLabel,
+			Size:        b.config.FloppyConfig.FloppySize,
 		},
 		&StepImport{
 			Name:       b.config.VMName,
@@ -87,6 +89,8 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 			Config:    &b.config.SSHConfig.Comm,
 			Host:      parallelscommon.CommHost,
 			SSHConfig: parallelscommon.SSHConfigFunc(b.config.SSHConfig),
+			Debug:     b.config.PackerDebug,
+			DebugDir:  b.config.OutputDir,
 		},
 		&parallelscommon.StepUploadVersion{
 			Path: b.config.PrlctlVersionFile,
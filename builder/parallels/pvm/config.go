@@ -34,6 +34,7 @@ type Config struct {
 
 func NewConfig(raws ...interface{}) (*Config, []string, error) {
 	c := new(Config)
+	var unknownKeys []string
 	err := config.Decode(c, &config.DecodeOpts{
 		Interpolate:        true,
 		InterpolateContext: &c.ctx,
@@ -45,6 +46,7 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 				"parallels_tools_guest_path",
 			},
 		},
+		UnknownKeys: &unknownKeys,
 	}, raws...)
 	if err != nil {
 		return nil, nil, err
@@ -76,7 +78,7 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 	}
 
 	// Warnings
-	var warnings []string
+	warnings := config.UnknownKeyWarnings(unknownKeys)
 	if c.ShutdownCommand == "" {
 		warnings = append(warnings,
 			"A shutdown_command was not specified. Without a shutdown command, Packer\n"+
@@ -36,6 +36,7 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 	var c Config
 
 	var md mapstructure.Metadata
+	var unknownKeys []string
 	err := config.Decode(&c, &config.DecodeOpts{
 		Metadata:           &md,
 		Interpolate:        true,
@@ -45,10 +46,12 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 				"run_command",
 			},
 		},
+		UnknownKeys: &unknownKeys,
 	}, raws...)
 	if err != nil {
 		return nil, nil, err
 	}
+	warnings := config.UnknownKeyWarnings(unknownKeys)
 
 	var errs *packer.MultiError
 
@@ -119,9 +122,9 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 	}
 
 	if errs != nil && len(errs.Errors) > 0 {
-		return nil, nil, errs
+		return nil, warnings, errs
 	}
 	common.ScrubConfig(c, c.PBUsername)
 
-	return &c, nil, nil
+	return &c, warnings, nil
 }
@@ -29,7 +29,18 @@ func (s *StepCreateSSHKey) Run(state multistep.StateBag) multistep.StepAction {
 
 		block, _ := pem.Decode(pemBytes)
 
-		priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		der := block.Bytes
+		if x509.IsEncryptedPEMBlock(block) {
+			der, err = x509.DecryptPEMBlock(block, []byte(c.Comm.SSHPrivateKeyPass))
+			if err != nil {
+				err := fmt.Errorf("Error decrypting ssh_private_key_file: %s", err)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+		}
+
+		priv, err := x509.ParsePKCS1PrivateKey(der)
 
 		if err != nil {
 
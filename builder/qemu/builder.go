@@ -7,7 +7,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/mitchellh/multistep"
@@ -22,6 +24,7 @@ const BuilderId = "transcend.qemu"
 
 var accels = map[string]struct{}{
 	"none": {},
+	"hvf":  {},
 	"kvm":  {},
 	"tcg":  {},
 	"xen":  {},
@@ -96,6 +99,7 @@ type Config struct {
 	Format            string     `mapstructure:"format"`
 	Headless          bool       `mapstructure:"headless"`
 	DiskImage         bool       `mapstructure:"disk_image"`
+	Firmware          string     `mapstructure:"firmware"`
 	MachineType       string     `mapstructure:"machine_type"`
 	NetDevice         string     `mapstructure:"net_device"`
 	OutputDir         string     `mapstructure:"output_directory"`
@@ -119,13 +123,21 @@ type Config struct {
 
 	RawBootWait        string `mapstructure:"boot_wait"`
 	RawShutdownTimeout string `mapstructure:"shutdown_timeout"`
+	BootKeyboardLayout string `mapstructure:"boot_keyboard_layout"`
+
+	// BootSteps are pairs of a regular expression to wait for on the
+	// serial console and the input to send once it matches.
+	BootSteps          [][]string `mapstructure:"boot_steps"`
+	RawBootStepTimeout string     `mapstructure:"boot_step_timeout"`
 
 	bootWait        time.Duration ``
 	shutdownTimeout time.Duration ``
+	bootStepTimeout time.Duration ``
 	ctx             interpolate.Context
 }
 
 func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
+	var unknownKeys []string
 	err := config.Decode(&b.config, &config.DecodeOpts{
 		Interpolate:        true,
 		InterpolateContext: &b.config.ctx,
@@ -135,13 +147,14 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 				"qemuargs",
 			},
 		},
+		UnknownKeys: &unknownKeys,
 	}, raws...)
 	if err != nil {
 		return nil, err
 	}
 
 	var errs *packer.MultiError
-	warnings := make([]string, 0)
+	warnings := config.UnknownKeyWarnings(unknownKeys)
 
 	if b.config.DiskSize == 0 {
 		b.config.DiskSize = 40000
@@ -155,9 +168,19 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 		b.config.DiskDiscard = "ignore"
 	}
 
+	if b.config.QemuBinary == "" {
+		b.config.QemuBinary = "qemu-system-x86_64"
+	}
+
 	if b.config.Accelerator == "" {
 		if runtime.GOOS == "windows" {
 			b.config.Accelerator = "tcg"
+		} else if runtime.GOOS == "darwin" {
+			if hvfAvailable(b.config.QemuBinary) {
+				b.config.Accelerator = "hvf"
+			} else {
+				b.config.Accelerator = "tcg"
+			}
 		} else {
 			// /dev/kvm is a kernel module that may be loaded if kvm is
 			// installed and the host supports VT-x extensions. To make sure
@@ -183,10 +206,6 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 		b.config.OutputDir = fmt.Sprintf("output-%s", b.config.PackerBuildName)
 	}
 
-	if b.config.QemuBinary == "" {
-		b.config.QemuBinary = "qemu-system-x86_64"
-	}
-
 	if b.config.RawBootWait == "" {
 		b.config.RawBootWait = "10s"
 	}
@@ -259,7 +278,22 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 
 	if _, ok := accels[b.config.Accelerator]; !ok {
 		errs = packer.MultiErrorAppend(
-			errs, errors.New("invalid accelerator, only 'kvm', 'tcg', 'xen', or 'none' are allowed"))
+			errs, errors.New("invalid accelerator, only 'kvm', 'hvf', 'tcg', 'xen', or 'none' are allowed"))
+	}
+
+	if layout, err := common.NewKeyboardLayout(b.config.BootKeyboardLayout); err != nil {
+		errs = packer.MultiErrorAppend(
+			errs, fmt.Errorf("boot_keyboard_layout is invalid: %s", err))
+	} else if err := layout.CheckBootCommand(b.config.BootCommand, true); err != nil {
+		errs = packer.MultiErrorAppend(
+			errs, fmt.Errorf("boot_command can't be typed: %s", err))
+	}
+
+	if b.config.Firmware != "" {
+		if _, err := os.Stat(b.config.Firmware); err != nil {
+			errs = packer.MultiErrorAppend(
+				errs, fmt.Errorf("firmware is invalid: %s", err))
+		}
 	}
 
 	if _, ok := netDevice[b.config.NetDevice]; !ok {
@@ -306,6 +340,29 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 			errs, fmt.Errorf("Failed parsing shutdown_timeout: %s", err))
 	}
 
+	if b.config.RawBootStepTimeout == "" {
+		b.config.RawBootStepTimeout = "5m"
+	}
+
+	b.config.bootStepTimeout, err = time.ParseDuration(b.config.RawBootStepTimeout)
+	if err != nil {
+		errs = packer.MultiErrorAppend(
+			errs, fmt.Errorf("Failed parsing boot_step_timeout: %s", err))
+	}
+
+	for i, step := range b.config.BootSteps {
+		if len(step) != 2 {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf(
+				"boot_steps[%d] must be a pair of what to expect and what to send", i))
+			continue
+		}
+
+		if _, err := regexp.Compile(step[0]); err != nil {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf(
+				"boot_steps[%d] has an invalid expectation: %s", i, err))
+		}
+	}
+
 	if b.config.SSHHostPortMin > b.config.SSHHostPortMax {
 		errs = packer.MultiErrorAppend(
 			errs, errors.New("ssh_host_port_min must be less than ssh_host_port_max"))
@@ -327,6 +384,29 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 	return warnings, nil
 }
 
+// hvfAvailable reports whether the hvf accelerator can be used: the host
+// supports the Hypervisor.framework, which the kern.hv_support sysctl
+// reports, and the QEMU binary was built with hvf.
+func hvfAvailable(qemuBinary string) bool {
+	out, err := exec.Command("sysctl", "-n", "kern.hv_support").Output()
+	if err != nil || strings.TrimSpace(string(out)) != "1" {
+		return false
+	}
+
+	// QEMU versions without hvf support don't know -accel either.
+	out, err = exec.Command(qemuBinary, "-accel", "help").Output()
+	if err != nil {
+		return false
+	}
+	for _, accel := range strings.Fields(string(out)) {
+		if accel == "hvf" {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packer.Artifact, error) {
 	// Create the driver that we'll use to communicate with Qemu
 	driver, err := b.newDriver(b.config.QemuBinary)
@@ -343,40 +423,53 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 		steprun.Message = "Starting VM, booting disk image"
 	}
 
-	steps := []multistep.Step{}
+	var isoStep multistep.Step
 	if !b.config.ISOSkipCache {
-		steps = append(steps, &common.StepDownload{
-			Checksum:     b.config.ISOChecksum,
-			ChecksumType: b.config.ISOChecksumType,
-			Description:  "ISO",
-			Extension:    b.config.TargetExtension,
-			ResultKey:    "iso_path",
-			TargetPath:   b.config.TargetPath,
-			Url:          b.config.ISOUrls,
-		},
-		)
+		isoStep = &common.StepDownload{
+			CAFile:             b.config.ISOCAFile,
+			Checksum:           b.config.ISOChecksum,
+			ChecksumType:       b.config.ISOChecksumType,
+			Description:        "ISO",
+			Extension:          b.config.TargetExtension,
+			InsecureSkipVerify: b.config.ISOInsecureSkipVerify,
+			LinkLocalFile:      b.config.ISOLocalMode == "symlink",
+			ProbeMirrors:       b.config.ISOProbeMirrors,
+			ResultKey:          "iso_path",
+			TargetPath:         b.config.TargetPath,
+			Url:                b.config.ISOUrls,
+		}
 	} else {
-		steps = append(steps, &stepSetISO{
+		isoStep = &stepSetISO{
 			ResultKey: "iso_path",
 			Url:       b.config.ISOUrls,
-		},
-		)
+		}
 	}
 
-	steps = append(steps, new(stepPrepareOutputDir),
-		&common.StepCreateFloppy{
-			Files:       b.config.FloppyConfig.FloppyFiles,
-			Directories: b.config.FloppyConfig.FloppyDirectories,
+	// The ISO is downloaded while the floppy and the disk are created,
+	// these don't depend on it.
+	steps := []multistep.Step{
+		new(stepPrepareOutputDir),
+		&common.StepParallel{
+			Steps: []multistep.Step{
+				isoStep,
+				&common.StepCreateFloppy{
+					Files:       b.config.FloppyConfig.FloppyFiles,
+					Directories: b.config.FloppyConfig.FloppyDirectories,
+					Label:       b.config.FloppyConfig.FloppyLabel,
+					Size:        b.config.FloppyConfig.FloppySize,
+				},
+				new(stepCreateDisk),
+			},
 		},
-		new(stepCreateDisk),
 		new(stepCopyDisk),
 		new(stepResizeDisk),
 		&common.StepHTTPServer{
 			HTTPDir:     b.config.HTTPDir,
+			HTTPAddress: b.config.HTTPAddress,
 			HTTPPortMin: b.config.HTTPPortMin,
 			HTTPPortMax: b.config.HTTPPortMax,
 		},
-	)
+	}
 
 	if b.config.Comm.Type != "none" {
 		steps = append(steps,
@@ -384,6 +477,12 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 		)
 	}
 
+	if len(b.config.BootSteps) > 0 {
+		steps = append(steps,
+			new(stepConfigureSerial),
+		)
+	}
+
 	steps = append(steps,
 		new(stepConfigureVNC),
 		steprun,
@@ -391,6 +490,12 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 		&stepTypeBootCommand{},
 	)
 
+	if len(b.config.BootSteps) > 0 {
+		steps = append(steps,
+			new(stepBootSteps),
+		)
+	}
+
 	if b.config.Comm.Type != "none" {
 		steps = append(steps,
 			&communicator.StepConnect{
@@ -399,6 +504,8 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 				SSHConfig: sshConfig,
 				SSHPort:   commPort,
 				WinRMPort: commPort,
+				Debug:     b.config.PackerDebug,
+				DebugDir:  b.config.OutputDir,
 			},
 		)
 	}
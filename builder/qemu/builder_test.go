@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/mitchellh/packer/packer"
 )
@@ -227,6 +230,138 @@ func TestBuilderPrepare_DiskSize(t *testing.T) {
 	}
 }
 
+func TestBuilderPrepare_Accelerator(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// Bad
+	config["accelerator"] = "bogus"
+	_, err := b.Prepare(config)
+	if err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Good
+	config["accelerator"] = "hvf"
+	b = Builder{}
+	_, err = b.Prepare(config)
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+}
+
+func TestHvfAvailable(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	// A host that supports the Hypervisor.framework
+	script := "#!/bin/sh\necho 1\n"
+	if err := ioutil.WriteFile(filepath.Join(td, "sysctl"), []byte(script), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", td+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	// A QEMU without hvf
+	qemu := filepath.Join(td, "qemu")
+	script = "#!/bin/sh\necho 'Accelerators supported in QEMU binary:'\necho tcg\n"
+	if err := ioutil.WriteFile(qemu, []byte(script), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if hvfAvailable(qemu) {
+		t.Fatal("hvf should not be available")
+	}
+
+	// A QEMU with hvf
+	script = "#!/bin/sh\necho 'Accelerators supported in QEMU binary:'\necho tcg\necho hvf\n"
+	if err := ioutil.WriteFile(qemu, []byte(script), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !hvfAvailable(qemu) {
+		t.Fatal("hvf should be available")
+	}
+
+	// A QEMU that doesn't know -accel
+	if hvfAvailable(filepath.Join(td, "missing")) {
+		t.Fatal("hvf should not be available")
+	}
+}
+
+func TestBuilderPrepare_BootSteps(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// Bad pair
+	config["boot_steps"] = [][]string{{"login:"}}
+	_, err := b.Prepare(config)
+	if err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Bad expectation
+	config["boot_steps"] = [][]string{{"login:(", "root<enter>"}}
+	b = Builder{}
+	_, err = b.Prepare(config)
+	if err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Bad timeout
+	config["boot_steps"] = [][]string{{"login:", "root<enter>"}}
+	config["boot_step_timeout"] = "forever"
+	b = Builder{}
+	_, err = b.Prepare(config)
+	if err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Good
+	delete(config, "boot_step_timeout")
+	b = Builder{}
+	_, err = b.Prepare(config)
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	if b.config.bootStepTimeout != 5*time.Minute {
+		t.Fatalf("bad: %s", b.config.bootStepTimeout)
+	}
+}
+
+func TestBuilderPrepare_Firmware(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// Bad
+	config["firmware"] = "/i/dont/exist/edk2.fd"
+	_, err := b.Prepare(config)
+	if err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Good
+	tf, err := ioutil.TempFile("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	tf.Close()
+	defer os.Remove(tf.Name())
+
+	config["firmware"] = tf.Name()
+	b = Builder{}
+	_, err = b.Prepare(config)
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+}
+
 func TestBuilderPrepare_Format(t *testing.T) {
 	var b Builder
 	config := testConfig()
@@ -0,0 +1,187 @@
+package qemu
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// serialConsoleMaxBuffer is how much unmatched output of the serial console
+// is kept around for the next expectation.
+const serialConsoleMaxBuffer = 1024 * 1024
+
+// serialConsolePollInterval is how often the output is checked while
+// waiting for an expectation.
+const serialConsolePollInterval = 100 * time.Millisecond
+
+// serialConsole is the first serial port (COM1) of the VM. QEMU connects to
+// the listener of the console when it starts, so that none of the output
+// of the VM is lost before the expectations are checked.
+type serialConsole struct {
+	l net.Listener
+
+	mu   sync.Mutex
+	conn net.Conn
+	buf  []byte
+	err  error
+}
+
+// newSerialConsole starts accepting the connection from QEMU on l.
+func newSerialConsole(l net.Listener) *serialConsole {
+	c := &serialConsole{l: l}
+	go c.accept()
+	return c
+}
+
+func (c *serialConsole) accept() {
+	conn, err := c.l.Accept()
+	if err != nil {
+		c.mu.Lock()
+		c.err = err
+		c.mu.Unlock()
+		return
+	}
+	log.Printf("Serial console connected: %s", conn.RemoteAddr())
+
+	c.mu.Lock()
+	c.conn = conn
+	c.mu.Unlock()
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		c.mu.Lock()
+		c.buf = append(c.buf, buf[:n]...)
+		if len(c.buf) > serialConsoleMaxBuffer {
+			c.buf = c.buf[len(c.buf)-serialConsoleMaxBuffer:]
+		}
+		if err != nil {
+			c.err = err
+		}
+		c.mu.Unlock()
+
+		if n > 0 {
+			log.Printf("Serial console: %q", buf[:n])
+		}
+		if err != nil {
+			log.Printf("Serial console closed: %s", err)
+			return
+		}
+	}
+}
+
+// Expect waits until the output of the console matches re, and discards
+// the output up to the end of the match so the next expectation only
+// matches newer output. It gives up after timeout or once cancelled
+// returns true.
+func (c *serialConsole) Expect(re *regexp.Regexp, timeout time.Duration, cancelled func() bool) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		c.mu.Lock()
+		loc := re.FindIndex(c.buf)
+		if loc != nil {
+			c.buf = c.buf[loc[1]:]
+		}
+		err := c.err
+		c.mu.Unlock()
+
+		if loc != nil {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("serial console closed before %q was found: %s", re, err)
+		}
+		if cancelled() {
+			return errors.New("cancelled")
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for %q on the serial console", re)
+		}
+
+		time.Sleep(serialConsolePollInterval)
+	}
+}
+
+// Send writes s to the console, waiting keyInterval after every character
+// to not overrun the input of the VM. The special codes <enter>, <return>,
+// <tab>, <esc>, <bs>, <spacebar>, <wait>, <wait5>, <wait10> and waits with
+// a duration such as <wait2m> are supported, like in the boot command.
+func (c *serialConsole) Send(s string, keyInterval time.Duration) error {
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	if conn == nil {
+		return errors.New("QEMU isn't connected to the serial console")
+	}
+
+	var err error
+	special := map[string]string{
+		"<bs>":       "\b",
+		"<enter>":    "\r",
+		"<esc>":      "\x1b",
+		"<return>":   "\r",
+		"<spacebar>": " ",
+		"<tab>":      "\t",
+	}
+	waitRe := regexp.MustCompile(`^<wait([0-9hms]*)>`)
+
+	for len(s) > 0 {
+		if m := waitRe.FindStringSubmatch(s); m != nil {
+			wait := time.Second
+			switch m[1] {
+			case "":
+			case "5":
+				wait = 5 * time.Second
+			case "10":
+				wait = 10 * time.Second
+			default:
+				if wait, err = time.ParseDuration(m[1]); err != nil {
+					return fmt.Errorf("bad wait %q: %s", m[0], err)
+				}
+			}
+
+			log.Printf("Special code %s found, sleeping %s", m[0], wait)
+			time.Sleep(wait)
+			s = s[len(m[0]):]
+			continue
+		}
+
+		var chunk, raw string
+		for code, value := range special {
+			if strings.HasPrefix(s, code) {
+				chunk, raw = value, code
+				break
+			}
+		}
+		if raw == "" {
+			raw = s[:1]
+			chunk = raw
+		}
+		s = s[len(raw):]
+
+		if _, err := conn.Write([]byte(chunk)); err != nil {
+			return err
+		}
+		time.Sleep(keyInterval)
+	}
+
+	return nil
+}
+
+// Close closes the listener and the connection from QEMU.
+func (c *serialConsole) Close() error {
+	err := c.l.Close()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil {
+		c.conn.Close()
+	}
+
+	return err
+}
@@ -0,0 +1,73 @@
+package qemu
+
+import (
+	"io/ioutil"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func testSerialConsole(t *testing.T) (*serialConsole, net.Conn) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	console := newSerialConsole(l)
+
+	// Connect like QEMU does when it starts
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return console, conn
+}
+
+func TestSerialConsole(t *testing.T) {
+	console, conn := testSerialConsole(t)
+	defer console.Close()
+	defer conn.Close()
+
+	notCancelled := func() bool { return false }
+
+	if _, err := conn.Write([]byte("FreeBSD/amd64\r\nlogin: ")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if err := console.Expect(regexp.MustCompile(`login: $`), time.Second, notCancelled); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The matched output is consumed
+	err := console.Expect(regexp.MustCompile(`login:`), 200*time.Millisecond, notCancelled)
+	if err == nil {
+		t.Fatal("should time out")
+	}
+
+	if err := console.Send("root<enter><wait0s>x", 0); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	console.Close()
+
+	input, err := ioutil.ReadAll(conn)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(input) != "root\rx" {
+		t.Fatalf("bad: %q", input)
+	}
+}
+
+func TestSerialConsole_cancel(t *testing.T) {
+	console, conn := testSerialConsole(t)
+	defer console.Close()
+	defer conn.Close()
+
+	cancelled := func() bool { return true }
+	err := console.Expect(regexp.MustCompile(`login:`), time.Minute, cancelled)
+	if err == nil {
+		t.Fatal("should be cancelled")
+	}
+}
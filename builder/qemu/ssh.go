@@ -19,6 +19,18 @@ func commPort(state multistep.StateBag) (int, error) {
 func sshConfig(state multistep.StateBag) (*gossh.ClientConfig, error) {
 	config := state.Get("config").(*Config)
 
+	if config.Comm.SSHAgentAuth {
+		agentAuth, err := commonssh.AgentAuth()
+		if err != nil {
+			return nil, err
+		}
+
+		return &gossh.ClientConfig{
+			User: config.Comm.SSHUsername,
+			Auth: []gossh.AuthMethod{agentAuth},
+		}, nil
+	}
+
 	auth := []gossh.AuthMethod{
 		gossh.Password(config.Comm.SSHPassword),
 		gossh.KeyboardInteractive(
@@ -26,7 +38,8 @@ func sshConfig(state multistep.StateBag) (*gossh.ClientConfig, error) {
 	}
 
 	if config.Comm.SSHPrivateKey != "" {
-		signer, err := commonssh.FileSigner(config.Comm.SSHPrivateKey)
+		signer, err := commonssh.EncryptedFileSigner(
+			config.Comm.SSHPrivateKey, config.Comm.SSHPrivateKeyPass)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,96 @@
+package qemu
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/mitchellh/multistep"
+	"github.com/mitchellh/packer/common"
+	"github.com/mitchellh/packer/packer"
+	"github.com/mitchellh/packer/template/interpolate"
+)
+
+// This step runs the boot steps against the serial console: for every
+// step it waits for the output to match the expectation and then sends
+// the input.
+//
+// Uses:
+//   config         *config
+//   http_port      uint
+//   serial_console *serialConsole
+//   ui             packer.Ui
+//
+// Produces:
+//   <nothing>
+type stepBootSteps struct{}
+
+func (s *stepBootSteps) Run(state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	console := state.Get("serial_console").(*serialConsole)
+	debug := state.Get("debug").(bool)
+	httpPort := state.Get("http_port").(uint)
+	ui := state.Get("ui").(packer.Ui)
+
+	var pauseFn multistep.DebugPauseFn
+	if debug {
+		pauseFn = state.Get("pauseFn").(multistep.DebugPauseFn)
+	}
+
+	keyInterval := common.PackerKeyDefault
+	if delay, err := time.ParseDuration(os.Getenv(common.PackerKeyEnv)); err == nil {
+		keyInterval = delay
+	}
+
+	ctx := config.ctx
+	ctx.Data = &bootCommandTemplateData{
+		"10.0.2.2",
+		httpPort,
+		config.VMName,
+	}
+
+	cancelled := func() bool {
+		_, ok := state.GetOk(multistep.StateCancelled)
+		return ok
+	}
+
+	ui.Say("Running the boot steps over the serial console...")
+	for i, step := range config.BootSteps {
+		ui.Message(fmt.Sprintf("Waiting for: %s", step[0]))
+		re := regexp.MustCompile(step[0])
+		if err := console.Expect(re, config.bootStepTimeout, cancelled); err != nil {
+			if cancelled() {
+				return multistep.ActionHalt
+			}
+
+			err := fmt.Errorf("Error running boot step %d: %s", i+1, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		send, err := interpolate.Render(step[1], &ctx)
+		if err != nil {
+			err := fmt.Errorf("Error preparing boot step %d: %s", i+1, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		if pauseFn != nil {
+			pauseFn(multistep.DebugLocationAfterRun, fmt.Sprintf("boot_steps[%d]: %s", i, send), state)
+		}
+
+		if err := console.Send(send, keyInterval); err != nil {
+			err := fmt.Errorf("Error sending boot step %d: %s", i+1, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+	}
+
+	return multistep.ActionContinue
+}
+
+func (*stepBootSteps) Cleanup(multistep.StateBag) {}
@@ -0,0 +1,52 @@
+package qemu
+
+import (
+	"fmt"
+	"log"
+	"net"
+
+	"github.com/mitchellh/multistep"
+	"github.com/mitchellh/packer/packer"
+)
+
+// This step opens the listener that QEMU connects the first serial port of
+// the VM to, for the boot steps.
+//
+// Uses:
+//   ui packer.Ui
+//
+// Produces:
+//   serial_console *serialConsole
+//   serial_port    uint
+//   serial_tty     string - The address of the serial console.
+type stepConfigureSerial struct {
+	console *serialConsole
+}
+
+func (s *stepConfigureSerial) Run(state multistep.StateBag) multistep.StepAction {
+	ui := state.Get("ui").(packer.Ui)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		err := fmt.Errorf("Error listening for the serial console: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	port := uint(l.Addr().(*net.TCPAddr).Port)
+	log.Printf("Listening for the serial console on port %d", port)
+
+	s.console = newSerialConsole(l)
+	state.Put("serial_console", s.console)
+	state.Put("serial_port", port)
+	state.Put("serial_tty", fmt.Sprintf("tcp:127.0.0.1:%d", port))
+
+	return multistep.ActionContinue
+}
+
+func (s *stepConfigureSerial) Cleanup(state multistep.StateBag) {
+	if s.console != nil {
+		s.console.Close()
+	}
+}
@@ -2,11 +2,12 @@ package qemu
 
 import (
 	"fmt"
-	"github.com/mitchellh/multistep"
-	"github.com/mitchellh/packer/packer"
 	"log"
-	"math/rand"
 	"net"
+
+	"github.com/mitchellh/multistep"
+	"github.com/mitchellh/packer/common"
+	"github.com/mitchellh/packer/packer"
 )
 
 // This step configures the VM to enable the VNC server.
@@ -17,40 +18,38 @@ import (
 //
 // Produces:
 //   vnc_port uint - The port that VNC is configured to listen on.
-type stepConfigureVNC struct{}
+type stepConfigureVNC struct {
+	l net.Listener
+}
 
-func (stepConfigureVNC) Run(state multistep.StateBag) multistep.StepAction {
+func (s *stepConfigureVNC) Run(state multistep.StateBag) multistep.StepAction {
 	config := state.Get("config").(*Config)
 	ui := state.Get("ui").(packer.Ui)
 
-	// Find an open VNC port. Note that this can still fail later on
-	// because we have to release the port at some point. But this does its
-	// best.
+	// Find an open VNC port. The listener holds the port until stepRun
+	// hands it to QEMU.
 	msg := fmt.Sprintf("Looking for available port between %d and %d on %s", config.VNCPortMin, config.VNCPortMax, config.VNCBindAddress)
 	ui.Say(msg)
 	log.Printf(msg)
-	var vncPort uint
-	portRange := int(config.VNCPortMax - config.VNCPortMin)
-	for {
-		if portRange > 0 {
-			vncPort = uint(rand.Intn(portRange)) + config.VNCPortMin
-		} else {
-			vncPort = config.VNCPortMin
-		}
-
-		log.Printf("Trying port: %d", vncPort)
-		l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", config.VNCBindAddress, vncPort))
-		if err == nil {
-			defer l.Close()
-			break
-		}
+	l, vncPort, err := common.ListenRange(config.VNCBindAddress, config.VNCPortMin, config.VNCPortMax)
+	if err != nil {
+		err := fmt.Errorf("Error finding VNC port: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
 	}
 
 	log.Printf("Found available VNC port: %d on IP: %s", vncPort, config.VNCBindAddress)
+	s.l = l
 	state.Put("vnc_port", vncPort)
 	state.Put("vnc_ip", config.VNCBindAddress)
+	state.Put("vnc_port_listener", l)
 
 	return multistep.ActionContinue
 }
 
-func (stepConfigureVNC) Cleanup(multistep.StateBag) {}
+func (s *stepConfigureVNC) Cleanup(multistep.StateBag) {
+	if s.l != nil {
+		s.l.Close()
+	}
+}
@@ -3,10 +3,10 @@ package qemu
 import (
 	"fmt"
 	"log"
-	"math/rand"
 	"net"
 
 	"github.com/mitchellh/multistep"
+	"github.com/mitchellh/packer/common"
 	"github.com/mitchellh/packer/packer"
 )
 
@@ -16,37 +16,35 @@ import (
 // Uses:
 //
 // Produces:
-type stepForwardSSH struct{}
+type stepForwardSSH struct {
+	l net.Listener
+}
 
 func (s *stepForwardSSH) Run(state multistep.StateBag) multistep.StepAction {
 	config := state.Get("config").(*Config)
 	ui := state.Get("ui").(packer.Ui)
 
 	log.Printf("Looking for available communicator (SSH, WinRM, etc) port between %d and %d", config.SSHHostPortMin, config.SSHHostPortMax)
-	var sshHostPort uint
-
-	portRange := config.SSHHostPortMax - config.SSHHostPortMin + 1
-	offset := uint(rand.Intn(int(portRange)))
-
-	for {
-		sshHostPort = offset + config.SSHHostPortMin
-		log.Printf("Trying port: %d", sshHostPort)
-		l, err := net.Listen("tcp", fmt.Sprintf(":%d", sshHostPort))
-		if err == nil {
-			defer l.Close()
-			break
-		}
-		offset++
-		if offset == portRange {
-			offset = 0
-		}
+	l, sshHostPort, err := common.ListenRange("", config.SSHHostPortMin, config.SSHHostPortMax)
+	if err != nil {
+		err := fmt.Errorf("Error finding port for communicator: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
 	}
 	ui.Say(fmt.Sprintf("Found port for communicator (SSH, WinRM, etc): %d.", sshHostPort))
 
-	// Save the port we're using so that future steps can use it
+	// Save the port we're using so that future steps can use it. The
+	// listener holds the port until stepRun hands it to QEMU.
+	s.l = l
 	state.Put("sshHostPort", sshHostPort)
+	state.Put("sshHostPortListener", l)
 
 	return multistep.ActionContinue
 }
 
-func (s *stepForwardSSH) Cleanup(state multistep.StateBag) {}
+func (s *stepForwardSSH) Cleanup(state multistep.StateBag) {
+	if s.l != nil {
+		s.l.Close()
+	}
+}
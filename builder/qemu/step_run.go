@@ -3,6 +3,7 @@ package qemu
 import (
 	"fmt"
 	"log"
+	"net"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -40,6 +41,13 @@ func (s *stepRun) Run(state multistep.StateBag) multistep.StepAction {
 		return multistep.ActionHalt
 	}
 
+	// Release the ports that were held for QEMU right before starting it
+	for _, key := range []string{"sshHostPortListener", "vnc_port_listener"} {
+		if l, ok := state.GetOk(key); ok {
+			l.(net.Listener).Close()
+		}
+	}
+
 	if err := driver.Qemu(command...); err != nil {
 		err := fmt.Errorf("Error launching VM: %s", err)
 		ui.Error(err.Error())
@@ -141,8 +149,16 @@ func getCommandArgs(bootDrive string, state multistep.StateBag) ([]string, error
 	}
 	defaultArgs["-boot"] = bootDrive
 	defaultArgs["-m"] = "512M"
+	if config.Firmware != "" {
+		defaultArgs["-bios"] = config.Firmware
+	}
 	defaultArgs["-vnc"] = vnc
 
+	// QEMU connects the first serial port to the console of the boot steps
+	if serialPort, ok := state.GetOk("serial_port"); ok {
+		defaultArgs["-serial"] = fmt.Sprintf("tcp:127.0.0.1:%d", serialPort.(uint))
+	}
+
 	// Append the accelerator to the machine type if it is specified
 	if config.Accelerator != "none" {
 		defaultArgs["-machine"] = fmt.Sprintf("%s,accel=%s", defaultArgs["-machine"], config.Accelerator)
@@ -23,9 +23,11 @@ type Builder struct {
 func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 	errs := &multierror.Error{}
 
+	var unknownKeys []string
 	err := config.Decode(&b.config, &config.DecodeOpts{
 		Interpolate:        true,
 		InterpolateContext: &b.config.ctx,
+		UnknownKeys:        &unknownKeys,
 	}, raws...)
 	if err != nil {
 		errs = multierror.Append(errs, err)
@@ -36,7 +38,7 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 	errs = multierror.Append(errs, b.config.Comm.Prepare(&b.config.ctx)...)
 	errs = multierror.Append(errs, b.config.TargetImageConfig.Prepare(&b.config.ctx)...)
 
-	return nil, errs.ErrorOrNil()
+	return config.UnknownKeyWarnings(unknownKeys), errs.ErrorOrNil()
 }
 
 func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packer.Artifact, error) {
@@ -63,6 +65,7 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 				b.config.Comm.SSHAgentAuth,
 				b.config.Comm.SSHUsername,
 				b.config.Comm.SSHPrivateKey,
+				b.config.Comm.SSHPrivateKeyPass,
 				b.config.Comm.SSHPassword),
 		},
 		&common.StepProvision{},
@@ -4,10 +4,10 @@ import (
 	"fmt"
 
 	"github.com/mitchellh/multistep"
+	commonssh "github.com/mitchellh/packer/common/ssh"
 	packerssh "github.com/mitchellh/packer/communicator/ssh"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
-	"io/ioutil"
 	"log"
 	"net"
 	"os"
@@ -27,8 +27,8 @@ func commHost(state multistep.StateBag) (string, error) {
 
 // SSHConfig returns a function that can be used for the SSH communicator
 // config for connecting to the instance created over SSH using the private key
-// or password.
-func sshConfig(useAgent bool, username, privateKeyPath, password string) func(multistep.StateBag) (*ssh.ClientConfig, error) {
+// or password. The passphrase decrypts a password protected private key.
+func sshConfig(useAgent bool, username, privateKeyPath, passphrase, password string) func(multistep.StateBag) (*ssh.ClientConfig, error) {
 	return func(state multistep.StateBag) (*ssh.ClientConfig, error) {
 
 		if useAgent {
@@ -57,16 +57,11 @@ func sshConfig(useAgent bool, username, privateKeyPath, password string) func(mu
 		if hasKey {
 			log.Printf("Configuring SSH private key '%s'.", privateKeyPath)
 
-			privateKeyBytes, err := ioutil.ReadFile(privateKeyPath)
+			signer, err := commonssh.EncryptedFileSigner(privateKeyPath, passphrase)
 			if err != nil {
 				return nil, fmt.Errorf("Unable to read SSH private key: %s", err)
 			}
 
-			signer, err := ssh.ParsePrivateKey(privateKeyBytes)
-			if err != nil {
-				return nil, fmt.Errorf("Error setting up SSH config: %s", err)
-			}
-
 			return &ssh.ClientConfig{
 				User: username,
 				Auth: []ssh.AuthMethod{
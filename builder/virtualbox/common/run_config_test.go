@@ -59,3 +59,30 @@ func TestRunConfigPrepare_VRDPBindAddress(t *testing.T) {
 		t.Fatalf("should not have error: %s", errs)
 	}
 }
+
+func TestRunConfigPrepare_BootKeyboardLayout(t *testing.T) {
+	c := new(RunConfig)
+	c.BootKeyboardLayout = "de"
+	errs := c.Prepare(testConfigTemplate(t))
+	if len(errs) > 0 {
+		t.Fatalf("should not have error: %s", errs)
+	}
+
+	c = new(RunConfig)
+	c.BootKeyboardLayout = "xx"
+	errs = c.Prepare(testConfigTemplate(t))
+	if len(errs) == 0 {
+		t.Fatalf("bad: %#v", errs)
+	}
+}
+
+func TestRunConfigCheckBootCommand(t *testing.T) {
+	c := new(RunConfig)
+	c.BootKeyboardLayout = "de"
+	if err := c.CheckBootCommand([]string{"a<b|c<enter>"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := c.CheckBootCommand([]string{"aæb"}); err == nil {
+		t.Fatal("should have error")
+	}
+}
@@ -20,6 +20,18 @@ func SSHPort(state multistep.StateBag) (int, error) {
 
 func SSHConfigFunc(config SSHConfig) func(multistep.StateBag) (*gossh.ClientConfig, error) {
 	return func(state multistep.StateBag) (*gossh.ClientConfig, error) {
+		if config.Comm.SSHAgentAuth {
+			agentAuth, err := commonssh.AgentAuth()
+			if err != nil {
+				return nil, err
+			}
+
+			return &gossh.ClientConfig{
+				User: config.Comm.SSHUsername,
+				Auth: []gossh.AuthMethod{agentAuth},
+			}, nil
+		}
+
 		auth := []gossh.AuthMethod{
 			gossh.Password(config.Comm.SSHPassword),
 			gossh.KeyboardInteractive(
@@ -27,7 +39,8 @@ func SSHConfigFunc(config SSHConfig) func(multistep.StateBag) (*gossh.ClientConf
 		}
 
 		if config.Comm.SSHPrivateKey != "" {
-			signer, err := commonssh.FileSigner(config.Comm.SSHPrivateKey)
+			signer, err := commonssh.EncryptedFileSigner(
+				config.Comm.SSHPrivateKey, config.Comm.SSHPrivateKeyPass)
 			if err != nil {
 				return nil, err
 			}
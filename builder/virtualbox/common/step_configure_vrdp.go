@@ -3,10 +3,10 @@ package common
 import (
 	"fmt"
 	"log"
-	"math/rand"
 	"net"
 
 	"github.com/mitchellh/multistep"
+	"github.com/mitchellh/packer/common"
 	"github.com/mitchellh/packer/packer"
 )
 
@@ -24,6 +24,8 @@ type StepConfigureVRDP struct {
 	VRDPBindAddress string
 	VRDPPortMin     uint
 	VRDPPortMax     uint
+
+	l net.Listener
 }
 
 func (s *StepConfigureVRDP) Run(state multistep.StateBag) multistep.StepAction {
@@ -32,23 +34,14 @@ func (s *StepConfigureVRDP) Run(state multistep.StateBag) multistep.StepAction {
 	vmName := state.Get("vmName").(string)
 
 	log.Printf("Looking for available port between %d and %d on %s", s.VRDPPortMin, s.VRDPPortMax, s.VRDPBindAddress)
-	var vrdpPort uint
-	portRange := int(s.VRDPPortMax - s.VRDPPortMin)
-
-	for {
-		if portRange > 0 {
-			vrdpPort = uint(rand.Intn(portRange)) + s.VRDPPortMin
-		} else {
-			vrdpPort = s.VRDPPortMin
-		}
-
-		log.Printf("Trying port: %d", vrdpPort)
-		l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", s.VRDPBindAddress, vrdpPort))
-		if err == nil {
-			defer l.Close()
-			break
-		}
+	l, vrdpPort, err := common.ListenRange(s.VRDPBindAddress, s.VRDPPortMin, s.VRDPPortMax)
+	if err != nil {
+		err := fmt.Errorf("Error finding VRDP port: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
 	}
+	s.l = l
 
 	command := []string{
 		"modifyvm", vmName,
@@ -68,7 +61,14 @@ func (s *StepConfigureVRDP) Run(state multistep.StateBag) multistep.StepAction {
 	state.Put("vrdpIp", s.VRDPBindAddress)
 	state.Put("vrdpPort", vrdpPort)
 
+	// The listener holds the port until StepRun starts the VM.
+	state.Put("vrdpPortListener", l)
+
 	return multistep.ActionContinue
 }
 
-func (s *StepConfigureVRDP) Cleanup(state multistep.StateBag) {}
+func (s *StepConfigureVRDP) Cleanup(state multistep.StateBag) {
+	if s.l != nil {
+		s.l.Close()
+	}
+}
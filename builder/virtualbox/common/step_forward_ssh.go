@@ -3,10 +3,10 @@ package common
 import (
 	"fmt"
 	"log"
-	"math/rand"
 	"net"
 
 	"github.com/mitchellh/multistep"
+	"github.com/mitchellh/packer/common"
 	"github.com/mitchellh/packer/helper/communicator"
 	"github.com/mitchellh/packer/packer"
 )
@@ -25,6 +25,8 @@ type StepForwardSSH struct {
 	HostPortMin    uint
 	HostPortMax    uint
 	SkipNatMapping bool
+
+	l net.Listener
 }
 
 func (s *StepForwardSSH) Run(state multistep.StateBag) multistep.StepAction {
@@ -38,22 +40,15 @@ func (s *StepForwardSSH) Run(state multistep.StateBag) multistep.StepAction {
 		log.Printf("Looking for available communicator (SSH, WinRM, etc) port between %d and %d",
 			s.HostPortMin, s.HostPortMax)
 
-		portRange := int(s.HostPortMax - s.HostPortMin + 1)
-		offset := rand.Intn(portRange)
-
-		for {
-			sshHostPort = offset + int(s.HostPortMin)
-			log.Printf("Trying port: %d", sshHostPort)
-			l, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", sshHostPort))
-			if err == nil {
-				defer l.Close()
-				break
-			}
-			offset++
-			if offset == portRange {
-				offset = 0
-			}
+		l, port, err := common.ListenRange("127.0.0.1", s.HostPortMin, s.HostPortMax)
+		if err != nil {
+			err := fmt.Errorf("Error finding port for communicator: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
 		}
+		s.l = l
+		sshHostPort = int(port)
 
 		// Create a forwarded port mapping to the VM
 		ui.Say(fmt.Sprintf("Creating forwarded port mapping for communicator (SSH, WinRM, etc) (host port %d)", sshHostPort))
@@ -68,6 +63,9 @@ func (s *StepForwardSSH) Run(state multistep.StateBag) multistep.StepAction {
 			ui.Error(err.Error())
 			return multistep.ActionHalt
 		}
+
+		// The listener holds the port until StepRun starts the VM.
+		state.Put("sshHostPortListener", l)
 	}
 
 	// Save the port we're using so that future steps can use it
@@ -76,4 +74,8 @@ func (s *StepForwardSSH) Run(state multistep.StateBag) multistep.StepAction {
 	return multistep.ActionContinue
 }
 
-func (s *StepForwardSSH) Cleanup(state multistep.StateBag) {}
+func (s *StepForwardSSH) Cleanup(state multistep.StateBag) {
+	if s.l != nil {
+		s.l.Close()
+	}
+}
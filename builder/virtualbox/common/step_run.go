@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/mitchellh/multistep"
 	"github.com/mitchellh/packer/packer"
+	"net"
 	"time"
 )
 
@@ -48,6 +49,14 @@ func (s *StepRun) Run(state multistep.StateBag) multistep.StepAction {
 		}
 		guiArgument = "headless"
 	}
+
+	// Release the ports that were held for the VM right before starting it
+	for _, key := range []string{"sshHostPortListener", "vrdpPortListener"} {
+		if l, ok := state.GetOk(key); ok {
+			l.(net.Listener).Close()
+		}
+	}
+
 	command := []string{"startvm", vmName, "--type", guiArgument}
 	if err := driver.VBoxManage(command...); err != nil {
 		err := fmt.Errorf("Error starting VM: %s", err)
@@ -5,7 +5,6 @@ import (
 	"log"
 	"strings"
 	"time"
-	"unicode"
 	"unicode/utf8"
 
 	"github.com/mitchellh/multistep"
@@ -33,9 +32,10 @@ type bootCommandTemplateData struct {
 // Produces:
 //   <nothing>
 type StepTypeBootCommand struct {
-	BootCommand []string
-	VMName      string
-	Ctx         interpolate.Context
+	BootCommand    []string
+	KeyboardLayout string
+	VMName         string
+	Ctx            interpolate.Context
 }
 
 func (s *StepTypeBootCommand) Run(state multistep.StateBag) multistep.StepAction {
@@ -58,6 +58,13 @@ func (s *StepTypeBootCommand) Run(state multistep.StateBag) multistep.StepAction
 		s.VMName,
 	}
 
+	layout, err := common.NewKeyboardLayout(s.KeyboardLayout)
+	if err != nil {
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
 	ui.Say("Typing the boot command...")
 	for i, command := range s.BootCommand {
 		command, err := interpolate.Render(command, &s.Ctx)
@@ -68,7 +75,7 @@ func (s *StepTypeBootCommand) Run(state multistep.StateBag) multistep.StepAction
 			return multistep.ActionHalt
 		}
 
-		for _, code := range scancodes(command) {
+		for _, code := range scancodes(command, layout) {
 			if code == "wait" {
 				time.Sleep(1 * time.Second)
 				continue
@@ -109,7 +116,7 @@ func (s *StepTypeBootCommand) Run(state multistep.StateBag) multistep.StepAction
 
 func (*StepTypeBootCommand) Cleanup(multistep.StateBag) {}
 
-func scancodes(message string) []string {
+func scancodes(message string, layout common.KeyboardLayout) []string {
 	// Scancodes reference: http://www.win.tue.nl/~aeb/linux/kbd/scancodes-1.html
 	//
 	// Scancodes represent raw keyboard output and are fed to the VM by the
@@ -152,8 +159,6 @@ func scancodes(message string) []string {
 	special["<rightCtrl>"] = []string{"e01d", "e09d"}
 	special["<rightShift>"] = []string{"36", "b6"}
 
-	shiftedChars := "~!@#$%^&*()_+{}|:\"<>?"
-
 	scancodeIndex := make(map[string]uint)
 	scancodeIndex["1234567890-="] = 0x02
 	scancodeIndex["!@#$%^&*()_+"] = 0x02
@@ -175,6 +180,7 @@ func scancodes(message string) []string {
 			i += 1
 		}
 	}
+	scancodeMap[common.KeyISO] = 0x56
 
 	result := make([]string, 0, len(message)*2)
 	for len(message) > 0 {
@@ -284,22 +290,39 @@ func scancodes(message string) []string {
 		if scancode == nil {
 			r, size := utf8.DecodeRuneInString(message)
 			message = message[size:]
-			scancodeInt := scancodeMap[r]
-			keyShift := unicode.IsUpper(r) || strings.ContainsRune(shiftedChars, r)
 
-			scancode = make([]string, 0, 4)
+			// Type the key that produces the character on the keyboard
+			// layout of the guest.
+			stroke := layout.Stroke(r)
+			scancodeInt := scancodeMap[stroke.Key]
+			keyShift := stroke.Shift
+			keyAltGr := stroke.AltGr
+			keyDead := stroke.Dead
+
+			scancode = make([]string, 0, 10)
 			if keyShift {
 				scancode = append(scancode, "2a")
 			}
+			if keyAltGr {
+				scancode = append(scancode, "e0", "38")
+			}
 
 			scancode = append(scancode, fmt.Sprintf("%02x", scancodeInt))
 
+			if keyAltGr {
+				scancode = append(scancode, "e0", "b8")
+			}
 			if keyShift {
 				scancode = append(scancode, "aa")
 			}
 
 			scancode = append(scancode, fmt.Sprintf("%02x", scancodeInt+0x80))
-			log.Printf("Sending char '%c', code '%v', shift %v", r, scancode, keyShift)
+
+			// Dead keys only produce their character once followed by a space.
+			if keyDead {
+				scancode = append(scancode, "39", "b9")
+			}
+			log.Printf("Sending char '%c', code '%v', shift %v, altgr %v", r, scancode, keyShift, keyAltGr)
 		}
 
 		result = append(result, scancode...)
@@ -0,0 +1,37 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mitchellh/packer/common"
+)
+
+func TestScancodes(t *testing.T) {
+	de, err := common.NewKeyboardLayout("de")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	cases := []struct {
+		Layout   common.KeyboardLayout
+		Input    string
+		Expected []string
+	}{
+		{nil, "y", []string{"15", "95"}},
+		{nil, "/", []string{"35", "b5"}},
+		{de, "y", []string{"2c", "ac"}},
+		{de, "/", []string{"2a", "08", "aa", "88"}},
+		{de, "@", []string{"e0", "38", "10", "e0", "b8", "90"}},
+		{de, "^", []string{"29", "a9", "39", "b9"}},
+		{de, "<", []string{"56", "d6"}},
+		{de, "|", []string{"e0", "38", "56", "e0", "b8", "d6"}},
+	}
+
+	for _, tc := range cases {
+		actual := scancodes(tc.Input, tc.Layout)
+		if !reflect.DeepEqual(actual, tc.Expected) {
+			t.Fatalf("%q: bad: %#v", tc.Input, actual)
+		}
+	}
+}
@@ -56,6 +56,7 @@ type Config struct {
 }
 
 func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
+	var unknownKeys []string
 	err := config.Decode(&b.config, &config.DecodeOpts{
 		Interpolate:        true,
 		InterpolateContext: &b.config.ctx,
@@ -68,6 +69,7 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 				"vboxmanage_post",
 			},
 		},
+		UnknownKeys: &unknownKeys,
 	}, raws...)
 	if err != nil {
 		return nil, err
@@ -75,7 +77,7 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 
 	// Accumulate any errors and warnings
 	var errs *packer.MultiError
-	warnings := make([]string, 0)
+	warnings := config.UnknownKeyWarnings(unknownKeys)
 
 	isoWarnings, isoErrs := b.config.ISOConfig.Prepare(&b.config.ctx)
 	warnings = append(warnings, isoWarnings...)
@@ -88,6 +90,9 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 		errs, b.config.OutputConfig.Prepare(&b.config.ctx, &b.config.PackerConfig)...)
 	errs = packer.MultiErrorAppend(errs, b.config.HTTPConfig.Prepare(&b.config.ctx)...)
 	errs = packer.MultiErrorAppend(errs, b.config.RunConfig.Prepare(&b.config.ctx)...)
+	if err := b.config.RunConfig.CheckBootCommand(b.config.BootCommand); err != nil {
+		errs = packer.MultiErrorAppend(errs, err)
+	}
 	errs = packer.MultiErrorAppend(errs, b.config.ShutdownConfig.Prepare(&b.config.ctx)...)
 	errs = packer.MultiErrorAppend(errs, b.config.SSHConfig.Prepare(&b.config.ctx)...)
 	errs = packer.MultiErrorAppend(errs, b.config.VBoxManageConfig.Prepare(&b.config.ctx)...)
@@ -185,13 +190,17 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 			Ctx:                  b.config.ctx,
 		},
 		&common.StepDownload{
-			Checksum:     b.config.ISOChecksum,
-			ChecksumType: b.config.ISOChecksumType,
-			Description:  "ISO",
-			Extension:    b.config.TargetExtension,
-			ResultKey:    "iso_path",
-			TargetPath:   b.config.TargetPath,
-			Url:          b.config.ISOUrls,
+			CAFile:             b.config.ISOCAFile,
+			Checksum:           b.config.ISOChecksum,
+			ChecksumType:       b.config.ISOChecksumType,
+			Description:        "ISO",
+			Extension:          b.config.TargetExtension,
+			InsecureSkipVerify: b.config.ISOInsecureSkipVerify,
+			LinkLocalFile:      b.config.ISOLocalMode == "symlink",
+			ProbeMirrors:       b.config.ISOProbeMirrors,
+			ResultKey:          "iso_path",
+			TargetPath:         b.config.TargetPath,
+			Url:                b.config.ISOUrls,
 		},
 		&vboxcommon.StepOutputDir{
 			Force: b.config.PackerForce,
@@ -200,9 +209,12 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 		&common.StepCreateFloppy{
 			Files:       b.config.FloppyConfig.FloppyFiles,
 			Directories: b.config.FloppyConfig.FloppyDirectories,
+			Label:       b.config.FloppyConfig.FloppyLabel,
+			Size:        b.config.FloppyConfig.FloppySize,
 		},
 		&common.StepHTTPServer{
 			HTTPDir:     b.config.HTTPDir,
+			HTTPAddress: b.config.HTTPAddress,
 			HTTPPortMin: b.config.HTTPPortMin,
 			HTTPPortMax: b.config.HTTPPortMax,
 		},
@@ -234,9 +246,10 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 			Headless: b.config.Headless,
 		},
 		&vboxcommon.StepTypeBootCommand{
-			BootCommand: b.config.BootCommand,
-			VMName:      b.config.VMName,
-			Ctx:         b.config.ctx,
+			BootCommand:    b.config.BootCommand,
+			KeyboardLayout: b.config.BootKeyboardLayout,
+			VMName:         b.config.VMName,
+			Ctx:            b.config.ctx,
 		},
 		&communicator.StepConnect{
 			Config:    &b.config.SSHConfig.Comm,
@@ -244,6 +257,8 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 			SSHConfig: vboxcommon.SSHConfigFunc(b.config.SSHConfig),
 			SSHPort:   vboxcommon.SSHPort,
 			WinRMPort: vboxcommon.SSHPort,
+			Debug:     b.config.PackerDebug,
+			DebugDir:  b.config.OutputDir,
 		},
 		&vboxcommon.StepUploadVersion{
 			Path: b.config.VBoxVersionFile,
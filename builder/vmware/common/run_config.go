@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/mitchellh/packer/common"
 	"github.com/mitchellh/packer/template/interpolate"
 )
 
@@ -11,6 +12,8 @@ type RunConfig struct {
 	Headless    bool   `mapstructure:"headless"`
 	RawBootWait string `mapstructure:"boot_wait"`
 
+	BootKeyboardLayout string `mapstructure:"boot_keyboard_layout"`
+
 	VNCBindAddress     string `mapstructure:"vnc_bind_address"`
 	VNCPortMin         uint   `mapstructure:"vnc_port_min"`
 	VNCPortMax         uint   `mapstructure:"vnc_port_max"`
@@ -51,5 +54,26 @@ func (c *RunConfig) Prepare(ctx *interpolate.Context) []error {
 			errs, fmt.Errorf("vnc_port_min must be less than vnc_port_max"))
 	}
 
+	if _, err := common.NewKeyboardLayout(c.BootKeyboardLayout); err != nil {
+		errs = append(
+			errs, fmt.Errorf("boot_keyboard_layout is invalid: %s", err))
+	}
+
 	return errs
 }
+
+// CheckBootCommand returns an error if the boot command can't be typed on
+// the boot_keyboard_layout.
+func (c *RunConfig) CheckBootCommand(command []string) error {
+	layout, err := common.NewKeyboardLayout(c.BootKeyboardLayout)
+	if err != nil {
+		// Reported by Prepare
+		return nil
+	}
+
+	if err := layout.CheckBootCommand(command, true); err != nil {
+		return fmt.Errorf("boot_command can't be typed: %s", err)
+	}
+
+	return nil
+}
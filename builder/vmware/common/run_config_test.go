@@ -34,3 +34,14 @@ func TestRunConfigPrepare(t *testing.T) {
 		t.Fatalf("bad: %#v", errs)
 	}
 }
+
+func TestRunConfigCheckBootCommand(t *testing.T) {
+	c := new(RunConfig)
+	c.BootKeyboardLayout = "de"
+	if err := c.CheckBootCommand([]string{"a/b@c<enter>"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := c.CheckBootCommand([]string{"a|b"}); err == nil {
+		t.Fatal("should have error")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 
 	"github.com/mitchellh/multistep"
+	"github.com/mitchellh/packer/common"
 	"github.com/mitchellh/packer/packer"
 )
 
@@ -25,6 +26,8 @@ type StepConfigureVNC struct {
 	VNCPortMin         uint
 	VNCPortMax         uint
 	VNCDisablePassword bool
+
+	l net.Listener
 }
 
 type VNCAddressFinder interface {
@@ -34,26 +37,15 @@ type VNCAddressFinder interface {
 	UpdateVMX(vncAddress, vncPassword string, vncPort uint, vmxData map[string]string)
 }
 
-func (StepConfigureVNC) VNCAddress(vncBindAddress string, portMin, portMax uint) (string, uint, error) {
-	// Find an open VNC port. Note that this can still fail later on
-	// because we have to release the port at some point. But this does its
-	// best.
-	var vncPort uint
-	portRange := int(portMax - portMin)
-	for {
-		if portRange > 0 {
-			vncPort = uint(rand.Intn(portRange)) + portMin
-		} else {
-			vncPort = portMin
-		}
-
-		log.Printf("Trying port: %d", vncPort)
-		l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", vncBindAddress, vncPort))
-		if err == nil {
-			defer l.Close()
-			break
-		}
+func (s *StepConfigureVNC) VNCAddress(vncBindAddress string, portMin, portMax uint) (string, uint, error) {
+	// Find an open VNC port. The listener holds the port until StepRun
+	// starts the VM.
+	l, vncPort, err := common.ListenRange(vncBindAddress, portMin, portMax)
+	if err != nil {
+		return "", 0, err
 	}
+	s.l = l
+
 	return vncBindAddress, vncPort, nil
 }
 
@@ -127,6 +119,9 @@ func (s *StepConfigureVNC) Run(state multistep.StateBag) multistep.StepAction {
 	state.Put("vnc_port", vncPort)
 	state.Put("vnc_ip", vncBindAddress)
 	state.Put("vnc_password", vncPassword)
+	if s.l != nil {
+		state.Put("vnc_port_listener", s.l)
+	}
 
 	return multistep.ActionContinue
 }
@@ -140,5 +135,8 @@ func (StepConfigureVNC) UpdateVMX(address, password string, port uint, data map[
 	}
 }
 
-func (StepConfigureVNC) Cleanup(multistep.StateBag) {
+func (s *StepConfigureVNC) Cleanup(multistep.StateBag) {
+	if s.l != nil {
+		s.l.Close()
+	}
 }
@@ -2,13 +2,38 @@ package common
 
 import (
 	"fmt"
+	"net"
 	"testing"
+
+	"github.com/mitchellh/multistep"
 )
 
 func TestStepConfigureVNC_implVNCAddressFinder(t *testing.T) {
 	var _ VNCAddressFinder = new(StepConfigureVNC)
 }
 
+func TestStepConfigureVNC_VNCAddress(t *testing.T) {
+	var s StepConfigureVNC
+	ip, port, err := s.VNCAddress("127.0.0.1", 5900, 6000)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The port stays reserved until the step is cleaned up
+	addr := fmt.Sprintf("%s:%d", ip, port)
+	if l, err := net.Listen("tcp", addr); err == nil {
+		l.Close()
+		t.Fatalf("port %d should be in use", port)
+	}
+
+	s.Cleanup(new(multistep.BasicStateBag))
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("port %d should be free: %s", port, err)
+	}
+	l.Close()
+}
+
 func TestStepConfigureVNC_UpdateVMX(t *testing.T) {
 	var s StepConfigureVNC
 	data := make(map[string]string)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/mitchellh/multistep"
 	"github.com/mitchellh/packer/packer"
+	"net"
 	"time"
 )
 
@@ -56,6 +57,11 @@ func (s *StepRun) Run(state multistep.StateBag) multistep.StepAction {
 		}
 	}
 
+	// Release the VNC port that was held for the VM right before starting it
+	if l, ok := state.GetOk("vnc_port_listener"); ok {
+		l.(net.Listener).Close()
+	}
+
 	if err := driver.Start(vmxPath, s.Headless); err != nil {
 		err := fmt.Errorf("Error starting VM: %s", err)
 		state.Put("error", err)
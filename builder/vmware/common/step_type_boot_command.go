@@ -8,7 +8,6 @@ import (
 	"runtime"
 	"strings"
 	"time"
-	"unicode"
 	"unicode/utf8"
 
 	"github.com/mitchellh/go-vnc"
@@ -20,6 +19,9 @@ import (
 
 const KeyLeftShift uint32 = 0xFFE1
 
+// KeyRightAlt is AltGr on keyboard layouts that have it.
+const KeyRightAlt uint32 = 0xFFEA
+
 type bootCommandTemplateData struct {
 	HTTPIP   string
 	HTTPPort uint
@@ -36,9 +38,10 @@ type bootCommandTemplateData struct {
 // Produces:
 //   <nothing>
 type StepTypeBootCommand struct {
-	BootCommand []string
-	VMName      string
-	Ctx         interpolate.Context
+	BootCommand    []string
+	KeyboardLayout string
+	VMName         string
+	Ctx            interpolate.Context
 }
 
 func (s *StepTypeBootCommand) Run(state multistep.StateBag) multistep.StepAction {
@@ -112,6 +115,13 @@ func (s *StepTypeBootCommand) Run(state multistep.StateBag) multistep.StepAction
 		s.VMName,
 	}
 
+	layout, err := common.NewKeyboardLayout(s.KeyboardLayout)
+	if err != nil {
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
 	ui.Say("Typing the boot command over VNC...")
 	for i, command := range s.BootCommand {
 		command, err := interpolate.Render(command, &s.Ctx)
@@ -132,7 +142,7 @@ func (s *StepTypeBootCommand) Run(state multistep.StateBag) multistep.StepAction
 			pauseFn(multistep.DebugLocationAfterRun, fmt.Sprintf("boot_command[%d]: %s", i, command), state)
 		}
 
-		vncSendString(c, command)
+		vncSendString(c, command, layout)
 	}
 
 	return multistep.ActionContinue
@@ -140,7 +150,7 @@ func (s *StepTypeBootCommand) Run(state multistep.StateBag) multistep.StepAction
 
 func (*StepTypeBootCommand) Cleanup(multistep.StateBag) {}
 
-func vncSendString(c *vnc.ClientConn, original string) {
+func vncSendString(c *vnc.ClientConn, original string, layout common.KeyboardLayout) {
 	// Scancodes reference: https://github.com/qemu/qemu/blob/master/ui/vnc_keysym.h
 	special := make(map[string]uint32)
 	special["<bs>"] = 0xFF08
@@ -178,7 +188,6 @@ func vncSendString(c *vnc.ClientConn, original string) {
 	special["<rightCtrl>"] = 0xFFE4
 	special["<rightShift>"] = 0xFFE2
 
-	shiftedChars := "~!@#$%^&*()_+{}|:\"<>?"
 
 	// We delay (default 100ms) between each key event to allow for CPU or
 	// network latency. See PackerKeyEnv for tuning.
@@ -191,6 +200,8 @@ func vncSendString(c *vnc.ClientConn, original string) {
 	for len(original) > 0 {
 		var keyCode uint32
 		keyShift := false
+		keyAltGr := false
+		keyDead := false
 
 		if strings.HasPrefix(original, "<leftAltOn>") {
 			keyCode = special["<leftAlt>"]
@@ -357,23 +368,42 @@ func vncSendString(c *vnc.ClientConn, original string) {
 		if keyCode == 0 {
 			r, size := utf8.DecodeRuneInString(original)
 			original = original[size:]
-			keyCode = uint32(r)
-			keyShift = unicode.IsUpper(r) || strings.ContainsRune(shiftedChars, r)
 
-			log.Printf("Sending char '%c', code %d, shift %v", r, keyCode, keyShift)
+			// Type the key that produces the character on the keyboard
+			// layout of the guest.
+			stroke := layout.Stroke(r)
+			keyCode = uint32(stroke.Key)
+			keyShift = stroke.Shift
+			keyAltGr = stroke.AltGr
+			keyDead = stroke.Dead
+
+			log.Printf("Sending char '%c', code %d, shift %v, altgr %v", r, keyCode, keyShift, keyAltGr)
 		}
 
 		if keyShift {
 			c.KeyEvent(KeyLeftShift, true)
 		}
+		if keyAltGr {
+			c.KeyEvent(KeyRightAlt, true)
+		}
 
 		c.KeyEvent(keyCode, true)
 		time.Sleep(keyInterval)
 		c.KeyEvent(keyCode, false)
 		time.Sleep(keyInterval)
 
+		if keyAltGr {
+			c.KeyEvent(KeyRightAlt, false)
+		}
 		if keyShift {
 			c.KeyEvent(KeyLeftShift, false)
 		}
+
+		// Dead keys only produce their character once followed by a space.
+		if keyDead {
+			c.KeyEvent(special["<spacebar>"], true)
+			time.Sleep(keyInterval)
+			c.KeyEvent(special["<spacebar>"], false)
+		}
 	}
 }
@@ -68,6 +68,7 @@ type Config struct {
 }
 
 func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
+	var unknownKeys []string
 	err := config.Decode(&b.config, &config.DecodeOpts{
 		Interpolate:        true,
 		InterpolateContext: &b.config.ctx,
@@ -77,6 +78,7 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 				"tools_upload_path",
 			},
 		},
+		UnknownKeys: &unknownKeys,
 	}, raws...)
 	if err != nil {
 		return nil, err
@@ -84,7 +86,7 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 
 	// Accumulate any errors and warnings
 	var errs *packer.MultiError
-	warnings := make([]string, 0)
+	warnings := config.UnknownKeyWarnings(unknownKeys)
 
 	isoWarnings, isoErrs := b.config.ISOConfig.Prepare(&b.config.ctx)
 	warnings = append(warnings, isoWarnings...)
@@ -94,6 +96,9 @@ func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
 	errs = packer.MultiErrorAppend(errs,
 		b.config.OutputConfig.Prepare(&b.config.ctx, &b.config.PackerConfig)...)
 	errs = packer.MultiErrorAppend(errs, b.config.RunConfig.Prepare(&b.config.ctx)...)
+	if err := b.config.RunConfig.CheckBootCommand(b.config.BootCommand); err != nil {
+		errs = packer.MultiErrorAppend(errs, err)
+	}
 	errs = packer.MultiErrorAppend(errs, b.config.ShutdownConfig.Prepare(&b.config.ctx)...)
 	errs = packer.MultiErrorAppend(errs, b.config.SSHConfig.Prepare(&b.config.ctx)...)
 	errs = packer.MultiErrorAppend(errs, b.config.ToolsConfig.Prepare(&b.config.ctx)...)
@@ -220,13 +225,17 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 			ToolsUploadFlavor: b.config.ToolsUploadFlavor,
 		},
 		&common.StepDownload{
-			Checksum:     b.config.ISOChecksum,
-			ChecksumType: b.config.ISOChecksumType,
-			Description:  "ISO",
-			Extension:    b.config.TargetExtension,
-			ResultKey:    "iso_path",
-			TargetPath:   b.config.TargetPath,
-			Url:          b.config.ISOUrls,
+			CAFile:             b.config.ISOCAFile,
+			Checksum:           b.config.ISOChecksum,
+			ChecksumType:       b.config.ISOChecksumType,
+			Description:        "ISO",
+			Extension:          b.config.TargetExtension,
+			InsecureSkipVerify: b.config.ISOInsecureSkipVerify,
+			LinkLocalFile:      b.config.ISOLocalMode == "symlink",
+			ProbeMirrors:       b.config.ISOProbeMirrors,
+			ResultKey:          "iso_path",
+			TargetPath:         b.config.TargetPath,
+			Url:                b.config.ISOUrls,
 		},
 		&vmwcommon.StepOutputDir{
 			Force: b.config.PackerForce,
@@ -234,6 +243,8 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 		&common.StepCreateFloppy{
 			Files:       b.config.FloppyConfig.FloppyFiles,
 			Directories: b.config.FloppyConfig.FloppyDirectories,
+			Label:       b.config.FloppyConfig.FloppyLabel,
+			Size:        b.config.FloppyConfig.FloppySize,
 		},
 		&stepRemoteUpload{
 			Key:     "floppy_path",
@@ -251,6 +262,7 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 		&vmwcommon.StepSuppressMessages{},
 		&common.StepHTTPServer{
 			HTTPDir:     b.config.HTTPDir,
+			HTTPAddress: b.config.HTTPAddress,
 			HTTPPortMin: b.config.HTTPPortMin,
 			HTTPPortMax: b.config.HTTPPortMax,
 		},
@@ -269,14 +281,17 @@ func (b *Builder) Run(ui packer.Ui, hook packer.Hook, cache packer.Cache) (packe
 			Headless:           b.config.Headless,
 		},
 		&vmwcommon.StepTypeBootCommand{
-			BootCommand: b.config.BootCommand,
-			VMName:      b.config.VMName,
-			Ctx:         b.config.ctx,
+			BootCommand:    b.config.BootCommand,
+			KeyboardLayout: b.config.BootKeyboardLayout,
+			VMName:         b.config.VMName,
+			Ctx:            b.config.ctx,
 		},
 		&communicator.StepConnect{
 			Config:    &b.config.SSHConfig.Comm,
 			Host:      driver.CommHost,
 			SSHConfig: vmwcommon.SSHConfigFunc(&b.config.SSHConfig),
+			Debug:     b.config.PackerDebug && b.config.RemoteType == "",
+			DebugDir:  b.config.OutputDir,
 		},
 		&vmwcommon.StepUploadTools{
 			RemoteType:        b.config.RemoteType,
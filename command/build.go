@@ -21,7 +21,8 @@ type BuildCommand struct {
 
 func (c BuildCommand) Run(args []string) int {
 	var cfgColor, cfgDebug, cfgForce, cfgParallel bool
-	var cfgOnError string
+	var cfgOnError, cfgStateFile string
+	var cfgParallelBuilds int
 	flags := c.Meta.FlagSet("build", FlagSetBuildFilter|FlagSetVars)
 	flags.Usage = func() { c.Ui.Say(c.Help()) }
 	flags.BoolVar(&cfgColor, "color", true, "")
@@ -30,6 +31,9 @@ func (c BuildCommand) Run(args []string) int {
 	flagOnError := enumflag.New(&cfgOnError, "cleanup", "abort", "ask")
 	flags.Var(flagOnError, "on-error", "")
 	flags.BoolVar(&cfgParallel, "parallel", true, "")
+	flags.IntVar(&cfgParallelBuilds, "parallel-builds", 0, "")
+	flags.IntVar(&c.Meta.postProcessorConcurrency, "parallel-post-processors", 0, "")
+	flags.StringVar(&cfgStateFile, "state-file", "", "")
 	if err := flags.Parse(args); err != nil {
 		return 1
 	}
@@ -104,6 +108,46 @@ func (c BuildCommand) Run(args []string) int {
 	log.Printf("Build debug mode: %v", cfgDebug)
 	log.Printf("Force build: %v", cfgForce)
 	log.Printf("On error: %v", cfgOnError)
+	log.Printf("Parallel builds: %d", cfgParallelBuilds)
+	log.Printf("State file: %s", cfgStateFile)
+
+	// With a state file, builds whose inputs are unchanged since their
+	// last successful run are skipped and report their previous artifacts.
+	var state *buildState
+	fingerprints := make(map[string]string)
+	skipped := make(map[string][]packer.Artifact)
+	if cfgStateFile != "" {
+		state, err = readBuildState(cfgStateFile)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to read state file: %s", err))
+			return 1
+		}
+
+		remaining := make([]packer.Build, 0, len(builds))
+		for _, b := range builds {
+			fingerprint, err := buildFingerprint(tpl, b.Name(), core.Context())
+			if err != nil {
+				c.Ui.Error(fmt.Sprintf(
+					"Failed to fingerprint build '%s': %s", b.Name(), err))
+				return 1
+			}
+			fingerprints[b.Name()] = fingerprint
+
+			if prior, ok := state.artifacts(b.Name(), fingerprint); ok && !cfgForce {
+				ui := buildUis[b.Name()]
+				ui.Say(fmt.Sprintf(
+					"Inputs of build '%s' are unchanged since its last successful run, skipping.",
+					b.Name()))
+				machineUi := &packer.TargettedUi{Target: b.Name(), Ui: c.Ui}
+				machineUi.Machine("build-skipped")
+				skipped[b.Name()] = prior
+				continue
+			}
+
+			remaining = append(remaining, b)
+		}
+		builds = remaining
+	}
 
 	// Set the debug and force mode and prepare all the builds
 	for _, b := range builds {
@@ -135,7 +179,25 @@ func (c BuildCommand) Run(args []string) int {
 		m map[string][]packer.Artifact
 	}{m: make(map[string][]packer.Artifact)}
 	errors := make(map[string]error)
+
+	// buildSem limits the number of builds running at the same time
+	// if -parallel-builds is set.
+	var buildSem chan struct{}
+	if cfgParallelBuilds > 0 {
+		buildSem = make(chan struct{}, cfgParallelBuilds)
+	}
+
 	for _, b := range builds {
+		if buildSem != nil {
+			log.Printf("Waiting for a free build slot: %s", b.Name())
+			buildSem <- struct{}{}
+		}
+
+		if interrupted {
+			log.Println("Interrupted, not going to start any more builds.")
+			break
+		}
+
 		// Increment the waitgroup so we wait for this item to finish properly
 		wg.Add(1)
 
@@ -157,6 +219,9 @@ func (c BuildCommand) Run(args []string) int {
 		// Run the build in a goroutine
 		go func(b packer.Build) {
 			defer wg.Done()
+			if buildSem != nil {
+				defer func() { <-buildSem }()
+			}
 
 			name := b.Name()
 			log.Printf("Starting build run: %s", name)
@@ -203,6 +268,22 @@ func (c BuildCommand) Run(args []string) int {
 		return 1
 	}
 
+	if state != nil {
+		for name, buildArtifacts := range artifacts.m {
+			state.set(name, fingerprints[name], buildArtifacts)
+		}
+		for name := range errors {
+			delete(state.Builds, name)
+		}
+		if err := state.write(cfgStateFile); err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to write state file: %s", err))
+		}
+
+		for name, prior := range skipped {
+			artifacts.m[name] = prior
+		}
+	}
+
 	if len(errors) > 0 {
 		c.Ui.Machine("error-count", strconv.FormatInt(int64(len(errors)), 10))
 
@@ -290,9 +371,12 @@ Options:
   -except=foo,bar,baz        Build all builds other than these
   -only=foo,bar,baz          Build only the specified builds
   -force                     Force a build to continue if artifacts exist, deletes existing artifacts
-  -machine-readable          Machine-readable output
+  -machine-readable[=json]   Machine-readable output
   -on-error=[cleanup|abort|ask] If the build fails do: clean up (default), abort, or ask
   -parallel=false            Disable parallelization (on by default)
+  -parallel-builds=N         Run at most N builds at the same time
+  -parallel-post-processors=N Run at most N post-processors at the same time
+  -state-file=path           Skip builds whose inputs are unchanged since the last run
   -var 'key=value'           Variable for templates, can be used multiple times.
   -var-file=path             JSON file containing user variables.
 `
@@ -0,0 +1,319 @@
+package command
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/packer/packer"
+	"github.com/mitchellh/packer/template"
+	"github.com/mitchellh/packer/template/interpolate"
+)
+
+// buildStateInputKeys are the configuration keys of builders,
+// provisioners and post-processors that name local files or directories
+// the build reads. Only these are part of the fingerprint, other keys may
+// name remote paths or outputs of the build.
+var buildStateInputKeys = map[string]bool{
+	"config_template":                true,
+	"cookbook_paths":                 true,
+	"custom_data_file":               true,
+	"data_bags_path":                 true,
+	"encrypted_data_bag_secret_path": true,
+	"environments_path":              true,
+	"floppy_dirs":                    true,
+	"floppy_files":                   true,
+	"galaxy_file":                    true,
+	"hiera_config_path":              true,
+	"http_directory":                 true,
+	"include":                        true,
+	"inventory_file":                 true,
+	"iso_checksum_url":               true,
+	"iso_url":                        true,
+	"iso_urls":                       true,
+	"local_pillar_roots":             true,
+	"local_state_tree":               true,
+	"manifest_dir":                   true,
+	"manifest_file":                  true,
+	"minion_config":                  true,
+	"module_paths":                   true,
+	"playbook_dir":                   true,
+	"playbook_file":                  true,
+	"role_paths":                     true,
+	"script":                         true,
+	"scripts":                        true,
+	"source":                         true,
+	"source_path":                    true,
+	"startup_script_file":            true,
+	"user_data_file":                 true,
+	"vagrantfile_template":           true,
+	"vmx_disk_template_path":         true,
+	"vmx_template_path":              true,
+}
+
+// buildState is the state file written with -state-file. It records a
+// fingerprint of the inputs of every successful build along with the
+// artifacts it created, so that builds whose inputs are unchanged can be
+// skipped.
+type buildState struct {
+	Builds map[string]*buildStateEntry `json:"builds"`
+}
+
+type buildStateEntry struct {
+	Fingerprint string           `json:"fingerprint"`
+	Artifacts   []*stateArtifact `json:"artifacts"`
+}
+
+// readBuildState reads the state file at path. A missing file is an
+// empty state.
+func readBuildState(path string) (*buildState, error) {
+	state := &buildState{Builds: make(map[string]*buildStateEntry)}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("Error parsing state file %s: %s", path, err)
+	}
+	if state.Builds == nil {
+		state.Builds = make(map[string]*buildStateEntry)
+	}
+
+	return state, nil
+}
+
+// write writes the state to the file at path.
+func (s *buildState) write(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// set records the artifacts of a successful build.
+func (s *buildState) set(name, fingerprint string, artifacts []packer.Artifact) {
+	entry := &buildStateEntry{Fingerprint: fingerprint}
+	for _, a := range artifacts {
+		if a == nil {
+			continue
+		}
+
+		entry.Artifacts = append(entry.Artifacts, &stateArtifact{
+			BuilderIdValue: a.BuilderId(),
+			IdValue:        a.Id(),
+			StringValue:    a.String(),
+			FilesValue:     a.Files(),
+		})
+	}
+
+	s.Builds[name] = entry
+}
+
+// artifacts returns the artifacts recorded for the build if its
+// fingerprint is unchanged and the files of the artifacts still exist.
+func (s *buildState) artifacts(name, fingerprint string) ([]packer.Artifact, bool) {
+	entry, ok := s.Builds[name]
+	if !ok || entry.Fingerprint != fingerprint {
+		return nil, false
+	}
+	for _, a := range entry.Artifacts {
+		for _, f := range a.FilesValue {
+			if _, err := os.Stat(f); err != nil {
+				log.Printf("Artifact file %s of build %s is gone: %s", f, name, err)
+				return nil, false
+			}
+		}
+	}
+
+	result := make([]packer.Artifact, len(entry.Artifacts))
+	for i, a := range entry.Artifacts {
+		result[i] = a
+	}
+
+	return result, true
+}
+
+// stateArtifact is an artifact recorded in the state file. It is only used
+// to report the artifacts of skipped builds.
+type stateArtifact struct {
+	BuilderIdValue string   `json:"builder_id"`
+	IdValue        string   `json:"id"`
+	StringValue    string   `json:"string"`
+	FilesValue     []string `json:"files"`
+}
+
+func (a *stateArtifact) BuilderId() string {
+	return a.BuilderIdValue
+}
+
+func (a *stateArtifact) Files() []string {
+	return a.FilesValue
+}
+
+func (a *stateArtifact) Id() string {
+	return a.IdValue
+}
+
+func (a *stateArtifact) String() string {
+	return a.StringValue
+}
+
+func (a *stateArtifact) State(name string) interface{} {
+	return nil
+}
+
+func (a *stateArtifact) Destroy() error {
+	return fmt.Errorf("artifacts recorded in the state file can't be destroyed")
+}
+
+// buildFingerprint returns a checksum of everything that goes into the
+// named build: the configuration of its builder, provisioners and
+// post-processors, the user variables and the contents of the local files
+// and directories named by the input keys of the configuration, such as
+// ISOs, http_directory and provisioner scripts.
+func buildFingerprint(tpl *template.Template, name string, ctx *interpolate.Context) (string, error) {
+	b, ok := tpl.Builders[name]
+	if !ok {
+		return "", fmt.Errorf("no such build found: %s", name)
+	}
+
+	inputs := map[string]interface{}{
+		"builder":   b,
+		"variables": ctx.UserVariables,
+	}
+
+	var provisioners []*template.Provisioner
+	for _, p := range tpl.Provisioners {
+		if !p.Skip(name) {
+			provisioners = append(provisioners, p)
+		}
+	}
+	inputs["provisioners"] = provisioners
+
+	var postProcessors [][]*template.PostProcessor
+	for _, ps := range tpl.PostProcessors {
+		var seq []*template.PostProcessor
+		for _, p := range ps {
+			if !p.Skip(name) {
+				seq = append(seq, p)
+			}
+		}
+		if len(seq) > 0 {
+			postProcessors = append(postProcessors, seq)
+		}
+	}
+	inputs["post-processors"] = postProcessors
+
+	raw, err := json.Marshal(inputs)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write(raw)
+
+	paths := make(map[string]bool)
+	collectPaths(b.Config, ctx, paths)
+	for _, p := range provisioners {
+		collectPaths(p.Config, ctx, paths)
+		collectPaths(p.Override[name], ctx, paths)
+	}
+	for _, ps := range postProcessors {
+		for _, p := range ps {
+			collectPaths(p.Config, ctx, paths)
+		}
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	for _, p := range sorted {
+		if err := hashPath(h, p); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// collectPaths adds the local files and directories named by the input
+// keys of the configuration v to paths.
+func collectPaths(v interface{}, ctx *interpolate.Context, paths map[string]bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if buildStateInputKeys[k] {
+				collectInputPaths(e, ctx, paths)
+			} else {
+				collectPaths(e, ctx, paths)
+			}
+		}
+	case []interface{}:
+		for _, e := range v {
+			collectPaths(e, ctx, paths)
+		}
+	}
+}
+
+// collectInputPaths adds every string in the value v of an input key that
+// names an existing local file or directory to paths.
+func collectInputPaths(v interface{}, ctx *interpolate.Context, paths map[string]bool) {
+	switch v := v.(type) {
+	case []interface{}:
+		for _, e := range v {
+			collectInputPaths(e, ctx, paths)
+		}
+	case string:
+		if rendered, err := interpolate.Render(v, ctx); err == nil {
+			v = rendered
+		}
+		v = strings.TrimPrefix(v, "file://")
+		if v == "" {
+			return
+		}
+		if _, err := os.Stat(v); err == nil {
+			paths[v] = true
+		}
+	}
+}
+
+// hashPath writes the name and the contents of the file at path, or of
+// every file below the directory at path, to h.
+func hashPath(h io.Writer, path string) error {
+	return filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		fmt.Fprintf(h, "%s\x00", p)
+		_, err = io.Copy(h, f)
+		return err
+	})
+}
@@ -0,0 +1,200 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/packer/packer"
+	"github.com/mitchellh/packer/template"
+	"github.com/mitchellh/packer/template/interpolate"
+)
+
+func TestBuildStateFileFlag(t *testing.T) {
+	c := &BuildCommand{
+		Meta: testMetaFile(t),
+	}
+
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+	stateFile := filepath.Join(td, "state.json")
+
+	args := []string{
+		"-state-file=" + stateFile,
+		filepath.Join(testFixture("build-only"), "template.json"),
+	}
+
+	defer cleanup()
+
+	if code := c.Run(args); code != 0 {
+		fatalCommand(t, c.Meta)
+	}
+
+	state, err := readBuildState(stateFile)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(state.Builds) != 3 {
+		t.Fatalf("bad: %#v", state.Builds)
+	}
+
+	// Nothing changed, so the builds should be skipped
+	if err := ioutil.WriteFile("chocolate.txt", []byte("stale"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if code := c.Run(args); code != 0 {
+		fatalCommand(t, c.Meta)
+	}
+	if content := fileContents(t, "chocolate.txt"); content != "stale" {
+		t.Errorf("bad: %q", content)
+	}
+
+	// Builds whose artifacts are gone run again
+	os.Remove("vanilla.txt")
+	if code := c.Run(args); code != 0 {
+		fatalCommand(t, c.Meta)
+	}
+	if !fileExists("vanilla.txt") {
+		t.Error("Expected to find vanilla.txt")
+	}
+
+	// -force runs the builds anyway
+	args = append([]string{"-force"}, args...)
+	if code := c.Run(args); code != 0 {
+		fatalCommand(t, c.Meta)
+	}
+	if content := fileContents(t, "chocolate.txt"); content != "chocolate" {
+		t.Errorf("bad: %q", content)
+	}
+}
+
+func fileContents(t *testing.T, path string) string {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return string(contents)
+}
+
+func TestBuildFingerprint(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	fixture := filepath.Join(testFixture("build-state"), "template.json")
+	tpl, err := template.ParseFile(fixture)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	script := filepath.Join(td, "setup.sh")
+	if err := ioutil.WriteFile(script, []byte("echo foo"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	tpl.Provisioners[0].Config["script"] = script
+
+	ctx := &interpolate.Context{
+		TemplatePath:  fixture,
+		UserVariables: map[string]string{"foo": "bar"},
+	}
+
+	fingerprint := func() string {
+		result, err := buildFingerprint(tpl, "chocolate", ctx)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		return result
+	}
+
+	// The provisioner doesn't apply to the build, so its script
+	// doesn't matter
+	expected := fingerprint()
+	if err := ioutil.WriteFile(script, []byte("echo bar"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual := fingerprint(); actual != expected {
+		t.Fatalf("bad: %s != %s", actual, expected)
+	}
+
+	// Once it applies, changing the script changes the fingerprint
+	tpl.Provisioners[0].Only = nil
+	expected = fingerprint()
+	if err := ioutil.WriteFile(script, []byte("echo baz"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual := fingerprint(); actual == expected {
+		t.Fatal("fingerprint should change with the script")
+	}
+
+	// So does changing a user variable
+	expected = fingerprint()
+	ctx.UserVariables["foo"] = "baz"
+	if actual := fingerprint(); actual == expected {
+		t.Fatal("fingerprint should change with the variables")
+	}
+
+	// The outputs of the build are ignored
+	target := filepath.Join(td, "chocolate.txt")
+	tpl.Builders["chocolate"].Config["target"] = target
+	if err := ioutil.WriteFile(target, []byte("foo"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected = fingerprint()
+	if err := ioutil.WriteFile(target, []byte("bar"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual := fingerprint(); actual != expected {
+		t.Fatalf("bad: %s != %s", actual, expected)
+	}
+
+	// So are remote paths of provisioners
+	tpl.Provisioners[0].Config["destination"] = td
+	expected = fingerprint()
+	if err := ioutil.WriteFile(filepath.Join(td, "remote"), []byte("foo"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual := fingerprint(); actual != expected {
+		t.Fatalf("bad: %s != %s", actual, expected)
+	}
+}
+
+func TestBuildState_artifacts(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	path := filepath.Join(td, "disk.img")
+	if err := ioutil.WriteFile(path, []byte("foo"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state := &buildState{Builds: make(map[string]*buildStateEntry)}
+	state.set("chocolate", "abc", []packer.Artifact{
+		&packer.MockArtifact{FilesValue: []string{path}},
+	})
+
+	if _, ok := state.artifacts("chocolate", "def"); ok {
+		t.Fatal("should not have artifacts with a different fingerprint")
+	}
+	artifacts, ok := state.artifacts("chocolate", "abc")
+	if !ok || len(artifacts) != 1 {
+		t.Fatalf("bad: %#v", artifacts)
+	}
+
+	// The build has to run again once its artifacts are gone
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, ok := state.artifacts("chocolate", "abc"); ok {
+		t.Fatal("should not have artifacts with missing files")
+	}
+}
@@ -122,6 +122,83 @@ func TestBuildExceptFileCommaFlags(t *testing.T) {
 	}
 }
 
+func TestBuildOnlyFileGlobFlags(t *testing.T) {
+	c := &BuildCommand{
+		Meta: testMetaFile(t),
+	}
+
+	args := []string{
+		"-only=ch*,nothing-*",
+		filepath.Join(testFixture("build-only"), "template.json"),
+	}
+
+	defer cleanup()
+
+	if code := c.Run(args); code != 0 {
+		fatalCommand(t, c.Meta)
+	}
+
+	if !fileExists("chocolate.txt") {
+		t.Error("Expected to find chocolate.txt")
+	}
+	if fileExists("vanilla.txt") {
+		t.Error("Expected NOT to find vanilla.txt")
+	}
+	if !fileExists("cherry.txt") {
+		t.Error("Expected to find cherry.txt")
+	}
+}
+
+func TestBuildExceptFileGlobFlags(t *testing.T) {
+	c := &BuildCommand{
+		Meta: testMetaFile(t),
+	}
+
+	args := []string{
+		"-except=c?e*,[v]anilla",
+		filepath.Join(testFixture("build-only"), "template.json"),
+	}
+
+	defer cleanup()
+
+	if code := c.Run(args); code != 0 {
+		fatalCommand(t, c.Meta)
+	}
+
+	if !fileExists("chocolate.txt") {
+		t.Error("Expected to find chocolate.txt")
+	}
+	if fileExists("vanilla.txt") {
+		t.Error("Expected NOT to find vanilla.txt")
+	}
+	if fileExists("cherry.txt") {
+		t.Error("Expected NOT to find cherry.txt")
+	}
+}
+
+func TestBuildParallelBuildsFlag(t *testing.T) {
+	c := &BuildCommand{
+		Meta: testMetaFile(t),
+	}
+
+	args := []string{
+		"-parallel-builds=1",
+		filepath.Join(testFixture("build-only"), "template.json"),
+	}
+
+	defer cleanup()
+
+	if code := c.Run(args); code != 0 {
+		fatalCommand(t, c.Meta)
+	}
+
+	for _, f := range []string{"chocolate.txt", "vanilla.txt", "cherry.txt"} {
+		if !fileExists(f) {
+			t.Errorf("Expected to find %s", f)
+		}
+	}
+}
+
 // fileExists returns true if the filename is found
 func fileExists(filename string) bool {
 	if _, err := os.Stat(filename); err == nil {
@@ -0,0 +1,127 @@
+package command
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mitchellh/packer/helper/flag-kv"
+	"github.com/mitchellh/packer/template"
+	"github.com/mitchellh/packer/template/interpolate"
+)
+
+type ConsoleCommand struct {
+	Meta
+
+	// Stdin is where expressions are read from. It defaults to os.Stdin.
+	Stdin io.Reader
+}
+
+func (c *ConsoleCommand) Run(args []string) int {
+	var data map[string]string
+	flags := c.Meta.FlagSet("console", FlagSetVars)
+	flags.Usage = func() { c.Ui.Say(c.Help()) }
+	flags.Var((*kvflag.Flag)(&data), "data", "")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) > 1 {
+		flags.Usage()
+		return 1
+	}
+
+	// Without a template we only know about the variables given on
+	// the command line.
+	ctx := &interpolate.Context{
+		UserVariables: c.Meta.variables(&template.Template{}),
+	}
+	if len(args) == 1 {
+		tpl, err := template.ParseFile(args[0])
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to parse template: %s", err))
+			return 1
+		}
+
+		core, err := c.Meta.Core(tpl)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+
+		ctx = core.Context()
+	}
+	if data != nil {
+		ctx.Data = data
+	}
+
+	stdin := c.Stdin
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+
+	scanner := bufio.NewScanner(stdin)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if line == "exit" {
+			break
+		}
+
+		result, err := consoleEval(line, ctx)
+		if err != nil {
+			c.Ui.Error(err.Error())
+			continue
+		}
+
+		c.Ui.Say(result)
+	}
+	if err := scanner.Err(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading input: %s", err))
+		return 1
+	}
+
+	return 0
+}
+
+// consoleEval renders a single console expression. Expressions without
+// any template actions are treated as the body of one, so that
+// `user "foo"` is the same as `{{user "foo"}}`.
+func consoleEval(expr string, ctx *interpolate.Context) (string, error) {
+	if !strings.Contains(expr, "{{") {
+		expr = "{{" + expr + "}}"
+	}
+
+	return interpolate.Render(expr, ctx)
+}
+
+func (*ConsoleCommand) Help() string {
+	helpText := `
+Usage: packer console [options] [TEMPLATE]
+
+  Reads configuration template expressions from standard input, one per
+  line, and prints the result of interpolating each of them. This can be
+  used to debug the templating of a template without running a build.
+
+  When a template is given, its user variables are available to the
+  expressions. Type "exit" or send EOF to leave the console.
+
+Options:
+
+  -data 'key=value'      Template data, such as HTTPIP for boot commands.
+                         Can be used multiple times.
+  -var 'key=value'       Variable for templates, can be used multiple times.
+  -var-file=path         JSON file containing user variables.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (*ConsoleCommand) Synopsis() string {
+	return "evaluate template expressions"
+}
@@ -0,0 +1,37 @@
+package command
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestConsoleCommand_implements(t *testing.T) {
+	var _ cli.Command = &ConsoleCommand{}
+}
+
+func TestConsoleCommand(t *testing.T) {
+	c := &ConsoleCommand{
+		Meta:  testMeta(t),
+		Stdin: strings.NewReader("user \"foo\"\n\n{{ .HTTPIP }}:{{ user `bar` }}\nfoo(\nexit\nuser \"foo\"\n"),
+	}
+
+	args := []string{
+		"-var=bar=baz",
+		"-data=HTTPIP=10.0.2.2",
+		filepath.Join(testFixture("console"), "template.json"),
+	}
+	if code := c.Run(args); code != 0 {
+		fatalCommand(t, c.Meta)
+	}
+
+	stdout, stderr := outputCommand(t, c.Meta)
+	if stdout != "bar\n10.0.2.2:baz\n" {
+		t.Fatalf("bad stdout: %q", stdout)
+	}
+	if stderr == "" {
+		t.Fatal("expected an error for the invalid expression")
+	}
+}
@@ -5,9 +5,78 @@ import (
 	"sort"
 	"strings"
 
+	amazonchrootbuilder "github.com/mitchellh/packer/builder/amazon/chroot"
+	amazonebsbuilder "github.com/mitchellh/packer/builder/amazon/ebs"
+	amazonebssurrogatebuilder "github.com/mitchellh/packer/builder/amazon/ebssurrogate"
+	amazonebsvolumebuilder "github.com/mitchellh/packer/builder/amazon/ebsvolume"
+	amazoninstancebuilder "github.com/mitchellh/packer/builder/amazon/instance"
+	azurearmbuilder "github.com/mitchellh/packer/builder/azure/arm"
+	cloudstackbuilder "github.com/mitchellh/packer/builder/cloudstack"
+	digitaloceanbuilder "github.com/mitchellh/packer/builder/digitalocean"
+	dockerbuilder "github.com/mitchellh/packer/builder/docker"
+	filebuilder "github.com/mitchellh/packer/builder/file"
+	googlecomputebuilder "github.com/mitchellh/packer/builder/googlecompute"
+	hypervcommon "github.com/mitchellh/packer/builder/hyperv/common"
+	nullbuilder "github.com/mitchellh/packer/builder/null"
+	oneandonebuilder "github.com/mitchellh/packer/builder/oneandone"
+	openstackbuilder "github.com/mitchellh/packer/builder/openstack"
+	parallelscommon "github.com/mitchellh/packer/builder/parallels/common"
+	profitbricksbuilder "github.com/mitchellh/packer/builder/profitbricks"
+	qemubuilder "github.com/mitchellh/packer/builder/qemu"
+	tritonbuilder "github.com/mitchellh/packer/builder/triton"
+	vboxcommon "github.com/mitchellh/packer/builder/virtualbox/common"
+	vmwcommon "github.com/mitchellh/packer/builder/vmware/common"
+	vmwareisobuilder "github.com/mitchellh/packer/builder/vmware/iso"
+	"github.com/mitchellh/packer/packer"
 	"github.com/mitchellh/packer/template"
 )
 
+// artifactBuilderIds are the BuilderIds of the artifacts that the
+// builders produce, by builder type.
+var artifactBuilderIds = map[string]string{
+	"amazon-chroot":       amazonchrootbuilder.BuilderId,
+	"amazon-ebs":          amazonebsbuilder.BuilderId,
+	"amazon-ebssurrogate": amazonebssurrogatebuilder.BuilderId,
+	"amazon-ebsvolume":    amazonebsvolumebuilder.BuilderId,
+	"amazon-instance":     amazoninstancebuilder.BuilderId,
+	"azure-arm":           azurearmbuilder.BuilderId,
+	"cloudstack":          cloudstackbuilder.BuilderId,
+	"digitalocean":        digitaloceanbuilder.BuilderId,
+	"docker":              dockerbuilder.BuilderId,
+	"file":                filebuilder.BuilderId,
+	"googlecompute":       googlecomputebuilder.BuilderId,
+	"hyperv-iso":          hypervcommon.BuilderId,
+	"null":                nullbuilder.BuilderId,
+	"oneandone":           oneandonebuilder.BuilderId,
+	"openstack":           openstackbuilder.BuilderId,
+	"parallels-iso":       parallelscommon.BuilderId,
+	"parallels-pvm":       parallelscommon.BuilderId,
+	"profitbricks":        profitbricksbuilder.BuilderId,
+	"qemu":                qemubuilder.BuilderId,
+	"triton":              tritonbuilder.BuilderId,
+	"virtualbox-iso":      vboxcommon.BuilderId,
+	"virtualbox-ovf":      vboxcommon.BuilderId,
+	"vmware-iso":          vmwcommon.BuilderId,
+	"vmware-vmx":          vmwcommon.BuilderId,
+}
+
+// defaultCommunicators are the communicators of the builders that don't
+// default to SSH.
+var defaultCommunicators = map[string]string{
+	"amazon-chroot": "chroot",
+	"docker":        "docker",
+	"file":          "none",
+}
+
+// hostPortBuilders are the builders that forward a port of the host in the
+// range of ssh_host_port_min and ssh_host_port_max to the communicator, and
+// connect to that port instead. VirtualBox doesn't with ssh_skip_nat_mapping.
+var hostPortBuilders = map[string]bool{
+	"qemu":           true,
+	"virtualbox-iso": true,
+	"virtualbox-ovf": true,
+}
+
 type InspectCommand struct {
 	Meta
 }
@@ -81,10 +150,15 @@ func (c *InspectCommand) Run(args []string) int {
 				continue
 			}
 
+			def := v.Default
+			if v.Sensitive {
+				def = packer.SecretRedacted
+			}
+
 			padding := strings.Repeat(" ", max-len(k))
-			output := fmt.Sprintf("  %s%s = %s", k, padding, v.Default)
+			output := fmt.Sprintf("  %s%s = %s", k, padding, def)
 
-			ui.Machine("template-variable", k, v.Default, "0")
+			ui.Machine("template-variable", k, def, "0")
 			ui.Say(output)
 		}
 	}
@@ -118,6 +192,19 @@ func (c *InspectCommand) Run(args []string) int {
 			ui.Machine("template-builder", k, v.Type)
 			ui.Say(output)
 
+			comm, port, timeout := inspectCommunicator(v)
+			ui.Machine("template-builder-communicator", k, comm, port, timeout)
+			if port != "" {
+				comm = fmt.Sprintf("%s (port %s, timeout %s)", comm, port, timeout)
+			}
+			ui.Say(fmt.Sprintf("    communicator: %s", comm))
+
+			artifact := inspectArtifactBuilderId(v)
+			ui.Machine("template-builder-artifact", k, artifact)
+			if artifact == "" {
+				artifact = "<unknown>"
+			}
+			ui.Say(fmt.Sprintf("    artifact:     %s", artifact))
 		}
 	}
 
@@ -141,6 +228,68 @@ func (c *InspectCommand) Run(args []string) int {
 	return 0
 }
 
+// inspectCommunicator returns the communicator type of the builder and,
+// for SSH and WinRM, the port or range of host ports and the timeout it
+// connects with. Values are shown as they are in the template, without
+// interpolation.
+func inspectCommunicator(b *template.Builder) (comm, port, timeout string) {
+	comm = "ssh"
+	if c, ok := defaultCommunicators[b.Type]; ok {
+		comm = c
+	}
+	if c, ok := b.Config["communicator"]; ok {
+		comm = fmt.Sprint(c)
+	}
+
+	value := func(key, def string) string {
+		if v, ok := b.Config[key]; ok {
+			return fmt.Sprint(v)
+		}
+		return def
+	}
+
+	switch comm {
+	case "ssh":
+		port = value("ssh_port", "22")
+		// The legacy ssh_wait_timeout takes precedence, as in the builders
+		timeout = value("ssh_wait_timeout", value("ssh_timeout", "5m"))
+	case "winrm":
+		def := "5985"
+		if ssl, ok := b.Config["winrm_use_ssl"].(bool); ok && ssl {
+			def = "5986"
+		}
+		port = value("winrm_port", def)
+		timeout = value("winrm_timeout", "30m")
+	default:
+		return
+	}
+
+	skipNat, _ := b.Config["ssh_skip_nat_mapping"].(bool)
+	if hostPortBuilders[b.Type] && !skipNat {
+		port = fmt.Sprintf("%s-%s",
+			value("ssh_host_port_min", "2222"), value("ssh_host_port_max", "4444"))
+	}
+
+	return
+}
+
+// inspectArtifactBuilderId returns the BuilderId of the artifact the
+// builder produces, or an empty string if it isn't known.
+func inspectArtifactBuilderId(b *template.Builder) string {
+	switch b.Type {
+	case "docker":
+		if commit, ok := b.Config["commit"].(bool); ok && commit {
+			return dockerbuilder.BuilderIdImport
+		}
+	case "vmware-iso":
+		if remote, ok := b.Config["remote_type"]; ok && remote != "" {
+			return vmwareisobuilder.BuilderIdESX
+		}
+	}
+
+	return artifactBuilderIds[b.Type]
+}
+
 func (*InspectCommand) Help() string {
 	helpText := `
 Usage: packer inspect TEMPLATE
@@ -0,0 +1,96 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/mitchellh/packer/template"
+)
+
+func TestInspectCommunicator(t *testing.T) {
+	cases := []struct {
+		Builder *template.Builder
+		Comm    string
+		Port    string
+		Timeout string
+	}{
+		{
+			&template.Builder{Type: "qemu"},
+			"ssh", "2222-4444", "5m",
+		},
+		{
+			&template.Builder{Type: "qemu", Config: map[string]interface{}{
+				"ssh_host_port_min": 3000,
+				"ssh_host_port_max": 3010,
+				"ssh_timeout":       "20m",
+			}},
+			"ssh", "3000-3010", "20m",
+		},
+		{
+			&template.Builder{Type: "qemu", Config: map[string]interface{}{
+				"ssh_timeout":      "20m",
+				"ssh_wait_timeout": "1h",
+			}},
+			"ssh", "2222-4444", "1h",
+		},
+		{
+			&template.Builder{Type: "virtualbox-iso", Config: map[string]interface{}{
+				"ssh_port":             2200,
+				"ssh_skip_nat_mapping": true,
+			}},
+			"ssh", "2200", "5m",
+		},
+		{
+			&template.Builder{Type: "amazon-ebs", Config: map[string]interface{}{
+				"ssh_port": 2200,
+			}},
+			"ssh", "2200", "5m",
+		},
+		{
+			&template.Builder{Type: "amazon-ebs", Config: map[string]interface{}{
+				"communicator":  "winrm",
+				"winrm_use_ssl": true,
+			}},
+			"winrm", "5986", "30m",
+		},
+		{
+			&template.Builder{Type: "docker"},
+			"docker", "", "",
+		},
+	}
+
+	for _, tc := range cases {
+		comm, port, timeout := inspectCommunicator(tc.Builder)
+		if comm != tc.Comm || port != tc.Port || timeout != tc.Timeout {
+			t.Fatalf("%s: bad: %s %s %s", tc.Builder.Type, comm, port, timeout)
+		}
+	}
+}
+
+func TestInspectArtifactBuilderId(t *testing.T) {
+	cases := []struct {
+		Builder  *template.Builder
+		Expected string
+	}{
+		{&template.Builder{Type: "qemu"}, "transcend.qemu"},
+		{&template.Builder{Type: "docker"}, "packer.docker"},
+		{
+			&template.Builder{Type: "docker", Config: map[string]interface{}{
+				"commit": true,
+			}},
+			"packer.post-processor.docker-import",
+		},
+		{
+			&template.Builder{Type: "vmware-iso", Config: map[string]interface{}{
+				"remote_type": "esx5",
+			}},
+			"mitchellh.vmware-esx",
+		},
+		{&template.Builder{Type: "unknown"}, ""},
+	}
+
+	for _, tc := range cases {
+		if actual := inspectArtifactBuilderId(tc.Builder); actual != tc.Expected {
+			t.Fatalf("%s: bad: %s", tc.Builder.Type, actual)
+		}
+	}
+}
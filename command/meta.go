@@ -5,6 +5,8 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"os"
+	"path"
 
 	"github.com/mitchellh/packer/helper/flag-kv"
 	"github.com/mitchellh/packer/helper/flag-slice"
@@ -34,6 +36,31 @@ type Meta struct {
 	flagBuildExcept []string
 	flagBuildOnly   []string
 	flagVars        map[string]string
+
+	// postProcessorConcurrency is passed on to the CoreConfig of
+	// created cores when it is greater than zero.
+	postProcessorConcurrency int
+}
+
+// EnvVariablePrefix is the prefix of environment variables that set user
+// variables, such as PACKER_VAR_aws_access_key.
+const EnvVariablePrefix = "PACKER_VAR_"
+
+// variables returns the user variables set with -var and -var-file, and
+// the ones of the template that are set in the environment. Variables set
+// with flags take precedence over the environment.
+func (m *Meta) variables(tpl *template.Template) map[string]string {
+	vars := make(map[string]string)
+	for name := range tpl.Variables {
+		if v, ok := os.LookupEnv(EnvVariablePrefix + name); ok {
+			vars[name] = v
+		}
+	}
+	for k, v := range m.flagVars {
+		vars[k] = v
+	}
+
+	return vars
 }
 
 // Core returns the core for the given template given the configured
@@ -42,7 +69,10 @@ func (m *Meta) Core(tpl *template.Template) (*packer.Core, error) {
 	// Copy the config so we don't modify it
 	config := *m.CoreConfig
 	config.Template = tpl
-	config.Variables = m.flagVars
+	config.Variables = m.variables(tpl)
+	if m.postProcessorConcurrency > 0 {
+		config.PostProcessorConcurrency = m.postProcessorConcurrency
+	}
 
 	// Init the core
 	core, err := packer.NewCore(&config)
@@ -55,22 +85,28 @@ func (m *Meta) Core(tpl *template.Template) (*packer.Core, error) {
 
 // BuildNames returns the list of builds that are in the given core
 // that we care about taking into account the only and except flags.
+// The only and except values may be glob patterns such as "ubuntu-*",
+// matched with the rules of path.Match.
 func (m *Meta) BuildNames(c *packer.Core) []string {
-	// TODO: test
-
 	// Filter the "only"
 	if len(m.flagBuildOnly) > 0 {
-		// Build a set of all the available names
-		nameSet := make(map[string]struct{})
-		for _, n := range c.BuildNames() {
-			nameSet[n] = struct{}{}
-		}
+		names := c.BuildNames()
 
-		// Build our result set which we pre-allocate some sane number
+		// Build our result set which we pre-allocate some sane number.
+		// Builds are returned in the order of the patterns that matched
+		// them, and each build is only returned once.
+		seen := make(map[string]struct{})
 		result := make([]string, 0, len(m.flagBuildOnly))
-		for _, n := range m.flagBuildOnly {
-			if _, ok := nameSet[n]; ok {
-				result = append(result, n)
+		for _, pattern := range m.flagBuildOnly {
+			for _, n := range names {
+				if _, ok := seen[n]; ok {
+					continue
+				}
+
+				if matchBuildName(pattern, n) {
+					seen[n] = struct{}{}
+					result = append(result, n)
+				}
 			}
 		}
 
@@ -79,20 +115,19 @@ func (m *Meta) BuildNames(c *packer.Core) []string {
 
 	// Filter the "except"
 	if len(m.flagBuildExcept) > 0 {
-		// Build a set of the things we don't want
-		nameSet := make(map[string]struct{})
-		for _, n := range m.flagBuildExcept {
-			nameSet[n] = struct{}{}
-		}
-
 		// Build our result set which is the names of all builds except
-		// those in the given set.
+		// those matching any of the given patterns.
 		names := c.BuildNames()
 		result := make([]string, 0, len(names))
+	NAMES:
 		for _, n := range names {
-			if _, ok := nameSet[n]; !ok {
-				result = append(result, n)
+			for _, pattern := range m.flagBuildExcept {
+				if matchBuildName(pattern, n) {
+					continue NAMES
+				}
 			}
+
+			result = append(result, n)
 		}
 		return result
 	}
@@ -101,6 +136,17 @@ func (m *Meta) BuildNames(c *packer.Core) []string {
 	return c.BuildNames()
 }
 
+// matchBuildName reports whether the build name matches the given
+// pattern. A malformed pattern only matches a build with exactly that name.
+func matchBuildName(pattern, name string) bool {
+	if pattern == name {
+		return true
+	}
+
+	matched, err := path.Match(pattern, name)
+	return err == nil && matched
+}
+
 // FlagSet returns a FlagSet with the common flags that every
 // command implements. The exact behavior of FlagSet can be configured
 // using the flags as the second parameter, for example to disable
@@ -48,12 +48,15 @@ import (
 	dockertagpostprocessor "github.com/mitchellh/packer/post-processor/docker-tag"
 	googlecomputeexportpostprocessor "github.com/mitchellh/packer/post-processor/googlecompute-export"
 	manifestpostprocessor "github.com/mitchellh/packer/post-processor/manifest"
+	ovapostprocessor "github.com/mitchellh/packer/post-processor/ova"
 	shelllocalpostprocessor "github.com/mitchellh/packer/post-processor/shell-local"
+	uploadpostprocessor "github.com/mitchellh/packer/post-processor/upload"
 	vagrantpostprocessor "github.com/mitchellh/packer/post-processor/vagrant"
 	vagrantcloudpostprocessor "github.com/mitchellh/packer/post-processor/vagrant-cloud"
 	vspherepostprocessor "github.com/mitchellh/packer/post-processor/vsphere"
 	ansibleprovisioner "github.com/mitchellh/packer/provisioner/ansible"
 	ansiblelocalprovisioner "github.com/mitchellh/packer/provisioner/ansible-local"
+	breakpointprovisioner "github.com/mitchellh/packer/provisioner/breakpoint"
 	chefclientprovisioner "github.com/mitchellh/packer/provisioner/chef-client"
 	chefsoloprovisioner "github.com/mitchellh/packer/provisioner/chef-solo"
 	convergeprovisioner "github.com/mitchellh/packer/provisioner/converge"
@@ -102,6 +105,7 @@ var Builders = map[string]packer.Builder{
 var Provisioners = map[string]packer.Provisioner{
 	"ansible":           new(ansibleprovisioner.Provisioner),
 	"ansible-local":     new(ansiblelocalprovisioner.Provisioner),
+	"breakpoint":        new(breakpointprovisioner.Provisioner),
 	"chef-client":       new(chefclientprovisioner.Provisioner),
 	"chef-solo":         new(chefsoloprovisioner.Provisioner),
 	"converge":          new(convergeprovisioner.Provisioner),
@@ -128,7 +132,9 @@ var PostProcessors = map[string]packer.PostProcessor{
 	"docker-tag":           new(dockertagpostprocessor.PostProcessor),
 	"googlecompute-export": new(googlecomputeexportpostprocessor.PostProcessor),
 	"manifest":             new(manifestpostprocessor.PostProcessor),
+	"ova":                  new(ovapostprocessor.PostProcessor),
 	"shell-local":          new(shelllocalpostprocessor.PostProcessor),
+	"upload":               new(uploadpostprocessor.PostProcessor),
 	"vagrant":              new(vagrantpostprocessor.PostProcessor),
 	"vagrant-cloud":        new(vagrantcloudpostprocessor.PostProcessor),
 	"vsphere":              new(vspherepostprocessor.PostProcessor),
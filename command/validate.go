@@ -1,10 +1,15 @@
 package command
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"reflect"
+	"sort"
 	"strings"
 
+	"github.com/mitchellh/packer/fix"
 	"github.com/mitchellh/packer/packer"
 	"github.com/mitchellh/packer/template"
 )
@@ -14,10 +19,11 @@ type ValidateCommand struct {
 }
 
 func (c *ValidateCommand) Run(args []string) int {
-	var cfgSyntaxOnly bool
+	var cfgSyntaxOnly, cfgStrict bool
 	flags := c.Meta.FlagSet("validate", FlagSetBuildFilter|FlagSetVars)
 	flags.Usage = func() { c.Ui.Say(c.Help()) }
 	flags.BoolVar(&cfgSyntaxOnly, "syntax-only", false, "check syntax only")
+	flags.BoolVar(&cfgStrict, "strict", false, "strict checks")
 	if err := flags.Parse(args); err != nil {
 		return 1
 	}
@@ -35,8 +41,26 @@ func (c *ValidateCommand) Run(args []string) int {
 		return 1
 	}
 
+	errs := make([]error, 0)
+	warnings := make(map[string][]string)
+
+	if cfgStrict {
+		strictErrs, err := validateStrict(tpl)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to check template: %s", err))
+			return 1
+		}
+
+		errs = append(errs, strictErrs...)
+	}
+
 	// If we're only checking syntax, then we're done already
 	if cfgSyntaxOnly {
+		if len(errs) > 0 {
+			c.reportErrors(errs)
+			return 1
+		}
+
 		c.Ui.Say("Syntax-only check passed. Everything looks okay.")
 		return 0
 	}
@@ -48,9 +72,6 @@ func (c *ValidateCommand) Run(args []string) int {
 		return 1
 	}
 
-	errs := make([]error, 0)
-	warnings := make(map[string][]string)
-
 	// Get the builds we care about
 	buildNames := c.Meta.BuildNames(core)
 	builds := make([]packer.Build, 0, len(buildNames))
@@ -79,15 +100,7 @@ func (c *ValidateCommand) Run(args []string) int {
 	}
 
 	if len(errs) > 0 {
-		c.Ui.Error("Template validation failed. Errors are shown below.\n")
-		for i, err := range errs {
-			c.Ui.Error(err.Error())
-
-			if (i + 1) < len(errs) {
-				c.Ui.Error("")
-			}
-		}
-
+		c.reportErrors(errs)
 		return 1
 	}
 
@@ -110,6 +123,135 @@ func (c *ValidateCommand) Run(args []string) int {
 	return 0
 }
 
+func (c *ValidateCommand) reportErrors(errs []error) {
+	c.Ui.Error("Template validation failed. Errors are shown below.\n")
+	for i, err := range errs {
+		c.Ui.Error(err.Error())
+
+		if (i + 1) < len(errs) {
+			c.Ui.Error("")
+		}
+	}
+}
+
+// validateStrict does the checks of the -strict flag: it reports user
+// variables that are defined but never used, user variables that are used
+// but never defined, and configuration that uses deprecated options that
+// "packer fix" would update.
+func validateStrict(tpl *template.Template) ([]error, error) {
+	var errs []error
+
+	called, err := tpl.UserVariablesCalled()
+	if err != nil {
+		return nil, err
+	}
+
+	defined := make([]string, 0, len(tpl.Variables))
+	for n := range tpl.Variables {
+		defined = append(defined, n)
+	}
+	sort.Strings(defined)
+	for _, n := range defined {
+		if _, ok := called[n]; !ok {
+			errs = append(errs, fmt.Errorf(
+				"variable '%s' is defined but never used", n))
+		}
+	}
+
+	used := make([]string, 0, len(called))
+	for n := range called {
+		used = append(used, n)
+	}
+	sort.Strings(used)
+	for _, n := range used {
+		if _, ok := tpl.Variables[n]; !ok {
+			errs = append(errs, fmt.Errorf(
+				"variable '%s' is used but not defined", n))
+		}
+	}
+
+	deprecated, err := deprecatedOptions(tpl.RawContents)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range deprecated {
+		errs = append(errs, errors.New(d))
+	}
+
+	return errs, nil
+}
+
+// deprecatedOptions runs every fixer on its own copy of the raw template
+// and returns a message for each builder or post-processor section that
+// the fixer would change.
+func deprecatedOptions(raw []byte) ([]string, error) {
+	var original map[string]interface{}
+	if err := json.Unmarshal(raw, &original); err != nil {
+		return nil, err
+	}
+	builders, _ := original["builders"].([]interface{})
+
+	var result []string
+	for _, name := range fix.FixerOrder {
+		var input map[string]interface{}
+		if err := json.Unmarshal(raw, &input); err != nil {
+			return nil, err
+		}
+
+		fixed, err := fix.Fixers[name].Fix(input)
+		if err != nil {
+			return nil, fmt.Errorf("fixer %s: %s", name, err)
+		}
+
+		// Round trip the fixed template through JSON so that it uses the
+		// same types as the original.
+		fixedRaw, err := json.Marshal(fixed)
+		if err != nil {
+			return nil, err
+		}
+		fixed = nil
+		if err := json.Unmarshal(fixedRaw, &fixed); err != nil {
+			return nil, err
+		}
+
+		synopsis := fix.Fixers[name].Synopsis()
+		msg := func(what string) string {
+			return fmt.Sprintf(
+				"%s uses deprecated options, run \"packer fix\": %s",
+				what, synopsis)
+		}
+		fixedBuilders, _ := fixed["builders"].([]interface{})
+		for i, b := range builders {
+			if i < len(fixedBuilders) && reflect.DeepEqual(b, fixedBuilders[i]) {
+				continue
+			}
+
+			result = append(result, msg(
+				fmt.Sprintf("builder '%s'", builderName(b))))
+		}
+
+		if pp, ok := original["post-processors"]; ok {
+			if !reflect.DeepEqual(pp, fixed["post-processors"]) {
+				result = append(result, msg("post-processors"))
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// builderName returns the name of a raw builder, which is its type unless
+// a name is set.
+func builderName(raw interface{}) string {
+	b, _ := raw.(map[string]interface{})
+	if name, ok := b["name"].(string); ok && name != "" {
+		return name
+	}
+
+	name, _ := b["type"].(string)
+	return name
+}
+
 func (*ValidateCommand) Help() string {
 	helpText := `
 Usage: packer validate [options] TEMPLATE
@@ -124,6 +266,8 @@ Usage: packer validate [options] TEMPLATE
 Options:
 
   -syntax-only           Only check syntax. Do not verify config of the template.
+  -strict                Also fail on unused or undefined variables and
+                         deprecated options.
   -except=foo,bar,baz    Validate all builds other than these
   -only=foo,bar,baz      Validate only these builds
   -var 'key=value'       Variable for templates, can be used multiple times.
@@ -1,7 +1,9 @@
 package command
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -42,3 +44,77 @@ func TestValidateCommandBadVersion(t *testing.T) {
 	}
 	t.Log(stdout)
 }
+
+func TestValidateCommand_requiredVariable(t *testing.T) {
+	c := &ValidateCommand{
+		Meta: testMetaFile(t),
+	}
+	args := []string{
+		filepath.Join(testFixture("validate-required-var"), "template.json"),
+	}
+
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("Expected exit code 1")
+	}
+}
+
+func TestValidateCommand_requiredVariableEnv(t *testing.T) {
+	c := &ValidateCommand{
+		Meta: testMetaFile(t),
+	}
+	args := []string{
+		filepath.Join(testFixture("validate-required-var"), "template.json"),
+	}
+
+	os.Setenv(EnvVariablePrefix+"foo", "bar")
+	defer os.Unsetenv(EnvVariablePrefix + "foo")
+
+	if code := c.Run(args); code != 0 {
+		fatalCommand(t, c.Meta)
+	}
+}
+
+func TestValidateCommand_strict(t *testing.T) {
+	c := &ValidateCommand{
+		Meta: testMetaFile(t),
+	}
+	args := []string{
+		"-strict",
+		"-syntax-only",
+		filepath.Join(testFixture("validate-strict"), "template.json"),
+	}
+
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("Expected exit code 1")
+	}
+
+	_, stderr := outputCommand(t, c.Meta)
+	expected := []string{
+		"variable 'unused' is defined but never used",
+		"variable 'undefined' is used but not defined",
+		"builder 'old' uses deprecated options",
+	}
+	for _, e := range expected {
+		if !strings.Contains(stderr, e) {
+			t.Errorf("Expected %q in:\n%s", e, stderr)
+		}
+	}
+	if strings.Contains(stderr, "builder 'file'") {
+		t.Errorf("Unexpected deprecation of builder 'file':\n%s", stderr)
+	}
+}
+
+func TestValidateCommand_strictOK(t *testing.T) {
+	c := &ValidateCommand{
+		Meta: testMetaFile(t),
+	}
+	args := []string{
+		"-strict",
+		filepath.Join(testFixture("validate"), "template.json"),
+	}
+
+	c.CoreConfig.Version = "102.0.0"
+	if code := c.Run(args); code != 0 {
+		fatalCommand(t, c.Meta)
+	}
+}
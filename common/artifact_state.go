@@ -0,0 +1,42 @@
+package common
+
+import (
+	"github.com/mitchellh/packer/packer"
+)
+
+// ArtifactStateData holds artifact state values that post-processors make
+// available to their output path templates. Values the artifact doesn't
+// have are left empty.
+type ArtifactStateData struct {
+	DiskFormat string
+	DiskName   string
+	DiskSize   uint64
+}
+
+// NewArtifactStateData reads the well-known state values from an artifact.
+func NewArtifactStateData(artifact packer.Artifact) ArtifactStateData {
+	var data ArtifactStateData
+	if v, ok := artifact.State("diskType").(string); ok {
+		data.DiskFormat = v
+	}
+	if v, ok := artifact.State("diskName").(string); ok {
+		data.DiskName = v
+	}
+
+	// Artifacts of plugins are received over RPC, which decodes integers
+	// as int64 or uint64 depending on their value.
+	switch v := artifact.State("diskSize").(type) {
+	case uint64:
+		data.DiskSize = v
+	case int64:
+		if v > 0 {
+			data.DiskSize = uint64(v)
+		}
+	case int:
+		if v > 0 {
+			data.DiskSize = uint64(v)
+		}
+	}
+
+	return data
+}
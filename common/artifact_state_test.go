@@ -0,0 +1,45 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/mitchellh/packer/packer"
+)
+
+func TestNewArtifactStateData(t *testing.T) {
+	artifact := &packer.MockArtifact{
+		StateValues: map[string]interface{}{
+			"diskName": "disk.qcow2",
+			"diskType": "qcow2",
+			"diskSize": uint64(40960),
+		},
+	}
+
+	data := NewArtifactStateData(artifact)
+	expected := ArtifactStateData{
+		DiskFormat: "qcow2",
+		DiskName:   "disk.qcow2",
+		DiskSize:   40960,
+	}
+	if data != expected {
+		t.Fatalf("bad: %#v", data)
+	}
+
+	// Over RPC the size is received as an int64
+	artifact.StateValues["diskSize"] = int64(40960)
+	data = NewArtifactStateData(artifact)
+	if data != expected {
+		t.Fatalf("bad: %#v", data)
+	}
+
+	artifact.StateValues["diskSize"] = 40960
+	data = NewArtifactStateData(artifact)
+	if data != expected {
+		t.Fatalf("bad: %#v", data)
+	}
+
+	data = NewArtifactStateData(&packer.MockArtifact{})
+	if data != (ArtifactStateData{}) {
+		t.Fatalf("bad: %#v", data)
+	}
+}
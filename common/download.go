@@ -6,16 +6,23 @@ import (
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"hash"
 	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"runtime"
+	"sync"
+
+	"golang.org/x/crypto/sha3"
 )
 
 // DownloadConfig is the configuration given to instantiate a new
@@ -36,6 +43,11 @@ type DownloadConfig struct {
 	// returning the local path to the file.
 	CopyFile bool
 
+	// If true and CopyFile isn't, a local file is symlinked to the target
+	// location and the path of the link is returned, instead of the path
+	// to the file itself. The checksum is still verified.
+	LinkFile bool
+
 	// The hashing implementation to use to checksum the downloaded file.
 	Hash hash.Hash
 
@@ -47,6 +59,12 @@ type DownloadConfig struct {
 	// What to use for the user agent for HTTP requests. If set to "", use the
 	// default user agent provided by Go.
 	UserAgent string
+
+	// The path to a PEM encoded CA bundle used to verify HTTPS servers in
+	// addition to the system roots, and whether to skip verification
+	// entirely.
+	CAFile             string
+	InsecureSkipVerify bool
 }
 
 // A DownloadClient helps download, verify checksums, etc.
@@ -67,6 +85,8 @@ func HashForType(t string) hash.Hash {
 		return sha256.New()
 	case "sha512":
 		return sha512.New()
+	case "sha3-256":
+		return sha3.New256()
 	default:
 		return nil
 	}
@@ -77,8 +97,10 @@ func HashForType(t string) hash.Hash {
 func NewDownloadClient(c *DownloadConfig) *DownloadClient {
 	if c.DownloaderMap == nil {
 		c.DownloaderMap = map[string]Downloader{
-			"http":  &HTTPDownloader{userAgent: c.UserAgent},
-			"https": &HTTPDownloader{userAgent: c.UserAgent},
+			"http":  newHTTPDownloader(c),
+			"https": newHTTPDownloader(c),
+			"s3":    &S3Downloader{},
+			"gs":    &GCSDownloader{},
 		}
 	}
 
@@ -94,8 +116,11 @@ type Downloader interface {
 	Total() uint
 }
 
+// Cancel cancels the running download, if any.
 func (d *DownloadClient) Cancel() {
-	// TODO(mitchellh): Implement
+	for _, downloader := range d.config.DownloaderMap {
+		downloader.Cancel()
+	}
 }
 
 func (d *DownloadClient) Get() (string, error) {
@@ -139,6 +164,13 @@ func (d *DownloadClient) Get() (string, error) {
 		if _, err = os.Stat(finalPath); err != nil {
 			return "", err
 		}
+
+		if d.config.LinkFile {
+			if finalPath, err = linkFile(sourcePath, d.config.TargetPath); err != nil {
+				return "", err
+			}
+			log.Printf("[DEBUG] Linked local file to: %s", finalPath)
+		}
 	} else {
 		finalPath = d.config.TargetPath
 
@@ -180,6 +212,50 @@ func (d *DownloadClient) Get() (string, error) {
 	return finalPath, err
 }
 
+// linkFile replaces whatever is at target with a symlink to the
+// absolute path of source, and returns target. If target already is the
+// source file, it is left alone and source is returned, so that the file
+// isn't removed as a copy later.
+func linkFile(source, target string) (string, error) {
+	absSource, err := filepath.Abs(source)
+	if err != nil {
+		return "", err
+	}
+
+	if targetInfo, err := os.Lstat(target); err == nil {
+		if sameFile(absSource, target) {
+			if targetInfo.Mode()&os.ModeSymlink != 0 {
+				return target, nil
+			}
+			return source, nil
+		}
+
+		if err := os.Remove(target); err != nil {
+			return "", err
+		}
+	}
+
+	if err := os.Symlink(absSource, target); err != nil {
+		return "", err
+	}
+
+	return target, nil
+}
+
+// sameFile returns true if both paths exist and resolve to the same file.
+func sameFile(a, b string) bool {
+	aInfo, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bInfo, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+
+	return os.SameFile(aInfo, bInfo)
+}
+
 // PercentProgress returns the download progress as a percentage.
 func (d *DownloadClient) PercentProgress() int {
 	if d.downloader == nil {
@@ -189,6 +265,16 @@ func (d *DownloadClient) PercentProgress() int {
 	return int((float64(d.downloader.Progress()) / float64(d.downloader.Total())) * 100)
 }
 
+// BytesProgress returns the number of bytes downloaded so far and the
+// total size of the download, if it is known.
+func (d *DownloadClient) BytesProgress() (uint, uint) {
+	if d.downloader == nil {
+		return 0, 0
+	}
+
+	return d.downloader.Progress(), d.downloader.Total()
+}
+
 // VerifyChecksum tests that the path matches the checksum for the
 // download.
 func (d *DownloadClient) VerifyChecksum(path string) (bool, error) {
@@ -211,13 +297,26 @@ func (d *DownloadClient) VerifyChecksum(path string) (bool, error) {
 // HTTPDownloader is an implementation of Downloader that downloads
 // files over HTTP.
 type HTTPDownloader struct {
+	downloadCanceler
+
 	progress  uint
 	total     uint
 	userAgent string
+
+	caFile   string
+	insecure bool
+
+	// client, if set, is used for requests instead of a client with the
+	// default download transport.
+	client *http.Client
 }
 
-func (*HTTPDownloader) Cancel() {
-	// TODO(mitchellh): Implement
+func newHTTPDownloader(c *DownloadConfig) *HTTPDownloader {
+	return &HTTPDownloader{
+		userAgent: c.UserAgent,
+		caFile:    c.CAFile,
+		insecure:  c.InsecureSkipVerify,
+	}
 }
 
 func (d *HTTPDownloader) Download(dst *os.File, src *url.URL) error {
@@ -238,24 +337,37 @@ func (d *HTTPDownloader) Download(dst *os.File, src *url.URL) error {
 	if err != nil {
 		return err
 	}
+	req.Cancel = d.start()
 
 	if d.userAgent != "" {
 		req.Header.Set("User-Agent", d.userAgent)
 	}
 
-	httpClient := &http.Client{
-		Transport: &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-		},
+	httpClient := d.client
+	if httpClient == nil {
+		transport, err := NewDownloadTransport(d.caFile, d.insecure)
+		if err != nil {
+			return err
+		}
+		httpClient = &http.Client{Transport: transport}
 	}
 
 	resp, err := httpClient.Do(req)
+	if err == nil {
+		resp.Body.Close()
+	}
 	if err == nil && (resp.StatusCode >= 200 && resp.StatusCode < 300) {
 		// If the HEAD request succeeded, then attempt to set the range
 		// query if we can.
 		if resp.Header.Get("Accept-Ranges") == "bytes" {
-			if fi, err := dst.Stat(); err == nil {
-				if _, err = dst.Seek(0, os.SEEK_END); err == nil {
+			if fi, err := dst.Stat(); err == nil && fi.Size() > 0 {
+				if resp.ContentLength > 0 && fi.Size() >= resp.ContentLength {
+					// A partial download can't be as large as the whole
+					// file, so it is complete but failed verification, or
+					// it is from a different file. Start over.
+					log.Printf("Existing file is not a partial download, restarting: %s", dst.Name())
+				} else if _, err = dst.Seek(0, os.SEEK_END); err == nil {
+					log.Printf("Resuming download at byte %d", fi.Size())
 					req.Header.Set("Range", fmt.Sprintf("bytes=%d-", fi.Size()))
 					d.progress = uint(fi.Size())
 				}
@@ -270,6 +382,27 @@ func (d *HTTPDownloader) Download(dst *os.File, src *url.URL) error {
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Error downloading %s: %s", src.String(), resp.Status)
+	}
+
+	// If we asked for a range and the server sent the whole file instead,
+	// write it from the beginning.
+	if d.progress > 0 && resp.StatusCode != http.StatusPartialContent {
+		log.Printf("Server doesn't support resuming, restarting download")
+		if _, err := dst.Seek(0, 0); err != nil {
+			return err
+		}
+		d.progress = 0
+	}
+
+	// Drop anything after the point we start writing at, left over from
+	// an earlier download.
+	if err := dst.Truncate(int64(d.progress)); err != nil {
+		return err
+	}
 
 	d.total = d.progress + uint(resp.ContentLength)
 	var buffer [4096]byte
@@ -300,3 +433,63 @@ func (d *HTTPDownloader) Progress() uint {
 func (d *HTTPDownloader) Total() uint {
 	return d.total
 }
+
+// NewDownloadTransport returns the HTTP transport used for downloads. It
+// honors the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+// If caFile is set, the certificates in it are trusted in addition to the
+// system roots, and if insecure is true, server certificates aren't
+// verified at all.
+func NewDownloadTransport(caFile string, insecure bool) (*http.Transport, error) {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+	}
+	if caFile == "" && !insecure {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecure}
+	if caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading CA file: %s", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			log.Printf("Error loading system CA certificates, using only %s: %s", caFile, err)
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("No certificates found in CA file: %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// downloadCanceler lets a Downloader cancel its running download by
+// closing the Cancel channel of its HTTP requests.
+type downloadCanceler struct {
+	l        sync.Mutex
+	cancelCh chan struct{}
+}
+
+// start returns the cancel channel of a new download.
+func (c *downloadCanceler) start() <-chan struct{} {
+	c.l.Lock()
+	defer c.l.Unlock()
+	c.cancelCh = make(chan struct{})
+	return c.cancelCh
+}
+
+// Cancel cancels the running download, if any.
+func (c *downloadCanceler) Cancel() {
+	c.l.Lock()
+	defer c.l.Unlock()
+	if c.cancelCh != nil {
+		close(c.cancelCh)
+		c.cancelCh = nil
+	}
+}
@@ -0,0 +1,44 @@
+package common
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// gcsReadScope is the OAuth scope needed to download objects from Google
+// Cloud Storage.
+const gcsReadScope = "https://www.googleapis.com/auth/devstorage.read_only"
+
+// GCSDownloader is an implementation of Downloader that downloads files
+// from Google Cloud Storage with URLs like gs://bucket/object, using the
+// Application Default Credentials.
+type GCSDownloader struct {
+	HTTPDownloader
+}
+
+func (d *GCSDownloader) Download(dst *os.File, src *url.URL) error {
+	bucket := src.Host
+	object := strings.TrimPrefix(src.Path, "/")
+	if bucket == "" || object == "" {
+		return fmt.Errorf("GCS URL must be of the form gs://bucket/object: %s", src.String())
+	}
+
+	if d.client == nil {
+		client, err := google.DefaultClient(oauth2.NoContext, gcsReadScope)
+		if err != nil {
+			return fmt.Errorf("Error getting Google credentials: %s", err)
+		}
+		d.client = client
+	}
+
+	return d.HTTPDownloader.Download(dst, &url.URL{
+		Scheme: "https",
+		Host:   "storage.googleapis.com",
+		Path:   "/" + bucket + "/" + object,
+	})
+}
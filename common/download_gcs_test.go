@@ -0,0 +1,103 @@
+package common
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+)
+
+// rewriteTransport sends every request to the given test server.
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestGCSDownloader_Impl(t *testing.T) {
+	var _ Downloader = new(GCSDownloader)
+}
+
+func TestGCSDownloader(t *testing.T) {
+	var requested string
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requested = r.Host + r.URL.Path
+		rw.Write([]byte("hello\n"))
+	}))
+	defer ts.Close()
+
+	target, _ := url.Parse(ts.URL)
+	d := new(GCSDownloader)
+	d.client = &http.Client{Transport: &rewriteTransport{target: target}}
+
+	tf, _ := ioutil.TempFile("", "packer")
+	defer os.Remove(tf.Name())
+	defer tf.Close()
+
+	src, _ := url.Parse("gs://bucket/path/to/the-OS.iso")
+	if err := d.Download(tf, src); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if requested != "storage.googleapis.com/bucket/path/to/the-OS.iso" {
+		t.Fatalf("bad request: %s", requested)
+	}
+
+	raw, err := ioutil.ReadFile(tf.Name())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(raw) != "hello\n" {
+		t.Fatalf("bad: %q", raw)
+	}
+}
+
+func TestGCSDownloader_cancel(t *testing.T) {
+	started := make(chan struct{})
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Length", "1024")
+		if r.Method == "HEAD" {
+			return
+		}
+
+		rw.Write([]byte("hello\n"))
+		rw.(http.Flusher).Flush()
+		close(started)
+		<-done
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	target, _ := url.Parse(ts.URL)
+	d := new(GCSDownloader)
+	d.client = &http.Client{Transport: &rewriteTransport{target: target}}
+
+	tf, _ := ioutil.TempFile("", "packer")
+	defer os.Remove(tf.Name())
+	defer tf.Close()
+
+	src, _ := url.Parse("gs://bucket/the-OS.iso")
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- d.Download(tf, src)
+	}()
+
+	<-started
+	d.Cancel()
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Fatal("should error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("download wasn't cancelled")
+	}
+}
@@ -0,0 +1,135 @@
+package common
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// S3Downloader is an implementation of Downloader that downloads files
+// from Amazon S3 with URLs like s3://bucket/key. Credentials are found the
+// same way as the AWS CLI does. The region of the bucket is taken from the
+// "region" query parameter, or AWS_REGION and AWS_DEFAULT_REGION.
+type S3Downloader struct {
+	downloadCanceler
+
+	progress uint
+	total    uint
+
+	// config overrides the configuration of the S3 client, for tests.
+	config *aws.Config
+}
+
+func (d *S3Downloader) Download(dst *os.File, src *url.URL) error {
+	log.Printf("Starting download: %s", src.String())
+
+	// Seek to the beginning by default
+	if _, err := dst.Seek(0, 0); err != nil {
+		return err
+	}
+
+	// Reset our progress
+	d.progress = 0
+	cancelCh := d.start()
+
+	bucket := src.Host
+	key := strings.TrimPrefix(src.Path, "/")
+	if bucket == "" || key == "" {
+		return fmt.Errorf("S3 URL must be of the form s3://bucket/key: %s", src.String())
+	}
+
+	configs := []*aws.Config{aws.NewConfig().WithRegion(s3Region(src))}
+	if d.config != nil {
+		configs = append(configs, d.config)
+	}
+	sess, err := session.NewSession(configs...)
+	if err != nil {
+		return err
+	}
+	svc := s3.New(sess)
+
+	// Resume a partial download if there is one.
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	headReq, head := svc.HeadObjectRequest(&s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	headReq.HTTPRequest.Cancel = cancelCh
+	if err := headReq.Send(); err != nil {
+		return fmt.Errorf("Error looking up %s: %s", src.String(), err)
+	}
+	if fi, err := dst.Stat(); err == nil && fi.Size() > 0 && fi.Size() < aws.Int64Value(head.ContentLength) {
+		if _, err = dst.Seek(0, os.SEEK_END); err == nil {
+			log.Printf("Resuming download at byte %d", fi.Size())
+			input.Range = aws.String(fmt.Sprintf("bytes=%d-", fi.Size()))
+			d.progress = uint(fi.Size())
+		}
+	}
+
+	// Drop anything after the point we start writing at, left over from
+	// an earlier download.
+	if err := dst.Truncate(int64(d.progress)); err != nil {
+		return err
+	}
+
+	req, resp := svc.GetObjectRequest(input)
+	req.HTTPRequest.Cancel = cancelCh
+	if err := req.Send(); err != nil {
+		return fmt.Errorf("Error downloading %s: %s", src.String(), err)
+	}
+	defer resp.Body.Close()
+
+	d.total = uint(aws.Int64Value(head.ContentLength))
+	var buffer [4096]byte
+	for {
+		n, err := resp.Body.Read(buffer[:])
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		d.progress += uint(n)
+
+		if _, werr := dst.Write(buffer[:n]); werr != nil {
+			return werr
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (d *S3Downloader) Progress() uint {
+	return d.progress
+}
+
+func (d *S3Downloader) Total() uint {
+	return d.total
+}
+
+// s3Region returns the region to use for the bucket in the given URL.
+func s3Region(u *url.URL) string {
+	if region := u.Query().Get("region"); region != "" {
+		return region
+	}
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		return region
+	}
+	if region := os.Getenv("AWS_DEFAULT_REGION"); region != "" {
+		return region
+	}
+
+	return "us-east-1"
+}
@@ -0,0 +1,133 @@
+package common
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+func TestS3Downloader_Impl(t *testing.T) {
+	var _ Downloader = new(S3Downloader)
+}
+
+func TestS3Downloader(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/bucket/path/to/the-OS.iso" {
+			http.NotFound(rw, r)
+			return
+		}
+
+		http.ServeContent(rw, r, "the-OS.iso", time.Now(), bytes.NewReader([]byte("hello\n")))
+	}))
+	defer ts.Close()
+
+	// Start with a partial download to resume
+	tf, _ := ioutil.TempFile("", "packer")
+	tf.Write([]byte("he"))
+	defer os.Remove(tf.Name())
+	defer tf.Close()
+
+	d := &S3Downloader{
+		config: &aws.Config{
+			Credentials:      credentials.NewStaticCredentials("id", "secret", ""),
+			Endpoint:         aws.String(ts.URL),
+			S3ForcePathStyle: aws.Bool(true),
+		},
+	}
+	src, _ := url.Parse("s3://bucket/path/to/the-OS.iso")
+	if err := d.Download(tf, src); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	raw, err := ioutil.ReadFile(tf.Name())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(raw) != "hello\n" {
+		t.Fatalf("bad: %q", raw)
+	}
+	if d.Progress() != 6 || d.Total() != 6 {
+		t.Fatalf("bad progress: %d of %d", d.Progress(), d.Total())
+	}
+
+	src, _ = url.Parse("s3://bucket/missing.iso")
+	if err := d.Download(tf, src); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestS3Downloader_cancel(t *testing.T) {
+	started := make(chan struct{})
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Length", "1024")
+		if r.Method == "HEAD" {
+			return
+		}
+
+		rw.Write([]byte("hello\n"))
+		rw.(http.Flusher).Flush()
+		close(started)
+		<-done
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	tf, _ := ioutil.TempFile("", "packer")
+	defer os.Remove(tf.Name())
+	defer tf.Close()
+
+	d := &S3Downloader{
+		config: &aws.Config{
+			Credentials:      credentials.NewStaticCredentials("id", "secret", ""),
+			Endpoint:         aws.String(ts.URL),
+			S3ForcePathStyle: aws.Bool(true),
+		},
+	}
+	src, _ := url.Parse("s3://bucket/the-OS.iso")
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- d.Download(tf, src)
+	}()
+
+	<-started
+	d.Cancel()
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Fatal("should error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("download wasn't cancelled")
+	}
+}
+
+func TestS3Region(t *testing.T) {
+	defer os.Setenv("AWS_REGION", os.Getenv("AWS_REGION"))
+	defer os.Setenv("AWS_DEFAULT_REGION", os.Getenv("AWS_DEFAULT_REGION"))
+	os.Setenv("AWS_REGION", "")
+	os.Setenv("AWS_DEFAULT_REGION", "")
+
+	u, _ := url.Parse("s3://bucket/key")
+	if r := s3Region(u); r != "us-east-1" {
+		t.Fatalf("bad: %s", r)
+	}
+
+	os.Setenv("AWS_DEFAULT_REGION", "eu-west-1")
+	if r := s3Region(u); r != "eu-west-1" {
+		t.Fatalf("bad: %s", r)
+	}
+
+	u, _ = url.Parse("s3://bucket/key?region=ap-southeast-2")
+	if r := s3Region(u); r != "ap-southeast-2" {
+		t.Fatalf("bad: %s", r)
+	}
+}
@@ -3,11 +3,13 @@ package common
 import (
 	"crypto/md5"
 	"encoding/hex"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 )
@@ -199,6 +201,147 @@ func TestDownloadClient_resume(t *testing.T) {
 	}
 }
 
+func TestDownloadClient_resumeNotSupported(t *testing.T) {
+	tf, _ := ioutil.TempFile("", "packer")
+	tf.Write([]byte("w"))
+	tf.Close()
+	defer os.Remove(tf.Name())
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method == "HEAD" {
+			rw.Header().Set("Accept-Ranges", "bytes")
+			rw.WriteHeader(204)
+			return
+		}
+
+		// Ignore the range and always send the whole file
+		rw.Write([]byte("hello\n"))
+	}))
+	defer ts.Close()
+
+	client := NewDownloadClient(&DownloadConfig{
+		Url:        ts.URL,
+		TargetPath: tf.Name(),
+	})
+	path, err := client.Get()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if string(raw) != "hello\n" {
+		t.Fatalf("bad: %s", string(raw))
+	}
+}
+
+func TestDownloadClient_resumeCompleteFile(t *testing.T) {
+	tf, _ := ioutil.TempFile("", "packer")
+	tf.Write([]byte("jello\n"))
+	tf.Close()
+	defer os.Remove(tf.Name())
+
+	ts := httptest.NewServer(http.FileServer(http.Dir("./test-fixtures/root")))
+	defer ts.Close()
+
+	client := NewDownloadClient(&DownloadConfig{
+		Url:        ts.URL + "/basic.txt",
+		TargetPath: tf.Name(),
+	})
+	path, err := client.Get()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if string(raw) != "hello\n" {
+		t.Fatalf("bad: %s", string(raw))
+	}
+}
+
+func TestDownloadClient_notFound(t *testing.T) {
+	tf, _ := ioutil.TempFile("", "packer")
+	tf.Close()
+	defer os.Remove(tf.Name())
+
+	ts := httptest.NewServer(http.FileServer(http.Dir("./test-fixtures/root")))
+	defer ts.Close()
+
+	client := NewDownloadClient(&DownloadConfig{
+		Url:        ts.URL + "/missing.txt",
+		TargetPath: tf.Name(),
+	})
+	if _, err := client.Get(); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestDownloadClient_caFile(t *testing.T) {
+	tf, _ := ioutil.TempFile("", "packer")
+	tf.Close()
+	defer os.Remove(tf.Name())
+
+	ts := httptest.NewTLSServer(http.FileServer(http.Dir("./test-fixtures/root")))
+	defer ts.Close()
+
+	caFile, err := ioutil.TempFile("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Remove(caFile.Name())
+	pem.Encode(caFile, &pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	caFile.Close()
+
+	// Without trusting the test server's certificate the download fails
+	client := NewDownloadClient(&DownloadConfig{
+		Url:        ts.URL + "/basic.txt",
+		TargetPath: tf.Name(),
+	})
+	if _, err := client.Get(); err == nil {
+		t.Fatal("should error")
+	}
+
+	cases := []*DownloadConfig{
+		{CAFile: caFile.Name()},
+		{InsecureSkipVerify: true},
+	}
+	for _, config := range cases {
+		config.Url = ts.URL + "/basic.txt"
+		config.TargetPath = tf.Name()
+
+		path, err := NewDownloadClient(config).Get()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if string(raw) != "hello\n" {
+			t.Fatalf("bad: %s", string(raw))
+		}
+	}
+}
+
+func TestNewDownloadTransport_badCAFile(t *testing.T) {
+	tf, _ := ioutil.TempFile("", "packer")
+	tf.Write([]byte("not a certificate"))
+	tf.Close()
+	defer os.Remove(tf.Name())
+
+	if _, err := NewDownloadTransport(tf.Name(), false); err == nil {
+		t.Fatal("should error")
+	}
+}
+
 func TestDownloadClient_usesDefaultUserAgent(t *testing.T) {
 	tf, err := ioutil.TempFile("", "packer")
 	if err != nil {
@@ -337,6 +480,19 @@ func TestHashForType(t *testing.T) {
 		}
 	}
 
+	if h := HashForType("sha3-256"); h == nil {
+		t.Fatalf("sha3-256 hash is nil")
+	} else {
+		h.Write([]byte("foo"))
+		result := h.Sum(nil)
+
+		expected := "76d3bc41c9f588f7fcd0d5bf4718f8f84b1c41b20882703100b9eb9413807c01"
+		actual := hex.EncodeToString(result)
+		if actual != expected {
+			t.Fatalf("bad hash: %s", actual)
+		}
+	}
+
 	if HashForType("fake") != nil {
 		t.Fatalf("fake hash is not nil")
 	}
@@ -384,3 +540,103 @@ func TestDownloadFileUrl(t *testing.T) {
 	}
 
 }
+
+func TestDownloadFileUrl_link(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Unable to detect working directory: %s", err)
+	}
+
+	sourcePath := filepath.Join(cwd, "test-fixtures", "fileurl", "cake")
+	checksum, err := hex.DecodeString("606f1945f81a022d0ed0bd99edfd4f99081c1cb1f97fae087291ee14e945e608")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+	targetPath := filepath.Join(td, "cake.iso")
+
+	// A stale file at the target is replaced
+	if err := ioutil.WriteFile(targetPath, []byte("stale"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	config := &DownloadConfig{
+		Url:        "file://" + filepath.ToSlash(sourcePath),
+		TargetPath: targetPath,
+		Checksum:   checksum,
+		Hash:       HashForType("sha256"),
+		LinkFile:   true,
+	}
+
+	path, err := NewDownloadClient(config).Get()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if path != targetPath {
+		t.Fatalf("bad: %s", path)
+	}
+
+	link, err := os.Readlink(targetPath)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if link != sourcePath {
+		t.Fatalf("bad: %s", link)
+	}
+
+	// A bad checksum removes the link but not the file
+	config.Checksum = []byte("nope")
+	config.Hash = HashForType("sha256")
+	if _, err := NewDownloadClient(config).Get(); err == nil {
+		t.Fatal("should error")
+	}
+	if _, err := os.Lstat(targetPath); !os.IsNotExist(err) {
+		t.Fatalf("link should be removed: %s", err)
+	}
+	if _, err := os.Stat(sourcePath); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestDownloadFileUrl_linkSameFile(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	sourcePath := filepath.Join(td, "cake.iso")
+	if err := ioutil.WriteFile(sourcePath, []byte("cake"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The target is the source file itself, through a linked directory
+	if err := os.Symlink(td, filepath.Join(td, "alias")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	config := &DownloadConfig{
+		Url:        "file://" + filepath.ToSlash(sourcePath),
+		TargetPath: filepath.Join(td, "alias", "cake.iso"),
+		Checksum:   []byte("nope"),
+		Hash:       HashForType("sha256"),
+		LinkFile:   true,
+	}
+
+	if _, err := NewDownloadClient(config).Get(); err == nil {
+		t.Fatal("should error")
+	}
+
+	info, err := os.Lstat(sourcePath)
+	if err != nil {
+		t.Fatalf("source should still exist: %s", err)
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		t.Fatal("source should not be replaced by a link")
+	}
+}
+
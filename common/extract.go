@@ -0,0 +1,135 @@
+package common
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// archiveExtensions maps the extensions of compressed files that are
+// extracted after they are downloaded to their format.
+var archiveExtensions = map[string]string{
+	".bz2": "bzip2",
+	".gz":  "gzip",
+	".xz":  "xz",
+	".zip": "zip",
+}
+
+// ArchiveFormat returns the format of the compressed file at the given
+// URL or path, based on its extension, along with the name of the file
+// without that extension. The format is "" if it isn't a compressed file.
+func ArchiveFormat(name string) (string, string) {
+	if i := strings.IndexAny(name, "?#"); i > -1 {
+		name = name[:i]
+	}
+
+	ext := strings.ToLower(path.Ext(name))
+	format, ok := archiveExtensions[ext]
+	if !ok {
+		return "", name
+	}
+
+	return format, name[:len(name)-len(ext)]
+}
+
+// ExtractArchive decompresses the file at src of the given format to dst.
+// Zip archives must contain exactly one file, which is extracted. The
+// file at dst is only created once extraction completes.
+func ExtractArchive(dst, src, format string) error {
+	tmp := dst + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	err = extractTo(out, src, format)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("Error extracting %s: %s", src, err)
+	}
+
+	return os.Rename(tmp, dst)
+}
+
+func extractTo(w io.Writer, src, format string) error {
+	if format == "zip" {
+		return extractZip(w, src)
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader
+	switch format {
+	case "bzip2":
+		r = bzip2.NewReader(f)
+	case "gzip":
+		gzipReader, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		r = gzipReader
+	case "xz":
+		// There is no xz implementation in the standard library, so
+		// use the xz command.
+		cmd := exec.Command("xz", "--decompress", "--stdout")
+		var stderr bytes.Buffer
+		cmd.Stdin = f
+		cmd.Stdout = w
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil
+	default:
+		return fmt.Errorf("Unsupported archive format: %s", format)
+	}
+
+	_, err = io.Copy(w, r)
+	return err
+}
+
+func extractZip(w io.Writer, src string) error {
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	var file *zip.File
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if file != nil {
+			return fmt.Errorf("zip archive contains more than one file")
+		}
+		file = f
+	}
+	if file == nil {
+		return fmt.Errorf("zip archive is empty")
+	}
+
+	r, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	_, err = io.Copy(w, r)
+	return err
+}
@@ -0,0 +1,107 @@
+package common
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveFormat(t *testing.T) {
+	cases := []struct {
+		Input  string
+		Format string
+		Name   string
+	}{
+		{"http://example.com/disk.img.xz", "xz", "http://example.com/disk.img"},
+		{"http://example.com/disk.img.GZ?mirror=1", "gzip", "http://example.com/disk.img"},
+		{"file:///tmp/the-OS.iso.zip", "zip", "file:///tmp/the-OS.iso"},
+		{"/tmp/disk.raw.bz2", "bzip2", "/tmp/disk.raw"},
+		{"http://example.com/the-OS.iso", "", "http://example.com/the-OS.iso"},
+	}
+
+	for _, tc := range cases {
+		format, name := ArchiveFormat(tc.Input)
+		if format != tc.Format || name != tc.Name {
+			t.Fatalf("%s: bad: %q %q", tc.Input, format, name)
+		}
+	}
+}
+
+func TestExtractArchive(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	gzipPath := filepath.Join(td, "disk.img.gz")
+	f, _ := os.Create(gzipPath)
+	w := gzip.NewWriter(f)
+	w.Write([]byte("disk contents"))
+	w.Close()
+	f.Close()
+
+	zipPath := filepath.Join(td, "disk.img.zip")
+	f, _ = os.Create(zipPath)
+	zw := zip.NewWriter(f)
+	zf, _ := zw.Create("disk.img")
+	zf.Write([]byte("disk contents"))
+	zw.Close()
+	f.Close()
+
+	archives := map[string]string{
+		gzipPath: "gzip",
+		zipPath:  "zip",
+	}
+	if _, err := exec.LookPath("xz"); err == nil {
+		xzPath := filepath.Join(td, "disk.img.xz")
+		ioutil.WriteFile(filepath.Join(td, "disk.img"), []byte("disk contents"), 0644)
+		if err := exec.Command("xz", filepath.Join(td, "disk.img")).Run(); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		archives[xzPath] = "xz"
+	}
+
+	for path, format := range archives {
+		dst := filepath.Join(td, "extracted")
+		if err := ExtractArchive(dst, path, format); err != nil {
+			t.Fatalf("%s: err: %s", format, err)
+		}
+
+		contents, err := ioutil.ReadFile(dst)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if string(contents) != "disk contents" {
+			t.Fatalf("%s: bad: %s", format, contents)
+		}
+	}
+}
+
+func TestExtractArchive_zipMultipleFiles(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	zipPath := filepath.Join(td, "disks.zip")
+	f, _ := os.Create(zipPath)
+	zw := zip.NewWriter(f)
+	zw.Create("a.img")
+	zw.Create("b.img")
+	zw.Close()
+	f.Close()
+
+	dst := filepath.Join(td, "extracted")
+	if err := ExtractArchive(dst, zipPath, "zip"); err == nil {
+		t.Fatal("should error")
+	}
+	if _, err := os.Stat(dst); err == nil {
+		t.Fatal("should not create the destination")
+	}
+}
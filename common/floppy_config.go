@@ -5,13 +5,22 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode"
 
 	"github.com/mitchellh/packer/template/interpolate"
 )
 
+// The smallest and largest floppy sizes in kilobytes.
+const (
+	MinFloppySize = 1440
+	MaxFloppySize = 2097152
+)
+
 type FloppyConfig struct {
 	FloppyFiles       []string `mapstructure:"floppy_files"`
 	FloppyDirectories []string `mapstructure:"floppy_dirs"`
+	FloppyLabel       string   `mapstructure:"floppy_label"`
+	FloppySize        int      `mapstructure:"floppy_size"`
 }
 
 func (c *FloppyConfig) Prepare(ctx *interpolate.Context) []error {
@@ -48,5 +57,26 @@ func (c *FloppyConfig) Prepare(ctx *interpolate.Context) []error {
 		}
 	}
 
+	if c.FloppyLabel == "" {
+		c.FloppyLabel = "packer"
+	}
+	if len(c.FloppyLabel) > 11 {
+		errs = append(errs, fmt.Errorf("floppy_label must be 11 characters or less"))
+	}
+	for _, r := range c.FloppyLabel {
+		if r > unicode.MaxASCII {
+			errs = append(errs, fmt.Errorf("floppy_label must only contain ASCII characters"))
+			break
+		}
+	}
+
+	if c.FloppySize == 0 {
+		c.FloppySize = MinFloppySize
+	}
+	if c.FloppySize < MinFloppySize || c.FloppySize > MaxFloppySize {
+		errs = append(errs, fmt.Errorf(
+			"floppy_size must be between %d and %d kilobytes", MinFloppySize, MaxFloppySize))
+	}
+
 	return errs
 }
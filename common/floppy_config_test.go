@@ -68,3 +68,29 @@ func TestMultiErrorFloppyFiles(t *testing.T) {
 		t.Fatalf("array with %v non existing floppy should return %v errors but it is returning %v", expectedErrors, expectedErrors, count)
 	}
 }
+
+func TestFloppyConfigLabelAndSize(t *testing.T) {
+	c := FloppyConfig{}
+	if errs := c.Prepare(nil); len(errs) != 0 {
+		t.Fatalf("bad: %#v", errs)
+	}
+	if c.FloppyLabel != "packer" || c.FloppySize != 1440 {
+		t.Fatalf("bad defaults: %q %d", c.FloppyLabel, c.FloppySize)
+	}
+
+	c = FloppyConfig{FloppyLabel: "OEMDRV", FloppySize: 2880}
+	if errs := c.Prepare(nil); len(errs) != 0 {
+		t.Fatalf("bad: %#v", errs)
+	}
+
+	for _, c := range []FloppyConfig{
+		{FloppyLabel: "thisistoolong"},
+		{FloppyLabel: "fl\u00f6ppy"},
+		{FloppySize: 720},
+		{FloppySize: MaxFloppySize + 1},
+	} {
+		if errs := c.Prepare(nil); len(errs) != 1 {
+			t.Fatalf("should have one error for %#v: %#v", c, errs)
+		}
+	}
+}
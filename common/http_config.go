@@ -2,6 +2,8 @@ package common
 
 import (
 	"errors"
+	"fmt"
+	"net"
 
 	"github.com/mitchellh/packer/template/interpolate"
 )
@@ -9,6 +11,7 @@ import (
 // HTTPConfig contains configuration for the local HTTP Server
 type HTTPConfig struct {
 	HTTPDir     string `mapstructure:"http_directory"`
+	HTTPAddress string `mapstructure:"http_bind_address"`
 	HTTPPortMin uint   `mapstructure:"http_port_min"`
 	HTTPPortMax uint   `mapstructure:"http_port_max"`
 }
@@ -17,6 +20,15 @@ func (c *HTTPConfig) Prepare(ctx *interpolate.Context) []error {
 	// Validation
 	var errs []error
 
+	if c.HTTPAddress == "" {
+		c.HTTPAddress = "0.0.0.0"
+	}
+
+	if net.ParseIP(c.HTTPAddress) == nil {
+		errs = append(errs,
+			fmt.Errorf("http_bind_address is not a valid IP address: %s", c.HTTPAddress))
+	}
+
 	if c.HTTPPortMin == 0 {
 		c.HTTPPortMin = 8000
 	}
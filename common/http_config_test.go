@@ -43,3 +43,23 @@ func TestHTTPConfigPrepare_Bounds(t *testing.T) {
 		t.Fatalf("should not have error: %s", err)
 	}
 }
+
+func TestHTTPConfigPrepare_Address(t *testing.T) {
+	h := HTTPConfig{}
+	if err := h.Prepare(nil); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if h.HTTPAddress != "0.0.0.0" {
+		t.Fatalf("bad: %s", h.HTTPAddress)
+	}
+
+	h = HTTPConfig{HTTPAddress: "127.0.0.1"}
+	if err := h.Prepare(nil); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	h = HTTPConfig{HTTPAddress: "localhost"}
+	if err := h.Prepare(nil); err == nil {
+		t.Fatal("should have error")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -16,13 +17,17 @@ import (
 
 // ISOConfig contains configuration for downloading ISO images.
 type ISOConfig struct {
-	ISOChecksum     string   `mapstructure:"iso_checksum"`
-	ISOChecksumURL  string   `mapstructure:"iso_checksum_url"`
-	ISOChecksumType string   `mapstructure:"iso_checksum_type"`
-	ISOUrls         []string `mapstructure:"iso_urls"`
-	TargetPath      string   `mapstructure:"iso_target_path"`
-	TargetExtension string   `mapstructure:"iso_target_extension"`
-	RawSingleISOUrl string   `mapstructure:"iso_url"`
+	ISOCAFile             string   `mapstructure:"iso_ca_file"`
+	ISOChecksum           string   `mapstructure:"iso_checksum"`
+	ISOChecksumURL        string   `mapstructure:"iso_checksum_url"`
+	ISOChecksumType       string   `mapstructure:"iso_checksum_type"`
+	ISOInsecureSkipVerify bool     `mapstructure:"iso_insecure_skip_verify"`
+	ISOLocalMode          string   `mapstructure:"iso_local_mode"`
+	ISOProbeMirrors       bool     `mapstructure:"iso_probe_mirrors"`
+	ISOUrls               []string `mapstructure:"iso_urls"`
+	TargetPath            string   `mapstructure:"iso_target_path"`
+	TargetExtension       string   `mapstructure:"iso_target_extension"`
+	RawSingleISOUrl       string   `mapstructure:"iso_url"`
 }
 
 func (c *ISOConfig) Prepare(ctx *interpolate.Context) (warnings []string, errs []error) {
@@ -38,6 +43,14 @@ func (c *ISOConfig) Prepare(ctx *interpolate.Context) (warnings []string, errs [
 		c.ISOUrls = []string{c.RawSingleISOUrl}
 	}
 
+	if c.ISOCAFile != "" {
+		if _, err := os.Stat(c.ISOCAFile); err != nil {
+			errs = append(
+				errs, fmt.Errorf("iso_ca_file is invalid: %s", err))
+			return
+		}
+	}
+
 	if c.ISOChecksumType == "" {
 		errs = append(
 			errs, errors.New("The iso_checksum_type must be specified."))
@@ -65,7 +78,13 @@ func (c *ISOConfig) Prepare(ctx *interpolate.Context) (warnings []string, errs [
 					}
 					switch u.Scheme {
 					case "http", "https":
-						res, err := http.Get(c.ISOChecksumURL)
+						transport, err := NewDownloadTransport(c.ISOCAFile, c.ISOInsecureSkipVerify)
+						if err != nil {
+							errs = append(errs, err)
+							return warnings, errs
+						}
+						client := &http.Client{Transport: transport}
+						res, err := client.Get(c.ISOChecksumURL)
 						c.ISOChecksum = ""
 						if err != nil {
 							errs = append(errs,
@@ -120,6 +139,14 @@ func (c *ISOConfig) Prepare(ctx *interpolate.Context) (warnings []string, errs [
 		}
 	}
 
+	if c.ISOLocalMode == "" {
+		c.ISOLocalMode = "reference"
+	}
+	if c.ISOLocalMode != "reference" && c.ISOLocalMode != "symlink" {
+		errs = append(errs, fmt.Errorf(
+			"iso_local_mode must be \"reference\" or \"symlink\", not %q", c.ISOLocalMode))
+	}
+
 	if c.TargetExtension == "" {
 		c.TargetExtension = "iso"
 	}
@@ -136,7 +163,8 @@ func (c *ISOConfig) Prepare(ctx *interpolate.Context) (warnings []string, errs [
 }
 
 func (c *ISOConfig) parseCheckSumFile(rd *bufio.Reader) error {
-	errNotFound := fmt.Errorf("No checksum for %q found at: %s", filepath.Base(c.ISOUrls[0]), c.ISOChecksumURL)
+	isoName := c.isoFileName()
+	errNotFound := fmt.Errorf("No checksum for %q found at: %s", isoName, c.ISOChecksumURL)
 	for {
 		line, err := rd.ReadString('\n')
 		if err != nil && line == "" {
@@ -148,7 +176,10 @@ func (c *ISOConfig) parseCheckSumFile(rd *bufio.Reader) error {
 		}
 		if strings.ToLower(parts[0]) == c.ISOChecksumType {
 			// BSD-style checksum
-			if parts[1] == fmt.Sprintf("(%s)", filepath.Base(c.ISOUrls[0])) {
+			if len(parts) < 4 || !strings.HasPrefix(parts[1], "(") || !strings.HasSuffix(parts[1], ")") {
+				continue
+			}
+			if checksumEntryName(parts[1][1:len(parts[1])-1]) == isoName {
 				c.ISOChecksum = parts[3]
 				return nil
 			}
@@ -158,7 +189,7 @@ func (c *ISOConfig) parseCheckSumFile(rd *bufio.Reader) error {
 				// Binary mode
 				parts[1] = parts[1][1:]
 			}
-			if parts[1] == filepath.Base(c.ISOUrls[0]) {
+			if checksumEntryName(parts[1]) == isoName {
 				c.ISOChecksum = parts[0]
 				return nil
 			}
@@ -166,3 +197,21 @@ func (c *ISOConfig) parseCheckSumFile(rd *bufio.Reader) error {
 	}
 	return errNotFound
 }
+
+// isoFileName returns the name of the ISO file as it appears in checksum
+// files, without the query string or fragment of the ISO URL.
+func (c *ISOConfig) isoFileName() string {
+	name := c.ISOUrls[0]
+	if u, err := url.Parse(name); err == nil && u.Path != "" {
+		name = u.Path
+	}
+
+	return path.Base(filepath.ToSlash(name))
+}
+
+// checksumEntryName returns the file name of an entry in a checksum file,
+// which may be given relative to the directory of the checksum file, such
+// as "./the-OS.iso".
+func checksumEntryName(entry string) string {
+	return path.Base(filepath.ToSlash(entry))
+}
@@ -29,6 +29,16 @@ bAr0 *the-OS.iso
 baZ0  other.iso
 `
 
+var cs_relative_style = `
+bAr1  ./other.iso
+baZ1  ./the-OS.iso
+`
+
+var cs_relative_bsd_style = `
+SHA256 (./other.iso) = bAr
+SHA256 (./the-OS.iso) = qUx
+`
+
 var cs_bsd_style_no_newline = `
 MD5 (other.iso) = bAr
 MD5 (the-OS.iso) = baZ`
@@ -152,6 +162,43 @@ func TestISOConfigPrepare_ISOChecksumURL(t *testing.T) {
 		t.Fatalf("should've found \"bar0\" got: %s", i.ISOChecksum)
 	}
 
+	// Test good - ISOChecksumURL with relative entries and a query string
+	i = testISOConfig()
+	i.ISOChecksum = ""
+	i.RawSingleISOUrl = "http://www.packer.io/the-OS.iso?mirror=1"
+	cs_file, _ = ioutil.TempFile("", "packer-test-")
+	ioutil.WriteFile(cs_file.Name(), []byte(cs_relative_style), 0666)
+	i.ISOChecksumURL = fmt.Sprintf("%s%s", filePrefix, cs_file.Name())
+	warns, err = i.Prepare(nil)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	if i.ISOChecksum != "baz1" {
+		t.Fatalf("should've found \"baz1\" got: %s", i.ISOChecksum)
+	}
+
+	// Test good - ISOChecksumURL BSD style with relative entries
+	i = testISOConfig()
+	i.ISOChecksum = ""
+	i.ISOChecksumType = "sha256"
+	cs_file, _ = ioutil.TempFile("", "packer-test-")
+	ioutil.WriteFile(cs_file.Name(), []byte(cs_relative_bsd_style), 0666)
+	i.ISOChecksumURL = fmt.Sprintf("%s%s", filePrefix, cs_file.Name())
+	warns, err = i.Prepare(nil)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	if i.ISOChecksum != "qux" {
+		t.Fatalf("should've found \"qux\" got: %s", i.ISOChecksum)
+	}
 }
 
 func TestISOConfigPrepare_ISOChecksumType(t *testing.T) {
@@ -316,3 +363,42 @@ func TestISOConfigPrepare_TargetExtension(t *testing.T) {
 		t.Fatalf("should've lowercased: %s", i.TargetExtension)
 	}
 }
+
+func TestISOConfigPrepare_ISOLocalMode(t *testing.T) {
+	i := testISOConfig()
+
+	// Test the default value
+	warns, err := i.Prepare(nil)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	if i.ISOLocalMode != "reference" {
+		t.Fatalf("should've found \"reference\" got: %s", i.ISOLocalMode)
+	}
+
+	// Test a good value
+	i = testISOConfig()
+	i.ISOLocalMode = "symlink"
+	warns, err = i.Prepare(nil)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	// Test a bad value
+	i = testISOConfig()
+	i.ISOLocalMode = "copy"
+	warns, err = i.Prepare(nil)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
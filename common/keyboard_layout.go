@@ -0,0 +1,196 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// KeyStroke is how a character is typed on a keyboard layout.
+type KeyStroke struct {
+	// Key is the character on the same physical key of a US keyboard,
+	// without any modifiers.
+	Key rune
+
+	// Shift and AltGr are the modifiers to hold while pressing the key.
+	Shift bool
+	AltGr bool
+
+	// Dead is true if the key is a dead key, which only produces the
+	// character once it is followed by a space.
+	Dead bool
+}
+
+// KeyISO is the Key of the key left of Z on ISO keyboards, which US
+// keyboards don't have.
+const KeyISO rune = -1
+
+// KeyboardLayout maps characters to the keystrokes that type them on a
+// keyboard layout other than the US one. Characters that aren't in the
+// layout are typed as on a US keyboard.
+type KeyboardLayout map[rune]KeyStroke
+
+// usShiftedChars are the characters other than upper case letters that
+// are typed with shift on a US keyboard.
+const usShiftedChars = "~!@#$%^&*()_+{}|:\"<>?"
+
+// Stroke returns the keystroke that types r on the layout. Characters that
+// aren't in the layout are typed with the same key as on a US keyboard.
+func (l KeyboardLayout) Stroke(r rune) KeyStroke {
+	if stroke, ok := l[r]; ok {
+		return stroke
+	}
+
+	return KeyStroke{
+		Key:   r,
+		Shift: unicode.IsUpper(r) || strings.ContainsRune(usShiftedChars, r),
+	}
+}
+
+// bootCommandSpecialRe matches the special codes and the templates of boot
+// commands, which aren't typed as they are.
+var bootCommandSpecialRe = regexp.MustCompile(`<[^<>\s]+>|{{[^}]*}}`)
+
+// CheckBootCommand returns an error if the boot commands contain a
+// character that can't be typed on the layout. Over VNC, characters of the
+// key left of Z can't be typed either, since VNC sends the characters of a
+// US keyboard, which doesn't have that key.
+func (l KeyboardLayout) CheckBootCommand(commands []string, vnc bool) error {
+	if l == nil {
+		return nil
+	}
+
+	for _, command := range commands {
+		for _, r := range bootCommandSpecialRe.ReplaceAllString(command, "") {
+			if r == ' ' || unicode.IsControl(r) {
+				continue
+			}
+
+			stroke, ok := l[r]
+			if !ok {
+				return fmt.Errorf("'%c' can't be typed on the keyboard layout", r)
+			}
+			if vnc && stroke.Key == KeyISO {
+				return fmt.Errorf(
+					"'%c' is on the key left of Z, which can't be typed over VNC", r)
+			}
+		}
+	}
+
+	return nil
+}
+
+// usKeyboardRows are the rows of a US keyboard, without any modifiers.
+// The keyboard layouts are described as the characters on the same
+// physical keys.
+var usKeyboardRows = []string{
+	"`1234567890-=",
+	"qwertyuiop[]",
+	"asdfghjkl;'\\",
+	"zxcvbnm,./",
+}
+
+// keyboardLayoutRow is a row of a keyboard layout, without modifiers, with
+// shift and with AltGr. Keys that don't type anything with a modifier are
+// spaces.
+type keyboardLayoutRow struct {
+	normal, shift, altGr string
+}
+
+// keyboardLayoutDef is a keyboard layout, with the key left of Z of ISO
+// keyboards as iso.
+type keyboardLayoutDef struct {
+	rows []keyboardLayoutRow
+	iso  keyboardLayoutRow
+	dead string
+}
+
+var keyboardLayouts = map[string]keyboardLayoutDef{
+	"de": {
+		rows: []keyboardLayoutRow{
+			{"^1234567890ß´", "°!\"§$%&/()=?`", "  ²³   {[]}\\ "},
+			{"qwertzuiopü+", "QWERTZUIOPÜ*", "@ €        ~"},
+			{"asdfghjklöä#", "ASDFGHJKLÖÄ'", ""},
+			{"yxcvbnm,.-", "YXCVBNM;:_", "      µ   "},
+		},
+		iso:  keyboardLayoutRow{"<", ">", "|"},
+		dead: "^´`",
+	},
+	"fr": {
+		rows: []keyboardLayoutRow{
+			{"²&é\"'(-è_çà)=", " 1234567890°+", "  ~#{[|`\\^@]}"},
+			{"azertyuiop^$", "AZERTYUIOP¨£", "  €        ¤"},
+			{"qsdfghjklmù*", "QSDFGHJKLM%µ", ""},
+			{"wxcvbn,;:!", "WXCVBN?./§", ""},
+		},
+		iso:  keyboardLayoutRow{"<", ">", ""},
+		dead: "^¨",
+	},
+	"sv": {
+		rows: []keyboardLayoutRow{
+			{"§1234567890+´", "½!\"#¤%&/()=?`", "  @£$€ {[]}\\ "},
+			{"qwertyuiopå¨", "QWERTYUIOPÅ^", "           ~"},
+			{"asdfghjklöä'", "ASDFGHJKLÖÄ*", ""},
+			{"zxcvbnm,.-", "ZXCVBNM;:_", "      µ   "},
+		},
+		iso:  keyboardLayoutRow{"<", ">", "|"},
+		dead: "´`¨^~",
+	},
+}
+
+// NewKeyboardLayout returns the keyboard layout with the given name. The
+// US layout, which is also used if the name is empty, is nil since
+// nothing has to be translated.
+func NewKeyboardLayout(name string) (KeyboardLayout, error) {
+	if name == "" || name == "us" {
+		return nil, nil
+	}
+
+	def, ok := keyboardLayouts[name]
+	if !ok {
+		names := []string{"us"}
+		for n := range keyboardLayouts {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+
+		return nil, fmt.Errorf(
+			"unknown keyboard layout %q, valid layouts: %s",
+			name, strings.Join(names, ", "))
+	}
+
+	layout := make(KeyboardLayout)
+	add := func(us []rune, chars string, shift, altGr bool) {
+		for i, r := range []rune(chars) {
+			if r == ' ' || i >= len(us) {
+				continue
+			}
+			if _, ok := layout[r]; ok {
+				continue
+			}
+
+			layout[r] = KeyStroke{
+				Key:   us[i],
+				Shift: shift,
+				AltGr: altGr,
+				Dead:  strings.ContainsRune(def.dead, r),
+			}
+		}
+	}
+
+	for i, row := range def.rows {
+		us := []rune(usKeyboardRows[i])
+		add(us, row.normal, false, false)
+		add(us, row.shift, true, false)
+		add(us, row.altGr, false, true)
+	}
+
+	iso := []rune{KeyISO}
+	add(iso, def.iso.normal, false, false)
+	add(iso, def.iso.shift, true, false)
+	add(iso, def.iso.altGr, false, true)
+
+	return layout, nil
+}
@@ -0,0 +1,109 @@
+package common
+
+import (
+	"testing"
+)
+
+func TestNewKeyboardLayout(t *testing.T) {
+	for _, name := range []string{"", "us"} {
+		layout, err := NewKeyboardLayout(name)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if layout != nil {
+			t.Fatalf("bad: %#v", layout)
+		}
+	}
+
+	if _, err := NewKeyboardLayout("xx"); err == nil {
+		t.Fatal("should error")
+	}
+
+	cases := []struct {
+		Layout   string
+		Char     rune
+		Expected KeyStroke
+	}{
+		{"de", 'y', KeyStroke{Key: 'z'}},
+		{"de", 'Z', KeyStroke{Key: 'y', Shift: true}},
+		{"de", '/', KeyStroke{Key: '7', Shift: true}},
+		{"de", '-', KeyStroke{Key: '/'}},
+		{"de", '@', KeyStroke{Key: 'q', AltGr: true}},
+		{"de", '\\', KeyStroke{Key: '-', AltGr: true}},
+		{"de", '^', KeyStroke{Key: '`', Dead: true}},
+		{"fr", 'a', KeyStroke{Key: 'q'}},
+		{"fr", '1', KeyStroke{Key: '1', Shift: true}},
+		{"fr", '.', KeyStroke{Key: ',', Shift: true}},
+		{"fr", '@', KeyStroke{Key: '0', AltGr: true}},
+		{"sv", '"', KeyStroke{Key: '2', Shift: true}},
+		{"sv", '{', KeyStroke{Key: '7', AltGr: true}},
+		{"sv", '~', KeyStroke{Key: ']', AltGr: true, Dead: true}},
+	}
+
+	for _, tc := range cases {
+		layout, err := NewKeyboardLayout(tc.Layout)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		actual, ok := layout[tc.Char]
+		if !ok {
+			t.Fatalf("%s: '%c' not found", tc.Layout, tc.Char)
+		}
+		if actual != tc.Expected {
+			t.Fatalf("%s: '%c': bad: %#v", tc.Layout, tc.Char, actual)
+		}
+	}
+
+	// The key left of Z of ISO keyboards
+	layout, _ := NewKeyboardLayout("de")
+	if actual := layout['|']; actual != (KeyStroke{Key: KeyISO, AltGr: true}) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestKeyboardLayoutStroke(t *testing.T) {
+	var us KeyboardLayout
+	if actual := us.Stroke('a'); actual != (KeyStroke{Key: 'a'}) {
+		t.Fatalf("bad: %#v", actual)
+	}
+	if actual := us.Stroke('A'); actual != (KeyStroke{Key: 'A', Shift: true}) {
+		t.Fatalf("bad: %#v", actual)
+	}
+	if actual := us.Stroke('>'); actual != (KeyStroke{Key: '>', Shift: true}) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	de, _ := NewKeyboardLayout("de")
+	if actual := de.Stroke('y'); actual != (KeyStroke{Key: 'z'}) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestKeyboardLayoutCheckBootCommand(t *testing.T) {
+	var us KeyboardLayout
+	if err := us.CheckBootCommand([]string{"é<enter>"}, true); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	de, _ := NewKeyboardLayout("de")
+	command := []string{
+		"<esc><wait10>linux ks=http://{{ .HTTPIP }}:{{ .HTTPPort }}/ks.cfg<enter>",
+	}
+	if err := de.CheckBootCommand(command, true); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Characters the layout doesn't have
+	if err := de.CheckBootCommand([]string{"é"}, false); err == nil {
+		t.Fatal("should error")
+	}
+
+	// The key left of Z can only be typed without VNC
+	if err := de.CheckBootCommand([]string{"a | b"}, false); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := de.CheckBootCommand([]string{"a | b"}, true); err == nil {
+		t.Fatal("should error")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,7 +30,17 @@ func newRunner(steps []multistep.Step, config PackerConfig, ui packer.Ui) (multi
 		pauseFn := MultistepDebugFn(ui)
 		return &multistep.DebugRunner{Steps: steps, PauseFn: pauseFn}, pauseFn
 	} else {
-		return &multistep.BasicRunner{Steps: steps}, nil
+		// The debug runner shows the type of each step, so the steps are
+		// only wrapped to report their progress when not debugging.
+		timings := new([]stepTiming)
+		for i, step := range steps {
+			steps[i] = progressStep{step, ui, timings}
+		}
+		return &timingRunner{
+			Runner:  &multistep.BasicRunner{Steps: steps},
+			ui:      ui,
+			timings: timings,
+		}, nil
 	}
 }
 
@@ -56,6 +67,103 @@ func typeName(i interface{}) string {
 	return reflect.Indirect(reflect.ValueOf(i)).Type().Name()
 }
 
+// stepTiming is how long a single step took to run.
+type stepTiming struct {
+	name     string
+	duration time.Duration
+}
+
+// timingRunner prints how long each step took once all steps have run.
+type timingRunner struct {
+	multistep.Runner
+	ui      packer.Ui
+	timings *[]stepTiming
+}
+
+func (r *timingRunner) Run(state multistep.StateBag) {
+	r.Runner.Run(state)
+	reportTimings(r.ui, *r.timings)
+}
+
+// reportTimings prints a table of the step durations along with their share
+// of the total time, and reports each of them as machine-readable output.
+func reportTimings(ui packer.Ui, timings []stepTiming) {
+	if len(timings) == 0 {
+		return
+	}
+
+	var total time.Duration
+	width := len("Total")
+	for _, t := range timings {
+		total += t.duration
+		if len(t.name) > width {
+			width = len(t.name)
+		}
+	}
+
+	lines := []string{"Step timings:"}
+	for _, t := range timings {
+		percent := 0.0
+		if total > 0 {
+			percent = 100 * float64(t.duration) / float64(total)
+		}
+		lines = append(lines, fmt.Sprintf("  %-*s  %10s  %5.1f%%",
+			width, t.name, t.duration/time.Millisecond*time.Millisecond, percent))
+		ui.Machine("step-timing", t.name,
+			strconv.FormatFloat(t.duration.Seconds(), 'f', 3, 64),
+			strconv.FormatFloat(percent, 'f', 1, 64))
+	}
+	lines = append(lines, fmt.Sprintf("  %-*s  %10s",
+		width, "Total", total/time.Millisecond*time.Millisecond))
+	ui.Machine("step-timing-total",
+		strconv.FormatFloat(total.Seconds(), 'f', 3, 64))
+
+	ui.Message(strings.Join(lines, "\n"))
+}
+
+// progressStep reports the start and the result of a step as
+// machine-readable output, and records how long the step took.
+type progressStep struct {
+	step    multistep.Step
+	ui      packer.Ui
+	timings *[]stepTiming
+}
+
+func (s progressStep) Run(state multistep.StateBag) multistep.StepAction {
+	name := typeName(unwrapStep(s.step))
+	s.ui.Machine("step-started", name)
+
+	start := time.Now()
+	action := s.step.Run(state)
+
+	duration := time.Since(start)
+	*s.timings = append(*s.timings, stepTiming{name, duration})
+
+	result := "continue"
+	if action == multistep.ActionHalt {
+		result = "halt"
+	}
+	s.ui.Machine("step-finished", name, result,
+		strconv.FormatFloat(duration.Seconds(), 'f', 3, 64))
+
+	return action
+}
+
+func (s progressStep) Cleanup(state multistep.StateBag) {
+	s.step.Cleanup(state)
+}
+
+// unwrapStep returns the step wrapped for -on-error handling.
+func unwrapStep(step multistep.Step) multistep.Step {
+	switch s := step.(type) {
+	case abortStep:
+		return s.step
+	case askStep:
+		return s.step
+	}
+	return step
+}
+
 type abortStep struct {
 	step multistep.Step
 	ui   packer.Ui
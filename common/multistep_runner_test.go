@@ -0,0 +1,86 @@
+package common
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/multistep"
+	"github.com/mitchellh/packer/packer"
+)
+
+type runnerTestStep struct {
+	action multistep.StepAction
+}
+
+func (s *runnerTestStep) Run(multistep.StateBag) multistep.StepAction {
+	return s.action
+}
+
+func (s *runnerTestStep) Cleanup(multistep.StateBag) {}
+
+func TestNewRunner_progress(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ui := &packer.MachineReadableUi{Writer: buf}
+
+	steps := []multistep.Step{
+		&runnerTestStep{multistep.ActionContinue},
+		&runnerTestStep{multistep.ActionHalt},
+	}
+	runner := NewRunner(steps, PackerConfig{}, ui)
+	runner.Run(new(multistep.BasicStateBag))
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		lines = append(lines, strings.SplitN(line, ",", 2)[1])
+	}
+
+	if len(lines) != 8 {
+		t.Fatalf("bad: %#v", lines)
+	}
+	if lines[0] != ",step-started,runnerTestStep" {
+		t.Fatalf("bad: %s", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], ",step-finished,runnerTestStep,continue,") {
+		t.Fatalf("bad: %s", lines[1])
+	}
+	if !strings.HasPrefix(lines[3], ",step-finished,runnerTestStep,halt,") {
+		t.Fatalf("bad: %s", lines[3])
+	}
+	if !strings.HasPrefix(lines[4], ",step-timing,runnerTestStep,") {
+		t.Fatalf("bad: %s", lines[4])
+	}
+	if !strings.HasPrefix(lines[6], ",step-timing-total,") {
+		t.Fatalf("bad: %s", lines[6])
+	}
+	if !strings.HasPrefix(lines[7], ",ui,message,Step timings:") {
+		t.Fatalf("bad: %s", lines[7])
+	}
+}
+
+func TestReportTimings(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ui := &packer.BasicUi{Reader: new(bytes.Buffer), Writer: buf}
+
+	reportTimings(ui, []stepTiming{
+		{"StepDownload", 3 * time.Second},
+		{"StepProvision", time.Second},
+	})
+
+	expected := `Step timings:
+  StepDownload           3s   75.0%
+  StepProvision          1s   25.0%
+  Total                  4s
+`
+	if buf.String() != expected {
+		t.Fatalf("bad: %q", buf.String())
+	}
+
+	// Nothing is reported without any steps
+	buf.Reset()
+	reportTimings(ui, nil)
+	if buf.Len() != 0 {
+		t.Fatalf("bad: %q", buf.String())
+	}
+}
@@ -0,0 +1,34 @@
+package common
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"strconv"
+)
+
+// ListenRange finds a free TCP port between min and max, inclusive, on the
+// given address and returns a listener that holds it.
+//
+// The ports are tried in a random order so that parallel builds are
+// unlikely to race for the same port, and every port is tried only once.
+// Callers should keep the listener open until the port is handed to its
+// consumer, so no other process can take the port in the meantime.
+func ListenRange(addr string, min, max uint) (net.Listener, uint, error) {
+	if min > max {
+		return nil, 0, fmt.Errorf("invalid port range: %d-%d", min, max)
+	}
+
+	for _, offset := range rand.Perm(int(max-min) + 1) {
+		port := min + uint(offset)
+		log.Printf("Trying port: %d", port)
+		l, err := net.Listen("tcp", net.JoinHostPort(addr, strconv.Itoa(int(port))))
+		if err == nil {
+			return l, port, nil
+		}
+	}
+
+	return nil, 0, fmt.Errorf(
+		"no free port between %d and %d on %s", min, max, addr)
+}
@@ -0,0 +1,29 @@
+package common
+
+import (
+	"testing"
+)
+
+func TestListenRange(t *testing.T) {
+	l, port, err := ListenRange("127.0.0.1", 18000, 19000)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer l.Close()
+
+	if port < 18000 || port > 19000 {
+		t.Fatalf("bad: %d", port)
+	}
+
+	// The port is held, so a range of just that port must fail
+	if l2, _, err := ListenRange("127.0.0.1", port, port); err == nil {
+		l2.Close()
+		t.Fatal("should error")
+	}
+}
+
+func TestListenRange_invalid(t *testing.T) {
+	if _, _, err := ListenRange("127.0.0.1", 2, 1); err == nil {
+		t.Fatal("should error")
+	}
+}
@@ -2,7 +2,9 @@ package common
 
 import (
 	"fmt"
+	"log"
 	"math"
+	"math/rand"
 	"time"
 )
 
@@ -42,3 +44,58 @@ func Retry(initialInterval float64, maxInterval float64, numTries uint, function
 	}
 	return nil
 }
+
+// RetryConfig configures retrying a function with exponential backoff.
+type RetryConfig struct {
+	// Tries is the maximum number of attempts. Zero means the function is
+	// retried indefinitely.
+	Tries uint
+
+	// InitialBackoff is the delay after the first failed attempt. The
+	// delay doubles after every further failure, up to MaxBackoff if it
+	// is set.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// Jitter randomizes every delay by up to this fraction of it, so
+	// 0.2 gives delays of 80% to 120% of the backoff. This keeps parallel
+	// builds from retrying in lockstep.
+	Jitter float64
+
+	// ShouldRetry reports whether an error should be retried. If it is
+	// nil, every error is retried.
+	ShouldRetry func(error) bool
+}
+
+// retrySleep is time.Sleep, replaceable for tests.
+var retrySleep = time.Sleep
+
+// Run calls f until it succeeds, fails with an error that should not be
+// retried or runs out of tries. The last error of f is returned.
+func (c *RetryConfig) Run(f func() error) error {
+	backoff := c.InitialBackoff
+	for try := uint(1); ; try++ {
+		err := f()
+		if err == nil {
+			return nil
+		}
+		if c.ShouldRetry != nil && !c.ShouldRetry(err) {
+			return err
+		}
+		if c.Tries > 0 && try >= c.Tries {
+			return err
+		}
+
+		delay := backoff
+		if c.Jitter > 0 {
+			delay += time.Duration(c.Jitter * (2*rand.Float64() - 1) * float64(delay))
+		}
+		log.Printf("Retrying in %s after error: %s", delay, err)
+		retrySleep(delay)
+
+		backoff *= 2
+		if c.MaxBackoff > 0 && backoff > c.MaxBackoff {
+			backoff = c.MaxBackoff
+		}
+	}
+}
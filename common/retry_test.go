@@ -1,8 +1,11 @@
 package common
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestRetry(t *testing.T) {
@@ -62,3 +65,72 @@ func TestRetry(t *testing.T) {
 		t.Fatalf("Unsuccessful retry function should have returned a retry exhausted error. Actual error: %s", err)
 	}
 }
+
+func TestRetryConfig(t *testing.T) {
+	var delays []time.Duration
+	retrySleep = func(d time.Duration) { delays = append(delays, d) }
+	defer func() { retrySleep = time.Sleep }()
+
+	tries := 0
+	c := &RetryConfig{
+		InitialBackoff: time.Second,
+		MaxBackoff:     3 * time.Second,
+	}
+	err := c.Run(func() error {
+		tries++
+		if tries < 4 {
+			return errors.New("fail")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}
+	if !reflect.DeepEqual(delays, expected) {
+		t.Fatalf("bad: %#v", delays)
+	}
+}
+
+func TestRetryConfig_tries(t *testing.T) {
+	retrySleep = func(time.Duration) {}
+	defer func() { retrySleep = time.Sleep }()
+
+	tries := 0
+	c := &RetryConfig{Tries: 3, Jitter: 0.5}
+	err := c.Run(func() error {
+		tries++
+		return fmt.Errorf("fail %d", tries)
+	})
+	if tries != 3 {
+		t.Fatalf("bad: %d", tries)
+	}
+	if err == nil || err.Error() != "fail 3" {
+		t.Fatalf("bad: %s", err)
+	}
+}
+
+func TestRetryConfig_shouldRetry(t *testing.T) {
+	retrySleep = func(time.Duration) {}
+	defer func() { retrySleep = time.Sleep }()
+
+	permanent := errors.New("permanent")
+	tries := 0
+	c := &RetryConfig{
+		ShouldRetry: func(err error) bool { return err != permanent },
+	}
+	err := c.Run(func() error {
+		tries++
+		if tries < 2 {
+			return errors.New("temporary")
+		}
+		return permanent
+	})
+	if tries != 2 {
+		t.Fatalf("bad: %d", tries)
+	}
+	if err != permanent {
+		t.Fatalf("bad: %s", err)
+	}
+}
@@ -0,0 +1,26 @@
+package ssh
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// AgentAuth returns an ssh.AuthMethod that authenticates with the keys
+// of the local SSH agent pointed to by SSH_AUTH_SOCK.
+func AgentAuth() (ssh.AuthMethod, error) {
+	authSock := os.Getenv("SSH_AUTH_SOCK")
+	if authSock == "" {
+		return nil, fmt.Errorf("SSH_AUTH_SOCK is not set")
+	}
+
+	sshAgent, err := net.Dial("unix", authSock)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot connect to SSH Agent socket %q: %s", authSock, err)
+	}
+
+	return ssh.PublicKeysCallback(agent.NewClient(sshAgent).Signers), nil
+}
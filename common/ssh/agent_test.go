@@ -0,0 +1,46 @@
+package ssh
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAgentAuth_noSocket(t *testing.T) {
+	old := os.Getenv("SSH_AUTH_SOCK")
+	defer os.Setenv("SSH_AUTH_SOCK", old)
+
+	os.Setenv("SSH_AUTH_SOCK", "")
+	if _, err := AgentAuth(); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestAgentAuth(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	sock := filepath.Join(td, "agent.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer l.Close()
+
+	old := os.Getenv("SSH_AUTH_SOCK")
+	defer os.Setenv("SSH_AUTH_SOCK", old)
+
+	os.Setenv("SSH_AUTH_SOCK", sock)
+	auth, err := AgentAuth()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if auth == nil {
+		t.Fatal("should have auth method")
+	}
+}
@@ -1,6 +1,7 @@
 package ssh
 
 import (
+	"crypto/x509"
 	"encoding/pem"
 	"fmt"
 	"io/ioutil"
@@ -11,6 +12,12 @@ import (
 
 // FileSigner returns an ssh.Signer for a key file.
 func FileSigner(path string) (ssh.Signer, error) {
+	return EncryptedFileSigner(path, "")
+}
+
+// EncryptedFileSigner returns an ssh.Signer for a key file that may be
+// protected with the given passphrase.
+func EncryptedFileSigner(path, passphrase string) (ssh.Signer, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -22,17 +29,43 @@ func FileSigner(path string) (ssh.Signer, error) {
 		return nil, err
 	}
 
+	return encryptedSigner(fmt.Sprintf("'%s'", path), keyBytes, passphrase)
+}
+
+// EncryptedSigner returns an ssh.Signer for a PEM encoded key that may be
+// protected with the given passphrase. Builders use it for keys they keep
+// in their state, which are either generated or read from
+// ssh_private_key_file.
+func EncryptedSigner(keyBytes []byte, passphrase string) (ssh.Signer, error) {
+	return encryptedSigner("ssh_private_key_file", keyBytes, passphrase)
+}
+
+func encryptedSigner(name string, keyBytes []byte, passphrase string) (ssh.Signer, error) {
 	// We parse the private key on our own first so that we can
 	// show a nicer error if the private key has a password.
 	block, _ := pem.Decode(keyBytes)
 	if block == nil {
 		return nil, fmt.Errorf(
-			"Failed to read key '%s': no key found", path)
+			"Failed to read key %s: no key found", name)
 	}
-	if block.Headers["Proc-Type"] == "4,ENCRYPTED" {
-		return nil, fmt.Errorf(
-			"Failed to read key '%s': password protected keys are\n"+
-				"not supported. Please decrypt the key prior to use.", path)
+	if x509.IsEncryptedPEMBlock(block) {
+		if passphrase == "" {
+			return nil, fmt.Errorf(
+				"Failed to read key %s: the key is password protected.\n"+
+					"Please set ssh_private_key_passphrase or decrypt the key\n"+
+					"prior to use.", name)
+		}
+
+		der, err := x509.DecryptPEMBlock(block, []byte(passphrase))
+		if err != nil {
+			return nil, fmt.Errorf(
+				"Failed to decrypt key %s: %s", name, err)
+		}
+
+		keyBytes = pem.EncodeToMemory(&pem.Block{
+			Type:  block.Type,
+			Bytes: der,
+		})
 	}
 
 	signer, err := ssh.ParsePrivateKey(keyBytes)
@@ -0,0 +1,80 @@
+package ssh
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func testEncryptedKeyFile(t *testing.T, passphrase string) string {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	block, err := x509.EncryptPEMBlock(rand.Reader, "RSA PRIVATE KEY",
+		x509.MarshalPKCS1PrivateKey(key), []byte(passphrase), x509.PEMCipherAES128)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	f, err := ioutil.TempFile("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer f.Close()
+
+	if err := pem.Encode(f, block); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return f.Name()
+}
+
+func TestEncryptedFileSigner(t *testing.T) {
+	path := testEncryptedKeyFile(t, "packer")
+	defer os.Remove(path)
+
+	if _, err := EncryptedFileSigner(path, "packer"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestEncryptedFileSigner_badPassphrase(t *testing.T) {
+	path := testEncryptedKeyFile(t, "packer")
+	defer os.Remove(path)
+
+	if _, err := EncryptedFileSigner(path, "wrong"); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestFileSigner_encrypted(t *testing.T) {
+	path := testEncryptedKeyFile(t, "packer")
+	defer os.Remove(path)
+
+	if _, err := FileSigner(path); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestEncryptedSigner(t *testing.T) {
+	path := testEncryptedKeyFile(t, "packer")
+	defer os.Remove(path)
+
+	keyBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, err := EncryptedSigner(keyBytes, "packer"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := EncryptedSigner(keyBytes, ""); err == nil {
+		t.Fatal("should error")
+	}
+}
@@ -20,6 +20,13 @@ type StepCreateFloppy struct {
 	Files       []string
 	Directories []string
 
+	// Label is the volume label of the floppy, "packer" by default.
+	Label string
+
+	// Size is the size of the floppy in kilobytes, 1440 by default. Sizes
+	// larger than a 2.88MB floppy are formatted as a FAT16 super floppy.
+	Size int
+
 	floppyPath string
 
 	FilesAdded map[string]bool
@@ -50,8 +57,17 @@ func (s *StepCreateFloppy) Run(state multistep.StateBag) multistep.StepAction {
 
 	log.Printf("Floppy path: %s", s.floppyPath)
 
+	size := s.Size
+	if size == 0 {
+		size = MinFloppySize
+	}
+	label := s.Label
+	if label == "" {
+		label = "packer"
+	}
+
 	// Set the size of the file to be a floppy sized
-	if err := floppyF.Truncate(1440 * 1024); err != nil {
+	if err := floppyF.Truncate(int64(size) * 1024); err != nil {
 		state.Put("error", fmt.Errorf("Error creating floppy: %s", err))
 		return multistep.ActionHalt
 	}
@@ -67,8 +83,8 @@ func (s *StepCreateFloppy) Run(state multistep.StateBag) multistep.StepAction {
 	// Format the block device so it contains a valid FAT filesystem
 	log.Println("Formatting the block device with a FAT filesystem...")
 	formatConfig := &fat.SuperFloppyConfig{
-		FATType: fat.FAT12,
-		Label:   "packer",
+		FATType: floppyFATType(size),
+		Label:   label,
 		OEMName: "packer",
 	}
 	if err := fat.FormatSuperFloppy(device, formatConfig); err != nil {
@@ -410,3 +426,14 @@ func fsDirectoryCache(rootDirectory fs.Directory) directoryCache {
 	}
 	return getFilesystemDirectory
 }
+
+// floppyFATType returns the FAT type to format a floppy of the given size
+// in kilobytes with. FAT16 needs more than 8400 sectors, and FAT12 works up
+// to that size.
+func floppyFATType(size int) fat.FATType {
+	if size*2 > 8400 {
+		return fat.FAT16
+	}
+
+	return fat.FAT12
+}
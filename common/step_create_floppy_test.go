@@ -294,3 +294,46 @@ func TestStepCreateFloppyDirectories(t *testing.T) {
 		}
 	}
 }
+
+func TestStepCreateFloppy_labelAndSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := path.Join(dir, "ks.cfg")
+	if err := ioutil.WriteFile(file, make([]byte, 2*1024*1024), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for _, size := range []int{2880, 8192} {
+		state := testStepCreateFloppyState(t)
+		step := &StepCreateFloppy{
+			Files: []string{file},
+			Label: "KICKSTART",
+			Size:  size,
+		}
+
+		if action := step.Run(state); action != multistep.ActionContinue {
+			t.Fatalf("bad action for size %d: %#v", size, state.Get("error"))
+		}
+
+		floppyPath := state.Get("floppy_path").(string)
+		contents, err := ioutil.ReadFile(floppyPath)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		step.Cleanup(state)
+
+		if len(contents) != size*1024 {
+			t.Fatalf("bad size: %d", len(contents))
+		}
+		if label := strings.TrimRight(string(contents[43:54]), "\x00 "); label != "KICKSTART" {
+			t.Fatalf("bad label for size %d: %q", size, label)
+		}
+		if !step.FilesAdded[file] {
+			t.Fatalf("file not added for size %d: %#v", size, step.FilesAdded)
+		}
+	}
+}
@@ -5,6 +5,14 @@ import (
 	"encoding/hex"
 	"fmt"
 	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/mitchellh/multistep"
@@ -38,11 +46,26 @@ type StepDownload struct {
 	// A list of URLs to attempt to download this thing.
 	Url []string
 
+	// LinkLocalFile, if true, symlinks local files into the cache or
+	// TargetPath instead of using them in place.
+	LinkLocalFile bool
+
+	// ProbeMirrors, if true, sends a request to every URL in parallel
+	// first and tries the URLs in order of how quickly they responded,
+	// instead of in the order they're given.
+	ProbeMirrors bool
+
 	// Extension is the extension to force for the file that is downloaded.
 	// Some systems require a certain extension. If this isn't set, the
 	// extension on the URL is used. Otherwise, this will be forced
 	// on the downloaded file for every URL.
 	Extension string
+
+	// CAFile is the path to a PEM encoded CA bundle to trust in addition
+	// to the system roots for HTTPS downloads, and InsecureSkipVerify
+	// disables verifying the server certificate entirely.
+	CAFile             string
+	InsecureSkipVerify bool
 }
 
 func (s *StepDownload) Run(state multistep.StateBag) multistep.StepAction {
@@ -61,22 +84,31 @@ func (s *StepDownload) Run(state multistep.StateBag) multistep.StepAction {
 
 	ui.Say(fmt.Sprintf("Downloading or copying %s", s.Description))
 
-	var finalPath string
-	for _, url := range s.Url {
+	urls := s.Url
+	if s.ProbeMirrors && len(urls) > 1 {
+		// A URL that is already in the cache doesn't have to be
+		// downloaded again, no matter how fast the other mirrors are.
+		cached, rest := s.cachedUrls(cache, urls, checksum)
+		if len(rest) > 1 {
+			ui.Message("Probing mirrors to find the fastest one...")
+			transport, err := NewDownloadTransport(s.CAFile, s.InsecureSkipVerify)
+			if err != nil {
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+			rest = orderByLatency(rest, transport, mirrorProbeTimeout)
+		}
+		urls = append(cached, rest...)
+	}
+
+	var finalPath, finalUrl string
+	for _, url := range urls {
 		ui.Message(fmt.Sprintf("Downloading or copying: %s", url))
 
 		targetPath := s.TargetPath
 		if targetPath == "" {
-			// Determine a cache key. This is normally just the URL but
-			// if we force a certain extension we hash the URL and add
-			// the extension to force it.
-			cacheKey := url
-			if s.Extension != "" {
-				hash := sha1.Sum([]byte(url))
-				cacheKey = fmt.Sprintf(
-					"%s.%s", hex.EncodeToString(hash[:]), s.Extension)
-			}
-
+			cacheKey := s.cacheKey(url)
 			log.Printf("Acquiring lock to download: %s", url)
 			targetPath = cache.Lock(cacheKey)
 			defer cache.Unlock(cacheKey)
@@ -86,9 +118,13 @@ func (s *StepDownload) Run(state multistep.StateBag) multistep.StepAction {
 			Url:        url,
 			TargetPath: targetPath,
 			CopyFile:   false,
+			LinkFile:   s.LinkLocalFile,
 			Hash:       HashForType(s.ChecksumType),
 			Checksum:   checksum,
 			UserAgent:  "Packer",
+
+			CAFile:             s.CAFile,
+			InsecureSkipVerify: s.InsecureSkipVerify,
 		}
 
 		path, err, retry := s.download(config, state)
@@ -102,6 +138,7 @@ func (s *StepDownload) Run(state multistep.StateBag) multistep.StepAction {
 
 		if err == nil {
 			finalPath = path
+			finalUrl = url
 			break
 		}
 	}
@@ -113,10 +150,99 @@ func (s *StepDownload) Run(state multistep.StateBag) multistep.StepAction {
 		return multistep.ActionHalt
 	}
 
+	// Compressed files are extracted into the cache, after the checksum
+	// of the compressed file has been verified.
+	if format, name := ArchiveFormat(finalUrl); format != "" {
+		path, err := s.extract(cache, ui, finalPath, name, format)
+		if err != nil {
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+		finalPath = path
+	}
+
 	state.Put(s.ResultKey, finalPath)
 	return multistep.ActionContinue
 }
 
+// cacheKey returns the key of the cache entry of the download of url. This
+// is normally just the URL but if we force a certain extension we hash the
+// URL and add the extension to force it.
+func (s *StepDownload) cacheKey(url string) string {
+	if s.Extension == "" {
+		return url
+	}
+
+	hash := sha1.Sum([]byte(url))
+	return fmt.Sprintf("%s.%s", hex.EncodeToString(hash[:]), s.Extension)
+}
+
+// cachedUrls splits urls into the ones whose cache entry already matches
+// the checksum and the rest, both in their original order.
+func (s *StepDownload) cachedUrls(cache packer.Cache, urls []string, checksum []byte) ([]string, []string) {
+	hash := HashForType(s.ChecksumType)
+	if s.TargetPath != "" || checksum == nil || hash == nil {
+		return nil, urls
+	}
+
+	var cached, rest []string
+	for _, url := range urls {
+		key := s.cacheKey(url)
+		path, ok := cache.RLock(key)
+		if !ok {
+			rest = append(rest, url)
+			continue
+		}
+
+		client := NewDownloadClient(&DownloadConfig{Hash: hash, Checksum: checksum})
+		verify, err := client.VerifyChecksum(path)
+		cache.RUnlock(key)
+
+		if err == nil && verify {
+			log.Printf("Found %s in the cache", url)
+			cached = append(cached, url)
+		} else {
+			rest = append(rest, url)
+		}
+	}
+
+	return cached, rest
+}
+
+// extract extracts the compressed file at path into the cache and returns
+// the path to the extracted file. The extracted file is reused as long as
+// it is newer than the compressed file.
+func (s *StepDownload) extract(cache packer.Cache, ui packer.Ui, path, name, format string) (string, error) {
+	ext := s.Extension
+	if ext == "" {
+		ext = strings.TrimPrefix(filepath.Ext(name), ".")
+	}
+	hash := sha1.Sum([]byte(name + "#extracted"))
+	cacheKey := hex.EncodeToString(hash[:])
+	if ext != "" {
+		cacheKey += "." + ext
+	}
+
+	log.Printf("Acquiring lock to extract: %s", name)
+	targetPath := cache.Lock(cacheKey)
+	defer cache.Unlock(cacheKey)
+
+	if archiveInfo, err := os.Stat(path); err == nil {
+		if info, err := os.Stat(targetPath); err == nil && !info.ModTime().Before(archiveInfo.ModTime()) {
+			ui.Message(fmt.Sprintf("Using previously extracted %s", s.Description))
+			return targetPath, nil
+		}
+	}
+
+	ui.Message(fmt.Sprintf("Extracting %s (%s)...", s.Description, format))
+	if err := ExtractArchive(targetPath, path, format); err != nil {
+		return "", err
+	}
+
+	return targetPath, nil
+}
+
 func (s *StepDownload) Cleanup(multistep.StateBag) {}
 
 func (s *StepDownload) download(config *DownloadConfig, state multistep.StateBag) (string, error, bool) {
@@ -133,6 +259,7 @@ func (s *StepDownload) download(config *DownloadConfig, state multistep.StateBag
 
 	progressTicker := time.NewTicker(5 * time.Second)
 	defer progressTicker.Stop()
+	progress := &downloadProgress{lastTime: time.Now()}
 
 	for {
 		select {
@@ -143,15 +270,151 @@ func (s *StepDownload) download(config *DownloadConfig, state multistep.StateBag
 
 			return path, nil, true
 		case <-progressTicker.C:
-			progress := download.PercentProgress()
-			if progress >= 0 {
-				ui.Message(fmt.Sprintf("Download progress: %d%%", progress))
+			percent := download.PercentProgress()
+			if percent >= 0 {
+				current, total := download.BytesProgress()
+				rate, remaining := progress.update(current, total, time.Now())
+				ui.Message(fmt.Sprintf(
+					"Download progress: %d%% (%s of %s, %s/s, %s remaining)",
+					percent, formatBytes(current), formatBytes(total),
+					formatBytes(uint(rate)), remaining))
+				ui.Machine("download-progress",
+					s.Description,
+					strconv.Itoa(percent),
+					strconv.FormatUint(uint64(current), 10),
+					strconv.FormatUint(uint64(total), 10),
+					strconv.FormatFloat(rate, 'f', 0, 64),
+					strconv.FormatInt(int64(remaining/time.Second), 10))
 			}
 		case <-time.After(1 * time.Second):
 			if _, ok := state.GetOk(multistep.StateCancelled); ok {
 				ui.Say("Interrupt received. Cancelling download...")
+				download.Cancel()
 				return "", nil, false
 			}
 		}
 	}
 }
+
+// mirrorProbeTimeout is how long to wait for a mirror to respond when
+// probing mirrors.
+const mirrorProbeTimeout = 10 * time.Second
+
+// orderByLatency returns the URLs ordered by how quickly they respond to a
+// HEAD request. URLs that aren't fetched over HTTP, such as local files,
+// come first, and URLs that fail to respond in time come last. URLs that
+// are otherwise equal keep their original order.
+func orderByLatency(urls []string, transport *http.Transport, timeout time.Duration) []string {
+	httpClient := &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+
+	probes := make([]mirrorProbe, len(urls))
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		probes[i].url = u
+
+		parsed, err := url.Parse(u)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			probes[i].ok = true
+			continue
+		}
+
+		wg.Add(1)
+		go func(p *mirrorProbe) {
+			defer wg.Done()
+
+			req, err := http.NewRequest("HEAD", p.url, nil)
+			if err != nil {
+				return
+			}
+			req.Header.Set("User-Agent", "Packer")
+
+			start := time.Now()
+			resp, err := httpClient.Do(req)
+			if err != nil {
+				log.Printf("Error probing mirror %s: %s", p.url, err)
+				return
+			}
+			resp.Body.Close()
+
+			p.latency = time.Since(start)
+			p.ok = resp.StatusCode >= 200 && resp.StatusCode < 300
+			log.Printf("Probed mirror %s: %s in %s", p.url, resp.Status, p.latency)
+		}(&probes[i])
+	}
+	wg.Wait()
+
+	sort.Stable(byLatency(probes))
+
+	result := make([]string, len(probes))
+	for i, p := range probes {
+		result[i] = p.url
+	}
+
+	return result
+}
+
+// mirrorProbe is the result of probing a single mirror.
+type mirrorProbe struct {
+	url     string
+	ok      bool
+	latency time.Duration
+}
+
+// byLatency sorts mirror probes with the mirrors that responded first,
+// fastest to slowest.
+type byLatency []mirrorProbe
+
+func (p byLatency) Len() int      { return len(p) }
+func (p byLatency) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p byLatency) Less(i, j int) bool {
+	if p[i].ok != p[j].ok {
+		return p[i].ok
+	}
+	return p[i].latency < p[j].latency
+}
+
+// downloadProgress keeps track of the progress of a download between
+// updates, to report the transfer rate and the time remaining.
+type downloadProgress struct {
+	lastBytes uint
+	lastTime  time.Time
+}
+
+// update records the current progress and returns the transfer rate in
+// bytes per second since the last update, along with the estimated time
+// until the download completes at that rate.
+func (p *downloadProgress) update(current, total uint, now time.Time) (float64, time.Duration) {
+	var rate float64
+	if elapsed := now.Sub(p.lastTime).Seconds(); elapsed > 0 && current >= p.lastBytes {
+		rate = float64(current-p.lastBytes) / elapsed
+	}
+	p.lastBytes = current
+	p.lastTime = now
+
+	var remaining time.Duration
+	if rate > 0 && total > current {
+		remaining = time.Duration(float64(total-current) / rate * float64(time.Second))
+		remaining -= remaining % time.Second
+	}
+
+	return rate, remaining
+}
+
+// formatBytes formats a number of bytes in a human readable way.
+func formatBytes(n uint) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := uint(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -1,8 +1,21 @@
 package common
 
 import (
-	"github.com/mitchellh/multistep"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/mitchellh/multistep"
+	"github.com/mitchellh/packer/packer"
 )
 
 func TestStepDownload_Impl(t *testing.T) {
@@ -12,3 +25,168 @@ func TestStepDownload_Impl(t *testing.T) {
 		t.Fatalf("download should be a step")
 	}
 }
+
+func TestDownloadProgress_update(t *testing.T) {
+	start := time.Now()
+	p := &downloadProgress{lastTime: start}
+
+	rate, remaining := p.update(10*1024*1024, 30*1024*1024, start.Add(5*time.Second))
+	if rate != 2*1024*1024 {
+		t.Fatalf("bad rate: %f", rate)
+	}
+	if remaining != 10*time.Second {
+		t.Fatalf("bad remaining: %s", remaining)
+	}
+
+	// Nothing downloaded since the last update
+	rate, remaining = p.update(10*1024*1024, 30*1024*1024, start.Add(10*time.Second))
+	if rate != 0 || remaining != 0 {
+		t.Fatalf("bad: %f %s", rate, remaining)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	cases := map[uint]string{
+		0:               "0 B",
+		1023:            "1023 B",
+		1024:            "1.0 KiB",
+		1536:            "1.5 KiB",
+		4 * 1024 * 1024: "4.0 MiB",
+		3 << 30:         "3.0 GiB",
+		5 << 29:         "2.5 GiB",
+	}
+
+	for n, expected := range cases {
+		if actual := formatBytes(n); actual != expected {
+			t.Fatalf("%d: expected %q, got %q", n, expected, actual)
+		}
+	}
+}
+
+func TestOrderByLatency(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer slow.Close()
+
+	fast := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer fast.Close()
+
+	broken := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(404)
+	}))
+	defer broken.Close()
+
+	urls := []string{
+		broken.URL + "/a.iso",
+		slow.URL + "/a.iso",
+		fast.URL + "/a.iso",
+		"file:///tmp/a.iso",
+	}
+	expected := []string{urls[3], urls[2], urls[1], urls[0]}
+
+	actual := orderByLatency(urls, new(http.Transport), 5*time.Second)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestStepDownload_probeMirrorsCached(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	var requests []string
+	fast := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method)
+		rw.Write([]byte("iso contents"))
+	}))
+	defer fast.Close()
+
+	// The cached mirror is gone, so the build fails if it is downloaded
+	gone := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	gone.Close()
+
+	cache := &packer.FileCache{CacheDir: filepath.Join(td, "cache")}
+	state := new(multistep.BasicStateBag)
+	state.Put("cache", cache)
+	state.Put("ui", &packer.BasicUi{
+		Reader: new(bytes.Buffer),
+		Writer: new(bytes.Buffer),
+	})
+
+	checksum := sha256.Sum256([]byte("iso contents"))
+	step := &StepDownload{
+		Checksum:     hex.EncodeToString(checksum[:]),
+		ChecksumType: "sha256",
+		Description:  "ISO",
+		Extension:    "iso",
+		ProbeMirrors: true,
+		ResultKey:    "iso_path",
+		Url:          []string{fast.URL + "/a.iso", gone.URL + "/a.iso"},
+	}
+
+	cachedPath := cache.Lock(step.cacheKey(gone.URL + "/a.iso"))
+	if err := ioutil.WriteFile(cachedPath, []byte("iso contents"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	cache.Unlock(step.cacheKey(gone.URL + "/a.iso"))
+
+	if action := step.Run(state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", state.Get("error"))
+	}
+	if path := state.Get("iso_path").(string); path != cachedPath {
+		t.Fatalf("bad path: %s", path)
+	}
+	if len(requests) != 0 {
+		t.Fatalf("mirror should not be used: %#v", requests)
+	}
+}
+
+func TestStepDownload_extract(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	src := filepath.Join(td, "the-OS.iso.gz")
+	f, _ := os.Create(src)
+	w := gzip.NewWriter(f)
+	w.Write([]byte("iso contents"))
+	w.Close()
+	f.Close()
+
+	state := new(multistep.BasicStateBag)
+	state.Put("cache", &packer.FileCache{CacheDir: filepath.Join(td, "cache")})
+	state.Put("ui", &packer.BasicUi{
+		Reader: new(bytes.Buffer),
+		Writer: new(bytes.Buffer),
+	})
+
+	step := &StepDownload{
+		ChecksumType: "none",
+		Description:  "ISO",
+		Extension:    "iso",
+		ResultKey:    "iso_path",
+		Url:          []string{"file://" + filepath.ToSlash(src)},
+	}
+	if action := step.Run(state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", state.Get("error"))
+	}
+
+	path := state.Get("iso_path").(string)
+	if filepath.Ext(path) != ".iso" || filepath.Dir(path) != filepath.Join(td, "cache") {
+		t.Fatalf("bad path: %s", path)
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(contents) != "iso contents" {
+		t.Fatalf("bad: %s", contents)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
-	"math/rand"
 	"net"
 	"net/http"
 	"os"
@@ -25,6 +24,7 @@ import (
 //   http_port int - The port the HTTP server started on.
 type StepHTTPServer struct {
 	HTTPDir     string
+	HTTPAddress string
 	HTTPPortMin uint
 	HTTPPortMax uint
 
@@ -40,33 +40,28 @@ func (s *StepHTTPServer) Run(state multistep.StateBag) multistep.StepAction {
 		return multistep.ActionContinue
 	}
 
-	// Find an available TCP port for our HTTP server
-	var httpAddr string
-	portRange := int(s.HTTPPortMax - s.HTTPPortMin)
-	for {
-		var err error
-		var offset uint = 0
-
-		if portRange > 0 {
-			// Intn will panic if portRange == 0, so we do a check.
-			// Intn is from [0, n), so add 1 to make from [0, n]
-			offset = uint(rand.Intn(portRange + 1))
-		}
+	bindAddress := s.HTTPAddress
+	if bindAddress == "" {
+		bindAddress = "0.0.0.0"
+	}
 
-		httpPort = offset + s.HTTPPortMin
-		httpAddr = fmt.Sprintf("0.0.0.0:%d", httpPort)
-		log.Printf("Trying port: %d", httpPort)
-		s.l, err = net.Listen("tcp", httpAddr)
-		if err == nil {
-			break
-		}
+	// Find an available TCP port for our HTTP server
+	var err error
+	s.l, httpPort, err = ListenRange(bindAddress, s.HTTPPortMin, s.HTTPPortMax)
+	if err != nil {
+		err := fmt.Errorf("Error finding port for the HTTP server: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
 	}
+	httpAddr := s.l.Addr().String()
 
 	ui.Say(fmt.Sprintf("Starting HTTP server on port %d", httpPort))
 
-	// Start the HTTP server and run it in the background
+	// Start the HTTP server and run it in the background. The file server
+	// handles Range requests, so installers can resume large downloads.
 	fileServer := http.FileServer(http.Dir(s.HTTPDir))
-	server := &http.Server{Addr: httpAddr, Handler: fileServer}
+	server := &http.Server{Addr: httpAddr, Handler: logRequests(fileServer)}
 	go server.Serve(s.l)
 
 	// Save the address into the state so it can be accessed in the future
@@ -76,6 +71,18 @@ func (s *StepHTTPServer) Run(state multistep.StateBag) multistep.StepAction {
 	return multistep.ActionContinue
 }
 
+// logRequests wraps a handler to log every request it serves.
+func logRequests(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rng := r.Header.Get("Range"); rng != "" {
+			log.Printf("HTTP server: %s %s %s (range %s)", r.RemoteAddr, r.Method, r.URL.Path, rng)
+		} else {
+			log.Printf("HTTP server: %s %s %s", r.RemoteAddr, r.Method, r.URL.Path)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
 func httpAddrFilename(suffix string) string {
 	uuid := os.Getenv("PACKER_RUN_UUID")
 	return filepath.Join(os.TempDir(), fmt.Sprintf("packer-%s-%s", uuid, suffix))
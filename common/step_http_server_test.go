@@ -0,0 +1,66 @@
+package common
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/multistep"
+)
+
+func TestStepHTTPServer_impl(t *testing.T) {
+	var _ multistep.Step = new(StepHTTPServer)
+}
+
+func TestStepHTTPServer(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	if err := ioutil.WriteFile(filepath.Join(td, "ks.cfg"), []byte("0123456789"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state := testStepCreateFloppyState(t)
+	step := &StepHTTPServer{
+		HTTPDir:     td,
+		HTTPAddress: "127.0.0.1",
+		HTTPPortMin: 18000,
+		HTTPPortMax: 19000,
+	}
+	defer step.Cleanup(state)
+
+	if action := step.Run(state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	port := state.Get("http_port").(uint)
+	req, err := http.NewRequest("GET", fmt.Sprintf("http://127.0.0.1:%d/ks.cfg", port), nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	req.Header.Set("Range", "bytes=5-")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		t.Fatalf("bad status: %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(body) != "56789" {
+		t.Fatalf("bad: %q", body)
+	}
+}
@@ -0,0 +1,97 @@
+package common
+
+import (
+	"sync"
+
+	"github.com/mitchellh/multistep"
+)
+
+// StepParallel runs a group of independent steps concurrently, such as
+// downloading an ISO while the output directory and disks are created.
+//
+// All steps are run to completion. If any of them halts, the group halts
+// and the other steps see multistep.StateCancelled, so that long running
+// steps that check it stop early. Only the first error put in the state
+// bag is kept. The steps share the state bag otherwise, so they must not
+// depend on each other's state.
+type StepParallel struct {
+	Steps []multistep.Step
+}
+
+func (s *StepParallel) Run(state multistep.StateBag) multistep.StepAction {
+	group := &parallelStateBag{StateBag: state}
+
+	var wg sync.WaitGroup
+	for _, step := range s.Steps {
+		wg.Add(1)
+		go func(step multistep.Step) {
+			defer wg.Done()
+			if step.Run(group) != multistep.ActionContinue {
+				group.halt()
+			}
+		}(step)
+	}
+	wg.Wait()
+
+	if group.halted() {
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *StepParallel) Cleanup(state multistep.StateBag) {
+	// Every step has run, so clean all of them up in reverse order just
+	// like the runner does for a sequence of steps.
+	for i := len(s.Steps) - 1; i >= 0; i-- {
+		s.Steps[i].Cleanup(state)
+	}
+}
+
+// parallelStateBag is the state bag of the steps of a StepParallel. It
+// reports the group as cancelled once any step halted, and keeps the first
+// error instead of letting the steps overwrite each other's errors.
+type parallelStateBag struct {
+	multistep.StateBag
+
+	l        sync.Mutex
+	isHalted bool
+}
+
+func (b *parallelStateBag) Get(k string) interface{} {
+	result, _ := b.GetOk(k)
+	return result
+}
+
+func (b *parallelStateBag) GetOk(k string) (interface{}, bool) {
+	if k == multistep.StateCancelled && b.halted() {
+		return true, true
+	}
+
+	return b.StateBag.GetOk(k)
+}
+
+func (b *parallelStateBag) Put(k string, v interface{}) {
+	b.l.Lock()
+	defer b.l.Unlock()
+
+	if k == "error" {
+		if _, ok := b.StateBag.GetOk("error"); ok {
+			return
+		}
+	}
+
+	b.StateBag.Put(k, v)
+}
+
+func (b *parallelStateBag) halt() {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.isHalted = true
+}
+
+func (b *parallelStateBag) halted() bool {
+	b.l.Lock()
+	defer b.l.Unlock()
+	return b.isHalted
+}
@@ -0,0 +1,149 @@
+package common
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/multistep"
+)
+
+type parallelTestStep struct {
+	Action multistep.StepAction
+	Wait   <-chan struct{}
+	Done   chan<- struct{}
+
+	l       *sync.Mutex
+	cleaned *[]int
+	id      int
+}
+
+func (s *parallelTestStep) Run(state multistep.StateBag) multistep.StepAction {
+	if s.Done != nil {
+		close(s.Done)
+	}
+	if s.Wait != nil {
+		<-s.Wait
+	}
+
+	return s.Action
+}
+
+func (s *parallelTestStep) Cleanup(state multistep.StateBag) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	*s.cleaned = append(*s.cleaned, s.id)
+}
+
+func TestStepParallel_impl(t *testing.T) {
+	var _ multistep.Step = new(StepParallel)
+}
+
+func TestStepParallel(t *testing.T) {
+	var l sync.Mutex
+	var cleaned []int
+
+	// The first step only finishes once the second one has started, so
+	// this would deadlock if the steps ran one after the other.
+	started := make(chan struct{})
+	step := &StepParallel{
+		Steps: []multistep.Step{
+			&parallelTestStep{Wait: started, l: &l, cleaned: &cleaned, id: 1},
+			&parallelTestStep{Done: started, l: &l, cleaned: &cleaned, id: 2},
+		},
+	}
+
+	state := new(multistep.BasicStateBag)
+	doneCh := make(chan multistep.StepAction, 1)
+	go func() {
+		doneCh <- step.Run(state)
+	}()
+
+	select {
+	case action := <-doneCh:
+		if action != multistep.ActionContinue {
+			t.Fatalf("bad action: %#v", action)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("steps didn't run in parallel")
+	}
+
+	step.Cleanup(state)
+	if len(cleaned) != 2 || cleaned[0] != 2 || cleaned[1] != 1 {
+		t.Fatalf("bad: %#v", cleaned)
+	}
+}
+
+func TestStepParallel_halt(t *testing.T) {
+	var l sync.Mutex
+	var cleaned []int
+
+	step := &StepParallel{
+		Steps: []multistep.Step{
+			&parallelTestStep{Action: multistep.ActionContinue, l: &l, cleaned: &cleaned, id: 1},
+			&parallelTestStep{Action: multistep.ActionHalt, l: &l, cleaned: &cleaned, id: 2},
+		},
+	}
+
+	state := new(multistep.BasicStateBag)
+	if action := step.Run(state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	step.Cleanup(state)
+	if len(cleaned) != 2 {
+		t.Fatalf("bad: %#v", cleaned)
+	}
+}
+
+// cancellableTestStep halts with an error right away if Fail is set, or
+// waits until the group is cancelled otherwise.
+type cancellableTestStep struct {
+	Fail bool
+}
+
+func (s *cancellableTestStep) Run(state multistep.StateBag) multistep.StepAction {
+	if s.Fail {
+		state.Put("error", errors.New("first"))
+		return multistep.ActionHalt
+	}
+
+	timeout := time.After(5 * time.Second)
+	for {
+		if _, ok := state.GetOk(multistep.StateCancelled); ok {
+			state.Put("error", errors.New("cancelled"))
+			return multistep.ActionHalt
+		}
+
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-timeout:
+			return multistep.ActionContinue
+		}
+	}
+}
+
+func (s *cancellableTestStep) Cleanup(multistep.StateBag) {}
+
+func TestStepParallel_cancelSiblings(t *testing.T) {
+	step := &StepParallel{
+		Steps: []multistep.Step{
+			&cancellableTestStep{},
+			&cancellableTestStep{Fail: true},
+		},
+	}
+
+	state := new(multistep.BasicStateBag)
+	if action := step.Run(state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	err := state.Get("error").(error)
+	if err.Error() != "first" {
+		t.Fatalf("bad: %s", err)
+	}
+	if _, ok := state.GetOk(multistep.StateCancelled); ok {
+		t.Fatal("the build should not be cancelled")
+	}
+}
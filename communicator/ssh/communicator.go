@@ -120,7 +120,10 @@ func (c *comm) Start(cmd *packer.RemoteCmd) (err error) {
 				log.Printf("Remote command exited without exit status or exit signal.")
 				exitStatus = packer.CmdDisconnect
 			default:
+				// The connection went away before the command finished,
+				// for example because the command rebooted the machine.
 				log.Printf("Error occurred waiting for ssh session: %s", err.Error())
+				exitStatus = packer.CmdDisconnect
 			}
 		}
 		cmd.SetExited(exitStatus)
@@ -245,7 +248,7 @@ func (c *comm) newSession() (session *ssh.Session, err error) {
 		}
 	}
 
-	return session, nil
+	return session, err
 }
 
 func (c *comm) reconnect() (err error) {
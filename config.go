@@ -30,6 +30,10 @@ type config struct {
 	Builders       map[string]string
 	PostProcessors map[string]string `json:"post-processors"`
 	Provisioners   map[string]string
+
+	// build is the name of the build that plugins are loaded for. See
+	// BuildComponents.
+	build string
 }
 
 // Decodes configuration in JSON format from the given io.Reader into
@@ -39,11 +43,15 @@ func decodeConfig(r io.Reader, c *config) error {
 	return decoder.Decode(c)
 }
 
+// projectPluginDir is the directory, relative to the CWD, that plugins
+// used by the templates of a single project can be put in.
+var projectPluginDir = filepath.Join(".packer.d", "plugins")
+
 // Discover discovers plugins.
 //
-// Search the directory of the executable, then the plugins directory, and
-// finally the CWD, in that order. Any conflicts will overwrite previously
-// found plugins, in that order.
+// Search the directory of the executable, then the plugins directory, then
+// the project plugins directory, and finally the CWD, in that order. Any
+// conflicts will overwrite previously found plugins, in that order.
 // Hence, the priority order is the reverse of the search order - i.e., the
 // CWD has the highest priority.
 func (c *config) Discover() error {
@@ -73,6 +81,11 @@ func (c *config) Discover() error {
 		}
 	}
 
+	// Next, look in the project plugins directory.
+	if err := c.discover(projectPluginDir); err != nil {
+		return err
+	}
+
 	// Next, look in the CWD.
 	if err := c.discover("."); err != nil {
 		return err
@@ -87,6 +100,20 @@ func (c *config) Discover() error {
 	return nil
 }
 
+// BuildComponents returns a packer.ComponentFinder that loads the plugins
+// of the given build, so that their output is tagged with the build name
+// in the log.
+func (c *config) BuildComponents(build string) packer.ComponentFinder {
+	bc := *c
+	bc.build = build
+	return packer.ComponentFinder{
+		Builder:       bc.LoadBuilder,
+		Hook:          bc.LoadHook,
+		PostProcessor: bc.LoadPostProcessor,
+		Provisioner:   bc.LoadProvisioner,
+	}
+}
+
 // This is a proper packer.BuilderFunc that can be used to load packer.Builder
 // implementations from the defined plugins.
 func (c *config) LoadBuilder(name string) (packer.Builder, error) {
@@ -280,5 +307,6 @@ func (c *config) pluginClient(path string) *plugin.Client {
 	config.Managed = true
 	config.MinPort = c.PluginMinPort
 	config.MaxPort = c.PluginMaxPort
+	config.Build = c.build
 	return plugin.NewClient(&config)
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/masterzen/winrm"
+	commonssh "github.com/mitchellh/packer/common/ssh"
 	"github.com/mitchellh/packer/template/interpolate"
 )
 
@@ -21,13 +22,16 @@ type Config struct {
 	SSHUsername           string        `mapstructure:"ssh_username"`
 	SSHPassword           string        `mapstructure:"ssh_password"`
 	SSHPrivateKey         string        `mapstructure:"ssh_private_key_file"`
+	SSHPrivateKeyPass     string        `mapstructure:"ssh_private_key_passphrase"`
 	SSHPty                bool          `mapstructure:"ssh_pty"`
 	SSHTimeout            time.Duration `mapstructure:"ssh_timeout"`
 	SSHAgentAuth          bool          `mapstructure:"ssh_agent_auth"`
 	SSHDisableAgent       bool          `mapstructure:"ssh_disable_agent"`
 	SSHHandshakeAttempts  int           `mapstructure:"ssh_handshake_attempts"`
+	SSHMaxRetryDelay      time.Duration `mapstructure:"ssh_max_retry_delay"`
 	SSHBastionHost        string        `mapstructure:"ssh_bastion_host"`
 	SSHBastionPort        int           `mapstructure:"ssh_bastion_port"`
+	SSHBastionAgentAuth   bool          `mapstructure:"ssh_bastion_agent_auth"`
 	SSHBastionUsername    string        `mapstructure:"ssh_bastion_username"`
 	SSHBastionPassword    string        `mapstructure:"ssh_bastion_password"`
 	SSHBastionPrivateKey  string        `mapstructure:"ssh_bastion_private_key_file"`
@@ -41,6 +45,7 @@ type Config struct {
 	WinRMTimeout            time.Duration `mapstructure:"winrm_timeout"`
 	WinRMUseSSL             bool          `mapstructure:"winrm_use_ssl"`
 	WinRMInsecure           bool          `mapstructure:"winrm_insecure"`
+	WinRMUseNTLM            bool          `mapstructure:"winrm_use_ntlm"`
 	WinRMTransportDecorator func() winrm.Transporter
 }
 
@@ -134,6 +139,10 @@ func (c *Config) prepareSSH(ctx *interpolate.Context) []error {
 			c.SSHBastionPort = 22
 		}
 
+		if c.SSHBastionUsername == "" {
+			c.SSHBastionUsername = c.SSHUsername
+		}
+
 		if c.SSHBastionPrivateKey == "" && c.SSHPrivateKey != "" {
 			c.SSHBastionPrivateKey = c.SSHPrivateKey
 		}
@@ -143,6 +152,10 @@ func (c *Config) prepareSSH(ctx *interpolate.Context) []error {
 		c.SSHFileTransferMethod = "scp"
 	}
 
+	if c.SSHPrivateKeyPass == "" {
+		c.SSHPrivateKeyPass = os.Getenv("PACKER_SSH_PRIVATE_KEY_PASSPHRASE")
+	}
+
 	// Validation
 	var errs []error
 	if c.SSHUsername == "" {
@@ -153,19 +166,33 @@ func (c *Config) prepareSSH(ctx *interpolate.Context) []error {
 		if _, err := os.Stat(c.SSHPrivateKey); err != nil {
 			errs = append(errs, fmt.Errorf(
 				"ssh_private_key_file is invalid: %s", err))
-		} else if _, err := SSHFileSigner(c.SSHPrivateKey); err != nil {
+		} else if _, err := commonssh.EncryptedFileSigner(c.SSHPrivateKey, c.SSHPrivateKeyPass); err != nil {
 			errs = append(errs, fmt.Errorf(
 				"ssh_private_key_file is invalid: %s", err))
 		}
 	}
 
 	if c.SSHBastionHost != "" {
-		if c.SSHBastionPassword == "" && c.SSHBastionPrivateKey == "" {
+		if c.SSHBastionPassword == "" && c.SSHBastionPrivateKey == "" && !c.SSHBastionAgentAuth {
 			errs = append(errs, errors.New(
-				"ssh_bastion_password or ssh_bastion_private_key_file must be specified"))
+				"ssh_bastion_password, ssh_bastion_private_key_file or ssh_bastion_agent_auth must be specified"))
+		}
+
+		if c.SSHBastionPrivateKey != "" && c.SSHBastionPrivateKey != c.SSHPrivateKey {
+			if _, err := os.Stat(c.SSHBastionPrivateKey); err != nil {
+				errs = append(errs, fmt.Errorf(
+					"ssh_bastion_private_key_file is invalid: %s", err))
+			} else if _, err := commonssh.EncryptedFileSigner(c.SSHBastionPrivateKey, c.SSHPrivateKeyPass); err != nil {
+				errs = append(errs, fmt.Errorf(
+					"ssh_bastion_private_key_file is invalid: %s", err))
+			}
 		}
 	}
 
+	if c.SSHMaxRetryDelay < 0 {
+		errs = append(errs, errors.New("ssh_max_retry_delay must not be negative"))
+	}
+
 	if c.SSHFileTransferMethod != "scp" && c.SSHFileTransferMethod != "sftp" {
 		errs = append(errs, fmt.Errorf(
 			"ssh_file_transfer_method ('%s') is invalid, valid methods: sftp, scp",
@@ -186,6 +213,10 @@ func (c *Config) prepareWinRM(ctx *interpolate.Context) []error {
 		c.WinRMTimeout = 30 * time.Minute
 	}
 
+	if c.WinRMUseNTLM {
+		c.WinRMTransportDecorator = func() winrm.Transporter { return &winrm.ClientNTLM{} }
+	}
+
 	var errs []error
 	if c.WinRMUser == "" {
 		errs = append(errs, errors.New("winrm_username must be specified."))
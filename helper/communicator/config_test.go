@@ -3,6 +3,7 @@ package communicator
 import (
 	"testing"
 
+	"github.com/masterzen/winrm"
 	"github.com/mitchellh/packer/template/interpolate"
 )
 
@@ -37,6 +38,48 @@ func TestConfig_badtype(t *testing.T) {
 	}
 }
 
+func TestConfig_bastion(t *testing.T) {
+	c := testConfig()
+	c.SSHBastionHost = "bastion"
+	c.SSHBastionPassword = "foo"
+	if err := c.Prepare(testContext(t)); len(err) > 0 {
+		t.Fatalf("bad: %#v", err)
+	}
+
+	if c.SSHBastionPort != 22 {
+		t.Fatalf("bad: %d", c.SSHBastionPort)
+	}
+	if c.SSHBastionUsername != "root" {
+		t.Fatalf("bad: %s", c.SSHBastionUsername)
+	}
+}
+
+func TestConfig_bastionNoAuth(t *testing.T) {
+	c := testConfig()
+	c.SSHBastionHost = "bastion"
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("bad: %#v", err)
+	}
+}
+
+func TestConfig_bastionAgentAuth(t *testing.T) {
+	c := testConfig()
+	c.SSHBastionHost = "bastion"
+	c.SSHBastionAgentAuth = true
+	if err := c.Prepare(testContext(t)); len(err) > 0 {
+		t.Fatalf("bad: %#v", err)
+	}
+}
+
+func TestConfig_bastionBadPrivateKey(t *testing.T) {
+	c := testConfig()
+	c.SSHBastionHost = "bastion"
+	c.SSHBastionPrivateKey = "/i/dont/exist"
+	if err := c.Prepare(testContext(t)); len(err) != 1 {
+		t.Fatalf("bad: %#v", err)
+	}
+}
+
 func TestConfig_winrm_noport(t *testing.T) {
 	c := &Config{
 		Type:      "winrm",
@@ -114,3 +157,22 @@ func TestConfig_winrm(t *testing.T) {
 func testContext(t *testing.T) *interpolate.Context {
 	return nil
 }
+
+func TestConfig_winrm_use_ntlm(t *testing.T) {
+	c := &Config{
+		Type:         "winrm",
+		WinRMUser:    "admin",
+		WinRMUseNTLM: true,
+	}
+	if err := c.Prepare(testContext(t)); len(err) > 0 {
+		t.Fatalf("bad: %#v", err)
+	}
+
+	if c.WinRMTransportDecorator == nil {
+		t.Fatalf("WinRMTransportDecorator should be set when NTLM is enabled")
+	}
+
+	if _, ok := c.WinRMTransportDecorator().(*winrm.ClientNTLM); !ok {
+		t.Fatalf("bad: %#v", c.WinRMTransportDecorator())
+	}
+}
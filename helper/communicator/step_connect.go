@@ -2,7 +2,11 @@ package communicator
 
 import (
 	"fmt"
+	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/mitchellh/multistep"
 	"github.com/mitchellh/packer/communicator/none"
@@ -40,7 +44,14 @@ type StepConnect struct {
 	// existing types.
 	CustomConnect map[string]multistep.Step
 
-	substep multistep.Step
+	// If Debug is true, the connection details are written to files in
+	// DebugDir once connected, so the machine can be inspected while the
+	// build is paused. The files are removed again on cleanup.
+	Debug    bool
+	DebugDir string
+
+	substep    multistep.Step
+	debugFiles []string
 }
 
 func (s *StepConnect) Run(state multistep.StateBag) multistep.StepAction {
@@ -84,11 +95,93 @@ func (s *StepConnect) Run(state multistep.StateBag) multistep.StepAction {
 	}
 
 	s.substep = step
-	return s.substep.Run(state)
+	action := s.substep.Run(state)
+	if action == multistep.ActionContinue && s.Debug && s.DebugDir != "" {
+		ui := state.Get("ui").(packer.Ui)
+		if err := s.writeDebugFiles(state); err != nil {
+			ui.Error(fmt.Sprintf("Error saving connection info for debug purposes: %s", err))
+		} else {
+			ui.Message(fmt.Sprintf("Saved connection info for debug purposes: %s",
+				strings.Join(s.debugFiles, ", ")))
+		}
+	}
+
+	return action
 }
 
 func (s *StepConnect) Cleanup(state multistep.StateBag) {
 	if s.substep != nil {
 		s.substep.Cleanup(state)
 	}
+
+	for _, path := range s.debugFiles {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("[WARN] Error removing debug file %s: %s", path, err)
+		}
+	}
+	s.debugFiles = nil
+}
+
+// writeDebugFiles writes the host, port and user the communicator connected
+// with to DebugDir, along with the private key if it was generated for this
+// build and the serial TTY if the builder put one in the state bag under
+// "serial_tty".
+func (s *StepConnect) writeDebugFiles(state multistep.StateBag) error {
+	host, err := s.Host(state)
+	if err != nil {
+		return err
+	}
+
+	port := s.Config.Port()
+	portFn := s.SSHPort
+	if s.Config.Type == "winrm" {
+		portFn = s.WinRMPort
+	}
+	if portFn != nil {
+		if port, err = portFn(state); err != nil {
+			return err
+		}
+	}
+
+	info := fmt.Sprintf("communicator: %s\nhost: %s\nport: %d\nusername: %s\n",
+		s.Config.Type, host, port, s.Config.User())
+
+	if key := debugPrivateKey(state); key != "" {
+		keyPath := filepath.Join(s.DebugDir, "debug-ssh-key.pem")
+		if err := s.writeDebugFile(keyPath, key); err != nil {
+			return err
+		}
+		info += fmt.Sprintf("private_key_file: %s\n", keyPath)
+	} else if s.Config.Type == "ssh" && s.Config.SSHPrivateKey != "" {
+		info += fmt.Sprintf("private_key_file: %s\n", s.Config.SSHPrivateKey)
+	}
+
+	if tty, ok := state.GetOk("serial_tty"); ok {
+		info += fmt.Sprintf("serial_tty: %s\n", tty)
+	}
+
+	return s.writeDebugFile(filepath.Join(s.DebugDir, "debug-connection.txt"), info)
+}
+
+func (s *StepConnect) writeDebugFile(path, contents string) error {
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		return err
+	}
+
+	s.debugFiles = append(s.debugFiles, path)
+	return nil
+}
+
+// debugPrivateKey returns the private key that builders generating a key
+// pair for the build put in the state bag, if any.
+func debugPrivateKey(state multistep.StateBag) string {
+	for _, k := range []string{"privateKey", "ssh_private_key"} {
+		if key, ok := state.GetOk(k); ok {
+			if key, ok := key.(string); ok && key != "" {
+				return key
+			}
+		}
+	}
+
+	return ""
 }
@@ -15,6 +15,9 @@ import (
 	gossh "golang.org/x/crypto/ssh"
 )
 
+// sshRetryDelay is the initial delay between attempts to connect to SSH.
+const sshRetryDelay = 5 * time.Second
+
 // StepConnectSSH is a step that only connects to SSH.
 //
 // In general, you should use StepConnect.
@@ -102,6 +105,7 @@ func (s *StepConnectSSH) waitForSSH(state multistep.StateBag, cancel <-chan stru
 	handshakeAttempts := 0
 
 	var comm packer.Communicator
+	delay := sshRetryDelay
 	first := true
 	for {
 		// Don't check for cancel or wait on first iteration
@@ -110,8 +114,10 @@ func (s *StepConnectSSH) waitForSSH(state multistep.StateBag, cancel <-chan stru
 			case <-cancel:
 				log.Println("[DEBUG] SSH wait cancelled. Exiting loop.")
 				return nil, errors.New("SSH wait cancelled")
-			case <-time.After(5 * time.Second):
+			case <-time.After(delay):
 			}
+
+			delay = nextRetryDelay(delay, s.Config.SSHMaxRetryDelay)
 		}
 		first = false
 
@@ -195,8 +201,32 @@ func (s *StepConnectSSH) waitForSSH(state multistep.StateBag, cancel <-chan stru
 	return comm, nil
 }
 
+// nextRetryDelay doubles the delay between connection attempts, up to
+// max. If max is not larger than the current delay, the delay is kept.
+func nextRetryDelay(delay, max time.Duration) time.Duration {
+	if max <= delay {
+		return delay
+	}
+
+	delay *= 2
+	if delay > max {
+		delay = max
+	}
+
+	return delay
+}
+
 func sshBastionConfig(config *Config) (*gossh.ClientConfig, error) {
 	auth := make([]gossh.AuthMethod, 0, 2)
+	if config.SSHBastionAgentAuth {
+		agentAuth, err := commonssh.AgentAuth()
+		if err != nil {
+			return nil, err
+		}
+
+		auth = append(auth, agentAuth)
+	}
+
 	if config.SSHBastionPassword != "" {
 		auth = append(auth,
 			gossh.Password(config.SSHBastionPassword),
@@ -205,7 +235,8 @@ func sshBastionConfig(config *Config) (*gossh.ClientConfig, error) {
 	}
 
 	if config.SSHBastionPrivateKey != "" {
-		signer, err := commonssh.FileSigner(config.SSHBastionPrivateKey)
+		signer, err := commonssh.EncryptedFileSigner(
+			config.SSHBastionPrivateKey, config.SSHPrivateKeyPass)
 		if err != nil {
 			return nil, err
 		}
@@ -2,7 +2,12 @@ package communicator
 
 import (
 	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mitchellh/multistep"
 	"github.com/mitchellh/packer/packer"
@@ -37,3 +42,78 @@ func testState(t *testing.T) multistep.StateBag {
 	})
 	return state
 }
+
+func TestNextRetryDelay(t *testing.T) {
+	cases := []struct {
+		Delay    time.Duration
+		Max      time.Duration
+		Expected time.Duration
+	}{
+		{5 * time.Second, 0, 5 * time.Second},
+		{5 * time.Second, 2 * time.Second, 5 * time.Second},
+		{5 * time.Second, time.Minute, 10 * time.Second},
+		{40 * time.Second, time.Minute, time.Minute},
+		{time.Minute, time.Minute, time.Minute},
+	}
+
+	for _, tc := range cases {
+		actual := nextRetryDelay(tc.Delay, tc.Max)
+		if actual != tc.Expected {
+			t.Fatalf("bad: %s, %s: %s", tc.Delay, tc.Max, actual)
+		}
+	}
+}
+
+func TestStepConnect_debugFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	state := testState(t)
+	state.Put("privateKey", "KEY")
+
+	step := &StepConnect{
+		Config: &Config{
+			Type:        "ssh",
+			SSHPort:     22,
+			SSHUsername: "packer",
+		},
+		Host: func(multistep.StateBag) (string, error) {
+			return "127.0.0.1", nil
+		},
+		SSHPort: func(multistep.StateBag) (int, error) {
+			return 2222, nil
+		},
+		Debug:    true,
+		DebugDir: dir,
+	}
+
+	if err := step.writeDebugFiles(state); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	info, err := ioutil.ReadFile(filepath.Join(dir, "debug-connection.txt"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for _, expected := range []string{"host: 127.0.0.1", "port: 2222", "username: packer", "debug-ssh-key.pem"} {
+		if !strings.Contains(string(info), expected) {
+			t.Fatalf("missing %q: %s", expected, info)
+		}
+	}
+
+	key, err := ioutil.ReadFile(filepath.Join(dir, "debug-ssh-key.pem"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(key) != "KEY" {
+		t.Fatalf("bad: %s", key)
+	}
+
+	step.Cleanup(state)
+	if _, err := os.Stat(filepath.Join(dir, "debug-connection.txt")); !os.IsNotExist(err) {
+		t.Fatal("debug files should be removed on cleanup")
+	}
+}
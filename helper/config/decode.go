@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"sort"
@@ -23,8 +24,23 @@ type DecodeOpts struct {
 	Interpolate        bool
 	InterpolateContext *interpolate.Context
 	InterpolateFilter  *interpolate.RenderFilter
+
+	// AllowUnknownKeys, if true, will not return an error for unknown
+	// configuration keys. Builders can use this together with UnknownKeys
+	// to turn unknown keys into warnings, which eases migrating templates
+	// between builder versions. The unknown_keys setting of the
+	// configuration, "error" or "warn", overrides it.
+	AllowUnknownKeys bool
+
+	// UnknownKeys, if non-nil, will be set to the sorted list of unknown
+	// configuration keys post-decode.
+	UnknownKeys *[]string
 }
 
+// UnknownKeysKey is the configuration key that sets whether unknown
+// configuration keys are errors ("error") or only reported ("warn").
+const UnknownKeysKey = "unknown_keys"
+
 // Decode decodes the configuration into the target and optionally
 // automatically interpolates all the configuration as it goes.
 func Decode(target interface{}, config *DecodeOpts, raws ...interface{}) error {
@@ -49,6 +65,10 @@ func Decode(target interface{}, config *DecodeOpts, raws ...interface{}) error {
 		}
 		ctx = config.InterpolateContext
 
+		// Environment variables can be read anywhere in the configuration
+		// of a component, not just in the user variables.
+		ctx.EnableEnv = true
+
 		// Render everything
 		for i, raw := range raws {
 			m, err := interpolate.RenderMap(raw, ctx, config.InterpolateFilter)
@@ -60,6 +80,11 @@ func Decode(target interface{}, config *DecodeOpts, raws ...interface{}) error {
 		}
 	}
 
+	allowUnknown, err := allowUnknownKeys(config.AllowUnknownKeys, raws...)
+	if err != nil {
+		return err
+	}
+
 	// Build our decoder
 	var md mapstructure.Metadata
 	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
@@ -68,6 +93,7 @@ func Decode(target interface{}, config *DecodeOpts, raws ...interface{}) error {
 		WeaklyTypedInput: true,
 		DecodeHook: mapstructure.ComposeDecodeHookFunc(
 			uint8ToStringHook,
+			jsonStringHook,
 			mapstructure.StringToSliceHookFunc(","),
 			mapstructure.StringToTimeDurationHookFunc(),
 		),
@@ -86,24 +112,68 @@ func Decode(target interface{}, config *DecodeOpts, raws ...interface{}) error {
 		*config.Metadata = md
 	}
 
-	// If we have unused keys, it is an error
-	if len(md.Unused) > 0 {
-		var err error
-		sort.Strings(md.Unused)
-		for _, unused := range md.Unused {
-			if unused != "type" && !strings.HasPrefix(unused, "packer_") {
-				err = multierror.Append(err, fmt.Errorf(
-					"unknown configuration key: %q", unused))
-			}
+	var unknown []string
+	sort.Strings(md.Unused)
+	for _, unused := range md.Unused {
+		if unused != "type" && unused != UnknownKeysKey && !strings.HasPrefix(unused, "packer_") {
+			unknown = append(unknown, unused)
 		}
-		if err != nil {
-			return err
+	}
+
+	if config.UnknownKeys != nil {
+		*config.UnknownKeys = unknown
+	}
+
+	// If we have unknown keys, it is an error unless they are allowed
+	if len(unknown) > 0 && !allowUnknown {
+		var err error
+		for _, key := range unknown {
+			err = multierror.Append(err, fmt.Errorf(
+				"unknown configuration key: %q", key))
 		}
+		return err
 	}
 
 	return nil
 }
 
+// allowUnknownKeys returns whether unknown keys are allowed: def, unless
+// the unknown_keys setting of the raw configuration overrides it.
+func allowUnknownKeys(def bool, raws ...interface{}) (bool, error) {
+	var s struct {
+		UnknownKeys string `mapstructure:"unknown_keys"`
+	}
+	for _, r := range raws {
+		if err := mapstructure.WeakDecode(r, &s); err != nil {
+			return false, err
+		}
+	}
+
+	switch s.UnknownKeys {
+	case "":
+		return def, nil
+	case "error":
+		return false, nil
+	case "warn":
+		return true, nil
+	default:
+		return false, fmt.Errorf(
+			"%s must be \"error\" or \"warn\", got %q", UnknownKeysKey, s.UnknownKeys)
+	}
+}
+
+// UnknownKeyWarnings returns a warning for each of the given unknown
+// configuration keys, suitable for returning from a builder's Prepare.
+func UnknownKeyWarnings(keys []string) []string {
+	warnings := make([]string, 0, len(keys))
+	for _, key := range keys {
+		warnings = append(warnings, fmt.Sprintf(
+			"unknown configuration key: %q, it will be ignored", key))
+	}
+
+	return warnings
+}
+
 // DetectContext builds a base interpolate.Context, automatically
 // detecting things like user variables from the raw configuration params.
 func DetectContext(raws ...interface{}) (*interpolate.Context, error) {
@@ -128,6 +198,30 @@ func DetectContext(raws ...interface{}) (*interpolate.Context, error) {
 	}, nil
 }
 
+// jsonStringHook decodes JSON encoded strings into slices and maps. This
+// is how the values of list and map user variables arrive, since user
+// variables are always strings.
+func jsonStringHook(f reflect.Kind, t reflect.Kind, v interface{}) (interface{}, error) {
+	if f != reflect.String {
+		return v, nil
+	}
+
+	s := strings.TrimSpace(v.(string))
+	switch {
+	case t == reflect.Slice && strings.HasPrefix(s, "["):
+	case t == reflect.Map && strings.HasPrefix(s, "{"):
+	default:
+		return v, nil
+	}
+
+	var result interface{}
+	if err := json.Unmarshal([]byte(s), &result); err != nil {
+		return v, nil
+	}
+
+	return result, nil
+}
+
 func uint8ToStringHook(f reflect.Kind, t reflect.Kind, v interface{}) (interface{}, error) {
 	// We need to convert []uint8 to string. We have to do this
 	// because internally Packer uses MsgPack for RPC and the MsgPack
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"os"
 	"reflect"
 	"testing"
 	"time"
@@ -104,8 +105,26 @@ func TestDecode(t *testing.T) {
 			},
 			nil,
 		},
+
+		"env": {
+			[]interface{}{
+				map[string]interface{}{
+					"name": "{{env `PACKER_TEST_DECODE_ENV`}}-{{build_name}}",
+				},
+				map[string]interface{}{
+					"packer_build_name": "foo",
+				},
+			},
+			&Target{
+				Name: "bar-foo",
+			},
+			nil,
+		},
 	}
 
+	os.Setenv("PACKER_TEST_DECODE_ENV", "bar")
+	defer os.Unsetenv("PACKER_TEST_DECODE_ENV")
+
 	for k, tc := range cases {
 		var result Target
 		err := Decode(&result, tc.Opts, tc.Input...)
@@ -118,3 +137,112 @@ func TestDecode(t *testing.T) {
 		}
 	}
 }
+
+func TestDecode_unknownKeys(t *testing.T) {
+	type Target struct {
+		Name string
+	}
+
+	raw := map[string]interface{}{
+		"name":                "bar",
+		"foo":                 "baz",
+		"bar":                 "baz",
+		"type":                "null",
+		"packer_builder_type": "null",
+	}
+
+	var unknown []string
+	var result Target
+	err := Decode(&result, &DecodeOpts{UnknownKeys: &unknown}, raw)
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !reflect.DeepEqual(unknown, []string{"bar", "foo"}) {
+		t.Fatalf("bad: %#v", unknown)
+	}
+
+	unknown = nil
+	err = Decode(&result, &DecodeOpts{
+		AllowUnknownKeys: true,
+		UnknownKeys:      &unknown,
+	}, raw)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if result.Name != "bar" {
+		t.Fatalf("bad: %#v", result)
+	}
+	if !reflect.DeepEqual(unknown, []string{"bar", "foo"}) {
+		t.Fatalf("bad: %#v", unknown)
+	}
+
+	warns := UnknownKeyWarnings(unknown)
+	if len(warns) != 2 {
+		t.Fatalf("bad: %#v", warns)
+	}
+}
+
+func TestDecode_unknownKeysSetting(t *testing.T) {
+	type Target struct {
+		Name string
+	}
+
+	raw := map[string]interface{}{
+		"name":         "bar",
+		"foo":          "baz",
+		"unknown_keys": "warn",
+	}
+
+	var unknown []string
+	var result Target
+	err := Decode(&result, &DecodeOpts{UnknownKeys: &unknown}, raw)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(unknown, []string{"foo"}) {
+		t.Fatalf("bad: %#v", unknown)
+	}
+
+	raw["unknown_keys"] = "error"
+	err = Decode(&result, &DecodeOpts{AllowUnknownKeys: true}, raw)
+	if err == nil {
+		t.Fatal("should error")
+	}
+
+	raw["unknown_keys"] = "ignore"
+	err = Decode(&result, nil, raw)
+	if err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestDecode_jsonVariables(t *testing.T) {
+	var result struct {
+		Names []string
+		Tags  map[string]string
+		Other []string
+	}
+
+	raw := map[string]interface{}{
+		"names": "{{user `names`}}",
+		"tags":  "{{user `tags`}}",
+		"other": "a,b",
+		"packer_user_variables": map[string]string{
+			"names": `["a,1", "b"]`,
+			"tags":  `{"os": "linux"}`,
+		},
+	}
+	if err := Decode(&result, nil, raw); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !reflect.DeepEqual(result.Names, []string{"a,1", "b"}) {
+		t.Fatalf("bad: %#v", result.Names)
+	}
+	if !reflect.DeepEqual(result.Tags, map[string]string{"os": "linux"}) {
+		t.Fatalf("bad: %#v", result.Tags)
+	}
+	if !reflect.DeepEqual(result.Other, []string{"a", "b"}) {
+		t.Fatalf("bad: %#v", result.Other)
+	}
+}
@@ -1,13 +1,15 @@
 package kvflag
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 )
 
 // FlagJSON is a flag.Value implementation for parsing user variables
-// from the command-line using JSON files.
+// from the command-line using JSON files. Lists and maps are kept JSON
+// encoded, which is how the values of typed variables are passed around.
 type FlagJSON map[string]string
 
 func (v *FlagJSON) String() string {
@@ -25,10 +27,31 @@ func (v *FlagJSON) Set(raw string) error {
 		*v = make(map[string]string)
 	}
 
-	if err := json.NewDecoder(f).Decode(v); err != nil {
+	var vars map[string]json.RawMessage
+	if err := json.NewDecoder(f).Decode(&vars); err != nil {
 		return fmt.Errorf(
 			"Error reading variables in '%s': %s", raw, err)
 	}
 
+	for k, value := range vars {
+		value = bytes.TrimSpace(value)
+		if len(value) == 0 || (value[0] != '[' && value[0] != '{') {
+			var s string
+			if err := json.Unmarshal(value, &s); err != nil {
+				return fmt.Errorf(
+					"Error reading variables in '%s': %s: %s", raw, k, err)
+			}
+			(*v)[k] = s
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, value); err != nil {
+			return fmt.Errorf(
+				"Error reading variables in '%s': %s: %s", raw, k, err)
+		}
+		(*v)[k] = buf.String()
+	}
+
 	return nil
 }
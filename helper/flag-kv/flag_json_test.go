@@ -38,6 +38,24 @@ func TestFlagJSON(t *testing.T) {
 			map[string]string{"key": "value"},
 			false,
 		},
+
+		{
+			"typed.json",
+			nil,
+			map[string]string{
+				"key":  "value",
+				"list": `["a","b"]`,
+				"map":  `{"a":"b"}`,
+			},
+			false,
+		},
+
+		{
+			"bad.json",
+			nil,
+			map[string]string{},
+			true,
+		},
 	}
 
 	for _, tc := range cases {
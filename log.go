@@ -1,14 +1,35 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 	"os"
+	"regexp"
+	"strings"
+	"sync"
 )
 
 // These are the environmental variables that determine if we log, and if
 // we log whether or not the log should go to a file.
-const EnvLog = "PACKER_LOG"          //Set to True
-const EnvLogFile = "PACKER_LOG_PATH" //Set to a file
+const EnvLog = "PACKER_LOG"            //Set to True
+const EnvLogFile = "PACKER_LOG_PATH"   //Set to a file
+const EnvLogLevel = "PACKER_LOG_LEVEL" //Set to a minimum level
+
+// logBuildName is replaced by the build name in the log path to write a
+// separate log file for each build. Log lines that don't belong to a build
+// go to the file for the build name logCoreName.
+const logBuildName = "{{build_name}}"
+const logCoreName = "packer"
+
+// logLevels are the levels of log lines, in increasing order.
+var logLevels = []string{"TRACE", "DEBUG", "INFO", "WARN", "ERR"}
+
+var logLevelRe = regexp.MustCompile(`\[(TRACE|DEBUG|INFO|WARN|ERR|ERROR)\]`)
+
+// logBuildRe matches the build tag of the plugin output in the log, see
+// plugin.ClientConfig.
+var logBuildRe = regexp.MustCompile(`^\S+ \S+ \[build ([^\]]+)\] `)
 
 // logOutput determines where we should send logs (if anywhere).
 func logOutput() (logOutput io.Writer, err error) {
@@ -16,14 +37,146 @@ func logOutput() (logOutput io.Writer, err error) {
 	if os.Getenv(EnvLog) != "" && os.Getenv(EnvLog) != "0" {
 		logOutput = os.Stderr
 
-		if logPath := os.Getenv(EnvLogFile); logPath != "" {
+		minLevel, err := parseLogLevel(os.Getenv(EnvLogLevel))
+		if err != nil {
+			return nil, err
+		}
+
+		logPath := os.Getenv(EnvLogFile)
+		if strings.Contains(logPath, logBuildName) {
+			return &logFilter{
+				path:     logPath,
+				minLevel: minLevel,
+				files:    make(map[string]*os.File),
+			}, nil
+		}
+
+		if logPath != "" {
 			var err error
 			logOutput, err = os.Create(logPath)
 			if err != nil {
 				return nil, err
 			}
 		}
+
+		if minLevel > 0 {
+			logOutput = &logFilter{out: logOutput, minLevel: minLevel}
+		}
 	}
 
 	return
 }
+
+// parseLogLevel returns the index of the given level in logLevels. An
+// empty level logs everything.
+func parseLogLevel(level string) (int, error) {
+	if level == "" {
+		return 0, nil
+	}
+
+	level = strings.ToUpper(level)
+	if level == "ERROR" {
+		level = "ERR"
+	}
+	for i, l := range logLevels {
+		if l == level {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%s must be one of %s, got %q",
+		EnvLogLevel, strings.Join(logLevels, ", "), level)
+}
+
+// logFilter is an io.Writer for the log that drops lines below a minimum
+// level and, if path is set, writes the lines of each build to their own
+// file. Lines without a level are always written.
+type logFilter struct {
+	out      io.Writer
+	path     string
+	minLevel int
+
+	buf   []byte
+	files map[string]*os.File
+	l     sync.Mutex
+}
+
+func (w *logFilter) Write(p []byte) (int, error) {
+	w.l.Lock()
+	defer w.l.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		idx := bytes.IndexByte(w.buf, '\n')
+		if idx < 0 {
+			break
+		}
+
+		line := w.buf[:idx+1]
+		w.buf = w.buf[idx+1:]
+		if err := w.writeLine(line); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// Close writes out the last unterminated line and closes the log files.
+func (w *logFilter) Close() error {
+	w.l.Lock()
+	defer w.l.Unlock()
+
+	if len(w.buf) > 0 {
+		w.writeLine(w.buf)
+		w.buf = nil
+	}
+
+	for _, f := range w.files {
+		f.Close()
+	}
+
+	return nil
+}
+
+func (w *logFilter) writeLine(line []byte) error {
+	if m := logLevelRe.FindSubmatch(line); m != nil {
+		level, _ := parseLogLevel(string(m[1]))
+		if level < w.minLevel {
+			return nil
+		}
+	}
+
+	out := w.out
+	if w.path != "" {
+		build := logCoreName
+		if m := logBuildRe.FindSubmatch(line); m != nil {
+			build = string(m[1])
+		}
+
+		var err error
+		out, err = w.file(build)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := out.Write(line)
+	return err
+}
+
+// file returns the log file of the given build, creating it if needed.
+func (w *logFilter) file(build string) (*os.File, error) {
+	if f, ok := w.files[build]; ok {
+		return f, nil
+	}
+
+	name := strings.NewReplacer("/", "_", "\\", "_").Replace(build)
+	f, err := os.Create(strings.Replace(w.path, logBuildName, name, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	w.files[build] = f
+	return f, nil
+}
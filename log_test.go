@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	cases := []struct {
+		Input    string
+		Expected int
+		Err      bool
+	}{
+		{"", 0, false},
+		{"trace", 0, false},
+		{"INFO", 2, false},
+		{"error", 4, false},
+		{"bogus", 0, true},
+	}
+
+	for _, tc := range cases {
+		actual, err := parseLogLevel(tc.Input)
+		if (err != nil) != tc.Err {
+			t.Fatalf("%q: err: %s", tc.Input, err)
+		}
+		if actual != tc.Expected {
+			t.Fatalf("%q: bad: %d", tc.Input, actual)
+		}
+	}
+}
+
+func TestLogFilter_level(t *testing.T) {
+	var buf bytes.Buffer
+	w := &logFilter{out: &buf, minLevel: 2}
+
+	w.Write([]byte("[DEBUG] dropped\n[INFO] kept\nno level "))
+	w.Write([]byte("kept\n[ERR] kept"))
+	w.Close()
+
+	expected := "[INFO] kept\nno level kept\n[ERR] kept"
+	if buf.String() != expected {
+		t.Fatalf("bad: %q", buf.String())
+	}
+}
+
+func TestLogFilter_builds(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	w := &logFilter{
+		path:  filepath.Join(dir, "packer-{{build_name}}.log"),
+		files: make(map[string]*os.File),
+	}
+	w.Write([]byte("2017/01/01 00:00:00 Starting build\n"))
+	w.Write([]byte("2017/01/01 00:00:00 [build qemu] packer-builder-qemu: booting\n"))
+	w.Write([]byte("2017/01/01 00:00:00 [build docker] packer-builder-docker: pulling\n"))
+	w.Close()
+
+	cases := map[string]string{
+		"packer-packer.log": "2017/01/01 00:00:00 Starting build\n",
+		"packer-qemu.log":   "2017/01/01 00:00:00 [build qemu] packer-builder-qemu: booting\n",
+		"packer-docker.log": "2017/01/01 00:00:00 [build docker] packer-builder-docker: pulling\n",
+	}
+	for name, expected := range cases {
+		actual, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if string(actual) != expected {
+			t.Fatalf("%s: bad: %q", name, actual)
+		}
+	}
+}
@@ -52,6 +52,9 @@ func realMain() int {
 		if logWriter == nil {
 			logWriter = ioutil.Discard
 		}
+		if f, ok := logWriter.(*logFilter); ok {
+			defer f.Close()
+		}
 
 		// We always send logs to a temporary file that we use in case
 		// there is a panic. Otherwise, we delete it.
@@ -111,7 +114,10 @@ func wrappedMain() int {
 		runtime.GOMAXPROCS(runtime.NumCPU())
 	}
 
-	log.SetOutput(os.Stderr)
+	// Redact the values of sensitive variables from the log, including
+	// the output of plugins which is logged by us.
+	packer.LogSecretFilter.Output = os.Stderr
+	log.SetOutput(&packer.LogSecretFilter)
 
 	log.Printf("[INFO] Packer version: %s", version.FormattedVersion())
 	log.Printf("Packer Target OS/Arch: %s %s", runtime.GOOS, runtime.GOARCH)
@@ -159,9 +165,15 @@ func wrappedMain() int {
 		Writer:      os.Stdout,
 		ErrorWriter: os.Stdout,
 	}
-	if machineReadable {
-		ui = &packer.MachineReadableUi{
-			Writer: os.Stdout,
+	if machineReadable != "" {
+		if machineReadable == "json" {
+			ui = &packer.JSONUi{
+				Writer: os.Stdout,
+			}
+		} else {
+			ui = &packer.MachineReadableUi{
+				Writer: os.Stdout,
+			}
 		}
 
 		// Set this so that we don't get colored output in our machine-
@@ -172,6 +184,21 @@ func wrappedMain() int {
 		}
 	}
 
+	// If requested, also write the output as JSON events for CI systems
+	if path := os.Getenv("PACKER_EVENT_LOG"); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Packer failed to open the event log: %s\n", err)
+			return 1
+		}
+		defer f.Close()
+
+		ui = &packer.EventUi{
+			Ui:     ui,
+			Writer: f,
+		}
+	}
+
 	// Create the CLI meta
 	CommandMeta = &command.Meta{
 		CoreConfig: &packer.CoreConfig{
@@ -181,7 +208,8 @@ func wrappedMain() int {
 				PostProcessor: config.LoadPostProcessor,
 				Provisioner:   config.LoadProvisioner,
 			},
-			Version: version.Version,
+			BuildComponents: config.BuildComponents,
+			Version:         version.Version,
 		},
 		Cache: cache,
 		Ui:    ui,
@@ -228,20 +256,28 @@ func excludeHelpFunc(commands map[string]cli.CommandFactory, exclude []string) c
 }
 
 // extractMachineReadable checks the args for the machine readable
-// flag and returns whether or not it is on. It modifies the args
-// to remove this flag.
-func extractMachineReadable(args []string) ([]string, bool) {
+// flag and returns the requested format, "text" or "json", or an empty
+// string if it is off. It modifies the args to remove this flag.
+func extractMachineReadable(args []string) ([]string, string) {
 	for i, arg := range args {
-		if arg == "-machine-readable" {
-			// We found it. Slice it out.
-			result := make([]string, len(args)-1)
-			copy(result, args[:i])
-			copy(result[i:], args[i+1:])
-			return result, true
+		var format string
+		switch arg {
+		case "-machine-readable", "-machine-readable=text":
+			format = "text"
+		case "-machine-readable=json":
+			format = "json"
+		default:
+			continue
 		}
+
+		// We found it. Slice it out.
+		result := make([]string, len(args)-1)
+		copy(result, args[:i])
+		copy(result[i:], args[i+1:])
+		return result, format
 	}
 
-	return args, false
+	return args, ""
 }
 
 func loadConfig() (*config, error) {
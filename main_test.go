@@ -35,7 +35,7 @@ func TestExcludeHelpFunc(t *testing.T) {
 
 func TestExtractMachineReadable(t *testing.T) {
 	var args, expected, result []string
-	var mr bool
+	var mr string
 
 	// Not
 	args = []string{"foo", "bar", "baz"}
@@ -45,7 +45,7 @@ func TestExtractMachineReadable(t *testing.T) {
 		t.Fatalf("bad: %#v", result)
 	}
 
-	if mr {
+	if mr != "" {
 		t.Fatal("should not be mr")
 	}
 
@@ -57,9 +57,21 @@ func TestExtractMachineReadable(t *testing.T) {
 		t.Fatalf("bad: %#v", result)
 	}
 
-	if !mr {
+	if mr != "text" {
 		t.Fatal("should be mr")
 	}
+
+	// JSON
+	args = []string{"foo", "-machine-readable=json", "baz"}
+	result, mr = extractMachineReadable(args)
+	expected = []string{"foo", "baz"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	if mr != "json" {
+		t.Fatalf("bad: %s", mr)
+	}
 }
 
 func TestRandom(t *testing.T) {
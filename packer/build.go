@@ -36,8 +36,26 @@ const (
 	// This key contains a map[string]string of the user variables for
 	// template processing.
 	UserVariablesConfigKey = "packer_user_variables"
+
+	// This key contains a map[string]interface{} of the communicator
+	// settings of the builder, such as ssh_host and ssh_username. It is
+	// only set for provisioners, so they can show how to connect to the
+	// machine.
+	CommunicatorConfigKey = "packer_communicator"
 )
 
+// communicatorConfigKeys are the communicator settings of a builder that
+// are passed to provisioners. Passwords and keys are left out.
+var communicatorConfigKeys = []string{
+	"communicator",
+	"ssh_host",
+	"ssh_port",
+	"ssh_username",
+	"winrm_host",
+	"winrm_port",
+	"winrm_username",
+}
+
 // A Build represents a single job within Packer that is responsible for
 // building some machine image artifact. Builds are meant to be parallelized.
 type Build interface {
@@ -96,6 +114,10 @@ type coreBuild struct {
 	templatePath   string
 	variables      map[string]string
 
+	// postProcessorSem is shared between the builds of a core to limit
+	// the number of concurrently running post-processors.
+	postProcessorSem chan struct{}
+
 	debug         bool
 	force         bool
 	onError       string
@@ -109,7 +131,7 @@ type coreBuildPostProcessor struct {
 	processor         PostProcessor
 	processorType     string
 	config            map[string]interface{}
-	keepInputArtifact bool
+	keepInputArtifact *bool
 }
 
 // Keeps track of the provisioner and the configuration of the provisioner
@@ -155,10 +177,13 @@ func (b *coreBuild) Prepare() (warn []string, err error) {
 	}
 
 	// Prepare the provisioners
+	provisionerConfig := map[string]interface{}{
+		CommunicatorConfigKey: communicatorConfig(b.builderConfig),
+	}
 	for _, coreProv := range b.provisioners {
-		configs := make([]interface{}, len(coreProv.config), len(coreProv.config)+1)
+		configs := make([]interface{}, len(coreProv.config), len(coreProv.config)+2)
 		copy(configs, coreProv.config)
-		configs = append(configs, packerConfig)
+		configs = append(configs, packerConfig, provisionerConfig)
 
 		if err = coreProv.provisioner.Prepare(configs...); err != nil {
 			return
@@ -242,7 +267,7 @@ PostProcessorRunSeqLoop:
 			}
 
 			builderUi.Say(fmt.Sprintf("Running post-processor: %s", corePP.processorType))
-			artifact, keep, err := corePP.processor.PostProcess(ppUi, priorArtifact)
+			artifact, keep, err := b.postProcess(ppUi, corePP, priorArtifact)
 			if err != nil {
 				errors = append(errors, fmt.Errorf("Post-processor failed: %s", err))
 				continue PostProcessorRunSeqLoop
@@ -253,7 +278,16 @@ PostProcessorRunSeqLoop:
 				continue PostProcessorRunSeqLoop
 			}
 
-			keep = keep || corePP.keepInputArtifact
+			if corePP.keepInputArtifact != nil {
+				if keep && !*corePP.keepInputArtifact {
+					ppUi.Say(fmt.Sprintf(
+						"Warning: keep_input_artifact is false, discarding the input "+
+							"artifact that post-processor '%s' keeps on its own. Its "+
+							"output may depend on the input.",
+						corePP.processorType))
+				}
+				keep = *corePP.keepInputArtifact
+			}
 			if i == 0 {
 				// This is the first post-processor. We handle deleting
 				// previous artifacts a bit different because multiple
@@ -332,3 +366,37 @@ func (b *coreBuild) SetOnError(val string) {
 func (b *coreBuild) Cancel() {
 	b.builder.Cancel()
 }
+
+// postProcess runs a single post-processor, waiting for a free slot first
+// if the number of concurrent post-processors is limited.
+func (b *coreBuild) postProcess(ui Ui, corePP coreBuildPostProcessor, artifact Artifact) (Artifact, bool, error) {
+	if b.postProcessorSem != nil {
+		select {
+		case b.postProcessorSem <- struct{}{}:
+		default:
+			ui.Say("Waiting for another post-processor to finish...")
+			b.postProcessorSem <- struct{}{}
+		}
+		defer func() { <-b.postProcessorSem }()
+	}
+
+	return corePP.processor.PostProcess(ui, artifact)
+}
+
+// communicatorConfig returns the communicator settings of the raw builder
+// configuration that are passed to provisioners.
+func communicatorConfig(raw interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return result
+	}
+
+	for _, k := range communicatorConfigKeys {
+		if v, ok := m[k]; ok {
+			result[k] = v
+		}
+	}
+
+	return result
+}
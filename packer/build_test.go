@@ -1,8 +1,13 @@
 package packer
 
 import (
+	"bytes"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func testBuild() *coreBuild {
@@ -19,7 +24,7 @@ func testBuild() *coreBuild {
 		},
 		postProcessors: [][]coreBuildPostProcessor{
 			{
-				{&MockPostProcessor{ArtifactId: "pp"}, "testPP", make(map[string]interface{}), true},
+				{&MockPostProcessor{ArtifactId: "pp"}, "testPP", make(map[string]interface{}), boolPointer(true)},
 			},
 		},
 		variables: make(map[string]string),
@@ -27,6 +32,10 @@ func testBuild() *coreBuild {
 	}
 }
 
+func boolPointer(v bool) *bool {
+	return &v
+}
+
 func testDefaultPackerConfig() map[string]interface{} {
 	return map[string]interface{}{
 		BuildNameConfigKey:     "test",
@@ -38,6 +47,12 @@ func testDefaultPackerConfig() map[string]interface{} {
 		UserVariablesConfigKey: make(map[string]string),
 	}
 }
+func testProvisionerConfig() map[string]interface{} {
+	return map[string]interface{}{
+		CommunicatorConfigKey: make(map[string]interface{}),
+	}
+}
+
 func TestBuild_Name(t *testing.T) {
 	build := testBuild()
 	if build.Name() != "test" {
@@ -64,7 +79,7 @@ func TestBuild_Prepare(t *testing.T) {
 	if !prov.PrepCalled {
 		t.Fatal("prep should be called")
 	}
-	if !reflect.DeepEqual(prov.PrepConfigs, []interface{}{42, packerConfig}) {
+	if !reflect.DeepEqual(prov.PrepConfigs, []interface{}{42, packerConfig, testProvisionerConfig()}) {
 		t.Fatalf("bad: %#v", prov.PrepConfigs)
 	}
 
@@ -139,11 +154,32 @@ func TestBuild_Prepare_Debug(t *testing.T) {
 	if !prov.PrepCalled {
 		t.Fatal("prepare should be called")
 	}
-	if !reflect.DeepEqual(prov.PrepConfigs, []interface{}{42, packerConfig}) {
+	if !reflect.DeepEqual(prov.PrepConfigs, []interface{}{42, packerConfig, testProvisionerConfig()}) {
 		t.Fatalf("bad: %#v", prov.PrepConfigs)
 	}
 }
 
+func TestBuild_Prepare_communicator(t *testing.T) {
+	build := testBuild()
+	build.builderConfig = map[string]interface{}{
+		"ssh_host":     "127.0.0.1",
+		"ssh_username": "packer",
+		"ssh_password": "secret",
+	}
+	build.Prepare()
+
+	prov := build.provisioners[0].provisioner.(*MockProvisioner)
+	expected := map[string]interface{}{
+		CommunicatorConfigKey: map[string]interface{}{
+			"ssh_host":     "127.0.0.1",
+			"ssh_username": "packer",
+		},
+	}
+	if !reflect.DeepEqual(prov.PrepConfigs[2], expected) {
+		t.Fatalf("bad: %#v", prov.PrepConfigs[2])
+	}
+}
+
 func TestBuildPrepare_variables_default(t *testing.T) {
 	packerConfig := testDefaultPackerConfig()
 	packerConfig[UserVariablesConfigKey] = map[string]string{
@@ -247,7 +283,7 @@ func TestBuild_Run_Artifacts(t *testing.T) {
 	build = testBuild()
 	build.postProcessors = [][]coreBuildPostProcessor{
 		{
-			{&MockPostProcessor{ArtifactId: "pp"}, "pp", make(map[string]interface{}), false},
+			{&MockPostProcessor{ArtifactId: "pp"}, "pp", make(map[string]interface{}), nil},
 		},
 	}
 
@@ -272,10 +308,10 @@ func TestBuild_Run_Artifacts(t *testing.T) {
 	build = testBuild()
 	build.postProcessors = [][]coreBuildPostProcessor{
 		{
-			{&MockPostProcessor{ArtifactId: "pp1"}, "pp", make(map[string]interface{}), false},
+			{&MockPostProcessor{ArtifactId: "pp1"}, "pp", make(map[string]interface{}), nil},
 		},
 		{
-			{&MockPostProcessor{ArtifactId: "pp2"}, "pp", make(map[string]interface{}), true},
+			{&MockPostProcessor{ArtifactId: "pp2"}, "pp", make(map[string]interface{}), boolPointer(true)},
 		},
 	}
 
@@ -300,12 +336,12 @@ func TestBuild_Run_Artifacts(t *testing.T) {
 	build = testBuild()
 	build.postProcessors = [][]coreBuildPostProcessor{
 		{
-			{&MockPostProcessor{ArtifactId: "pp1a"}, "pp", make(map[string]interface{}), false},
-			{&MockPostProcessor{ArtifactId: "pp1b"}, "pp", make(map[string]interface{}), true},
+			{&MockPostProcessor{ArtifactId: "pp1a"}, "pp", make(map[string]interface{}), nil},
+			{&MockPostProcessor{ArtifactId: "pp1b"}, "pp", make(map[string]interface{}), boolPointer(true)},
 		},
 		{
-			{&MockPostProcessor{ArtifactId: "pp2a"}, "pp", make(map[string]interface{}), false},
-			{&MockPostProcessor{ArtifactId: "pp2b"}, "pp", make(map[string]interface{}), false},
+			{&MockPostProcessor{ArtifactId: "pp2a"}, "pp", make(map[string]interface{}), nil},
+			{&MockPostProcessor{ArtifactId: "pp2b"}, "pp", make(map[string]interface{}), nil},
 		},
 	}
 
@@ -331,7 +367,7 @@ func TestBuild_Run_Artifacts(t *testing.T) {
 	build.postProcessors = [][]coreBuildPostProcessor{
 		{
 			{
-				&MockPostProcessor{ArtifactId: "pp", Keep: true}, "pp", make(map[string]interface{}), false,
+				&MockPostProcessor{ArtifactId: "pp", Keep: true}, "pp", make(map[string]interface{}), nil,
 			},
 		},
 	}
@@ -351,6 +387,37 @@ func TestBuild_Run_Artifacts(t *testing.T) {
 	if !reflect.DeepEqual(artifactIds, expectedIds) {
 		t.Fatalf("unexpected ids: %#v", artifactIds)
 	}
+
+	// Test case: Test that keep_input_artifact set to false overrides a
+	// post-processor that wants to keep its input, with a warning.
+	ui = testUi()
+	build = testBuild()
+	build.postProcessors = [][]coreBuildPostProcessor{
+		{
+			{
+				&MockPostProcessor{ArtifactId: "pp", Keep: true}, "pp", make(map[string]interface{}), boolPointer(false),
+			},
+		},
+	}
+
+	build.Prepare()
+	artifacts, err = build.Run(ui, cache)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expectedIds = []string{"pp"}
+	artifactIds = make([]string, len(artifacts))
+	for i, artifact := range artifacts {
+		artifactIds[i] = artifact.Id()
+	}
+
+	if !reflect.DeepEqual(artifactIds, expectedIds) {
+		t.Fatalf("unexpected ids: %#v", artifactIds)
+	}
+	if out := ui.Writer.(*bytes.Buffer).String(); !strings.Contains(out, "Warning: keep_input_artifact is false") {
+		t.Fatalf("should warn: %s", out)
+	}
 }
 
 func TestBuild_RunBeforePrepare(t *testing.T) {
@@ -368,6 +435,63 @@ func TestBuild_RunBeforePrepare(t *testing.T) {
 	testBuild().Run(testUi(), &TestCache{})
 }
 
+// concurrencyPostProcessor records the highest number of post-processors
+// it has seen running at the same time.
+type concurrencyPostProcessor struct {
+	running int32
+	max     int32
+}
+
+func (p *concurrencyPostProcessor) Configure(...interface{}) error {
+	return nil
+}
+
+func (p *concurrencyPostProcessor) PostProcess(ui Ui, a Artifact) (Artifact, bool, error) {
+	n := atomic.AddInt32(&p.running, 1)
+	defer atomic.AddInt32(&p.running, -1)
+	for {
+		max := atomic.LoadInt32(&p.max)
+		if n <= max || atomic.CompareAndSwapInt32(&p.max, max, n) {
+			break
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	return &MockArtifact{IdValue: "pp"}, false, nil
+}
+
+func TestBuild_Run_postProcessorConcurrency(t *testing.T) {
+	pp := new(concurrencyPostProcessor)
+	sem := make(chan struct{}, 1)
+
+	builds := make([]*coreBuild, 3)
+	for i := range builds {
+		build := testBuild()
+		build.postProcessors = [][]coreBuildPostProcessor{
+			{{pp, "testPP", make(map[string]interface{}), nil}},
+		}
+		build.postProcessorSem = sem
+		build.Prepare()
+		builds[i] = build
+	}
+
+	var wg sync.WaitGroup
+	for _, build := range builds {
+		wg.Add(1)
+		go func(b *coreBuild) {
+			defer wg.Done()
+			if _, err := b.Run(testUi(), &TestCache{}); err != nil {
+				t.Errorf("err: %s", err)
+			}
+		}(build)
+	}
+	wg.Wait()
+
+	if pp.max != 1 {
+		t.Fatalf("bad: %d post-processors ran at the same time", pp.max)
+	}
+}
+
 func TestBuild_Cancel(t *testing.T) {
 	build := testBuild()
 	build.Cancel()
@@ -39,10 +39,15 @@ type Cache interface {
 
 // FileCache implements a Cache by caching the data directly to a cache
 // directory.
+//
+// Locks for writing are also held on a lock file next to the cached file,
+// so that separate Packer processes sharing the cache directory don't
+// write to the same file at the same time.
 type FileCache struct {
 	CacheDir string
 	l        sync.Mutex
 	rw       map[string]*sync.RWMutex
+	files    map[string]*os.File
 }
 
 func (f *FileCache) Lock(key string) string {
@@ -50,11 +55,32 @@ func (f *FileCache) Lock(key string) string {
 	rw := f.rwLock(hashKey)
 	rw.Lock()
 
-	return f.cachePath(key, hashKey)
+	path := f.cachePath(key, hashKey)
+	lockF, err := lockFile(path + ".lock")
+	if err != nil {
+		log.Printf("[ERR] Error locking cache file, other processes may write to it: %s", err)
+	} else {
+		f.l.Lock()
+		if f.files == nil {
+			f.files = make(map[string]*os.File)
+		}
+		f.files[hashKey] = lockF
+		f.l.Unlock()
+	}
+
+	return path
 }
 
 func (f *FileCache) Unlock(key string) {
 	hashKey := f.hashKey(key)
+
+	f.l.Lock()
+	if lockF, ok := f.files[hashKey]; ok {
+		lockF.Close()
+		delete(f.files, hashKey)
+	}
+	f.l.Unlock()
+
 	rw := f.rwLock(hashKey)
 	rw.Unlock()
 }
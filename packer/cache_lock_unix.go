@@ -0,0 +1,32 @@
+// +build darwin freebsd linux netbsd openbsd
+
+package packer
+
+import (
+	"log"
+	"os"
+	"syscall"
+)
+
+// lockFile opens the file at path and takes an exclusive lock on it,
+// waiting for other processes holding the lock. The lock is released when
+// the returned file is closed or the process exits.
+func lockFile(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	// Try without blocking first so we can log that we're waiting
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err == nil {
+		return f, nil
+	}
+
+	log.Printf("Waiting for another process to release cache lock: %s", path)
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return f, nil
+}
@@ -0,0 +1,46 @@
+package packer
+
+import (
+	"log"
+	"os"
+	"syscall"
+	"time"
+)
+
+// errorSharingViolation is returned by CreateFile when another process
+// has the file open without sharing it.
+const errorSharingViolation syscall.Errno = 32
+
+// lockFile opens the file at path without sharing it, waiting for other
+// processes that have it open. The lock is released when the returned file
+// is closed or the process exits.
+func lockFile(path string) (*os.File, error) {
+	pathp, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+
+	waiting := false
+	for {
+		h, err := syscall.CreateFile(
+			pathp,
+			syscall.GENERIC_READ|syscall.GENERIC_WRITE,
+			0,
+			nil,
+			syscall.OPEN_ALWAYS,
+			syscall.FILE_ATTRIBUTE_NORMAL,
+			0)
+		if err == nil {
+			return os.NewFile(uintptr(h), path), nil
+		}
+		if err != errorSharingViolation {
+			return nil, err
+		}
+
+		if !waiting {
+			log.Printf("Waiting for another process to release cache lock: %s", path)
+			waiting = true
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 type TestCache struct{}
@@ -80,3 +81,38 @@ func TestFileCache(t *testing.T) {
 		t.Fatalf("unknown data: %s", data)
 	}
 }
+
+func TestFileCache_lockSharedDir(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("error creating temporary dir: %s", err)
+	}
+	defer os.RemoveAll(cacheDir)
+
+	// Two caches on the same directory act like two Packer processes
+	first := &FileCache{CacheDir: cacheDir}
+	second := &FileCache{CacheDir: cacheDir}
+
+	first.Lock("foo.iso")
+
+	locked := make(chan struct{})
+	go func() {
+		second.Lock("foo.iso")
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("should wait for the first lock to be released")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	first.Unlock("foo.iso")
+
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("should lock once the first lock is released")
+	}
+	second.Unlock("foo.iso")
+}
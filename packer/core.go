@@ -15,10 +15,15 @@ import (
 type Core struct {
 	Template *template.Template
 
-	components ComponentFinder
-	variables  map[string]string
-	builds     map[string]*template.Builder
-	version    string
+	components      ComponentFinder
+	buildComponents func(string) ComponentFinder
+	variables       map[string]string
+	builds          map[string]*template.Builder
+	version         string
+
+	// postProcessorSem limits how many post-processors may run at the
+	// same time across all builds of this core. It is nil if unlimited.
+	postProcessorSem chan struct{}
 }
 
 // CoreConfig is the structure for initializing a new Core. Once a CoreConfig
@@ -28,6 +33,16 @@ type CoreConfig struct {
 	Template   *template.Template
 	Variables  map[string]string
 	Version    string
+
+	// PostProcessorConcurrency is the maximum number of post-processors
+	// that may run at the same time across all builds. Zero or less means
+	// there is no limit.
+	PostProcessorConcurrency int
+
+	// BuildComponents, if set, returns the ComponentFinder used to look
+	// up the components of the given build instead of Components. This
+	// lets the components of each build be told apart, e.g. in the logs.
+	BuildComponents func(build string) ComponentFinder
 }
 
 // The function type used to lookup Builder implementations.
@@ -55,10 +70,14 @@ type ComponentFinder struct {
 // NewCore creates a new Core.
 func NewCore(c *CoreConfig) (*Core, error) {
 	result := &Core{
-		Template:   c.Template,
-		components: c.Components,
-		variables:  c.Variables,
-		version:    c.Version,
+		Template:        c.Template,
+		components:      c.Components,
+		buildComponents: c.BuildComponents,
+		variables:       c.Variables,
+		version:         c.Version,
+	}
+	if c.PostProcessorConcurrency > 0 {
+		result.postProcessorSem = make(chan struct{}, c.PostProcessorConcurrency)
 	}
 	if err := result.validate(); err != nil {
 		return nil, err
@@ -102,7 +121,13 @@ func (c *Core) Build(n string) (Build, error) {
 	if !ok {
 		return nil, fmt.Errorf("no such build found: %s", n)
 	}
-	builder, err := c.components.Builder(configBuilder.Type)
+
+	components := c.components
+	if c.buildComponents != nil {
+		components = c.buildComponents(n)
+	}
+
+	builder, err := components.Builder(configBuilder.Type)
 	if err != nil {
 		return nil, fmt.Errorf(
 			"error initializing builder '%s': %s",
@@ -125,7 +150,7 @@ func (c *Core) Build(n string) (Build, error) {
 		}
 
 		// Get the provisioner
-		provisioner, err := c.components.Provisioner(rawP.Type)
+		provisioner, err := components.Provisioner(rawP.Type)
 		if err != nil {
 			return nil, fmt.Errorf(
 				"error initializing provisioner '%s': %s",
@@ -145,6 +170,25 @@ func (c *Core) Build(n string) (Build, error) {
 			}
 		}
 
+		// If there is a timeout, we wrap the provisioner so it is
+		// cancelled when it runs too long. This happens before pausing
+		// so the pause doesn't count towards the timeout.
+		if rawP.Timeout > 0 {
+			provisioner = &TimeoutProvisioner{
+				Timeout:     rawP.Timeout,
+				Provisioner: provisioner,
+			}
+		}
+
+		// If the provisioner should be retried, we wrap it so it runs
+		// again when it fails. Each attempt has its own timeout.
+		if rawP.MaxRetries > 0 {
+			provisioner = &RetriedProvisioner{
+				MaxRetries:  rawP.MaxRetries,
+				Provisioner: provisioner,
+			}
+		}
+
 		// If we're pausing, we wrap the provisioner in a special pauser.
 		if rawP.PauseBefore > 0 {
 			provisioner = &PausedProvisioner{
@@ -170,7 +214,7 @@ func (c *Core) Build(n string) (Build, error) {
 			}
 
 			// Get the post-processor
-			postProcessor, err := c.components.PostProcessor(rawP.Type)
+			postProcessor, err := components.PostProcessor(rawP.Type)
 			if err != nil {
 				return nil, fmt.Errorf(
 					"error initializing post-processor '%s': %s",
@@ -181,6 +225,10 @@ func (c *Core) Build(n string) (Build, error) {
 					"post-processor type not found: %s", rawP.Type)
 			}
 
+			if rawP.VerifyChecksums {
+				postProcessor = &verifyingPostProcessor{postProcessor}
+			}
+
 			current = append(current, coreBuildPostProcessor{
 				processor:         postProcessor,
 				processorType:     rawP.Type,
@@ -208,6 +256,8 @@ func (c *Core) Build(n string) (Build, error) {
 		provisioners:   provisioners,
 		templatePath:   c.Template.Path,
 		variables:      c.variables,
+
+		postProcessorSem: c.postProcessorSem,
 	}, nil
 }
 
@@ -254,11 +304,24 @@ func (c *Core) validate() error {
 
 	// Validate variables are set
 	var err error
-	for n, v := range c.Template.Variables {
-		if v.Required {
-			if _, ok := c.variables[n]; !ok {
+	names := make([]string, 0, len(c.Template.Variables))
+	for n := range c.Template.Variables {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for _, n := range names {
+		v := c.Template.Variables[n]
+		value, ok := c.variables[n]
+		if v.Required && !ok {
+			err = multierror.Append(err, fmt.Errorf(
+				"required variable not set: %s", n))
+		}
+
+		if ok {
+			if verr := v.ValidateValue(value); verr != nil {
 				err = multierror.Append(err, fmt.Errorf(
-					"required variable not set: %s", n))
+					"variable %s: %s", n, verr))
 			}
 		}
 	}
@@ -301,6 +364,13 @@ func (c *Core) init() error {
 		c.variables[k] = def
 	}
 
+	// Redact the values of sensitive variables from the output and logs
+	for k, v := range c.Template.Variables {
+		if v.Sensitive {
+			LogSecretFilter.Set(c.variables[k])
+		}
+	}
+
 	// Interpolate the push configuration
 	if _, err := interpolate.RenderInterface(&c.Template.Push, c.Context()); err != nil {
 		return fmt.Errorf("Error interpolating 'push': %s", err)
@@ -80,6 +80,27 @@ func TestCoreBuild_basic(t *testing.T) {
 	}
 }
 
+func TestCoreBuild_buildComponents(t *testing.T) {
+	config := TestCoreConfig(t)
+	testCoreTemplate(t, config, fixtureDir("build-basic.json"))
+	TestBuilder(t, config, "test")
+
+	var builds []string
+	config.BuildComponents = func(build string) ComponentFinder {
+		builds = append(builds, build)
+		return config.Components
+	}
+	core := TestCore(t, config)
+
+	if _, err := core.Build("test"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !reflect.DeepEqual(builds, []string{"test"}) {
+		t.Fatalf("bad: %#v", builds)
+	}
+}
+
 func TestCoreBuild_basicInterpolated(t *testing.T) {
 	config := TestCoreConfig(t)
 	testCoreTemplate(t, config, fixtureDir("build-basic-interpolated.json"))
@@ -142,6 +163,17 @@ func TestCoreBuild_env(t *testing.T) {
 	}
 }
 
+func TestCore_sensitiveVariables(t *testing.T) {
+	config := TestCoreConfig(t)
+	testCoreTemplate(t, config, fixtureDir("sensitive-variables.json"))
+	TestCore(t, config)
+
+	actual := LogSecretFilter.FilterString("password: core-sensitive-secret")
+	if actual != "password: <sensitive>" {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
 func TestCoreBuild_buildNameVar(t *testing.T) {
 	config := TestCoreConfig(t)
 	testCoreTemplate(t, config, fixtureDir("build-var-build-name.json"))
@@ -485,6 +517,19 @@ func TestCoreValidate(t *testing.T) {
 			false,
 		},
 
+		// Typed variables
+		{
+			"validate-typed-variable.json",
+			map[string]string{"isos": `["a.iso", "b.iso"]`},
+			false,
+		},
+
+		{
+			"validate-typed-variable.json",
+			map[string]string{"isos": "a.iso"},
+			true,
+		},
+
 		// Min version good
 		{
 			"validate-min-version.json",
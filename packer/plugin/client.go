@@ -64,6 +64,11 @@ type ClientConfig struct {
 	// If non-nil, then the stderr of the client will be written to here
 	// (as well as the log).
 	Stderr io.Writer
+
+	// Build is the name of the build the plugin is used for, if any. The
+	// lines of stderr are tagged with it in the log as "[build NAME]" so
+	// that the log can be split up by build.
+	Build string
 }
 
 // This makes sure all the managed subprocesses are killed and properly
@@ -305,21 +310,28 @@ func (c *Client) Start() (addr net.Addr, err error) {
 	case <-timeout:
 		err = errors.New("timeout while waiting for plugin to start")
 	case <-exitCh:
-		err = errors.New("plugin exited before we could connect")
+		err = fmt.Errorf(
+			"plugin %s exited before we could connect", cmd.Path)
 	case lineBytes := <-linesCh:
 		// Trim the line and split by "|" in order to get the parts of
 		// the output.
 		line := strings.TrimSpace(string(lineBytes))
 		parts := strings.SplitN(line, "|", 3)
 		if len(parts) < 3 {
-			err = fmt.Errorf("Unrecognized remote plugin message: %s", line)
+			err = fmt.Errorf(
+				"Unrecognized remote plugin message from %s: %s\n\n"+
+					"This usually means that it is not a Packer plugin.",
+				cmd.Path, line)
 			return
 		}
 
 		// Test the API version
 		if parts[0] != APIVersion {
-			err = fmt.Errorf("Incompatible API version with plugin. "+
-				"Plugin version: %s, Ours: %s", parts[0], APIVersion)
+			err = fmt.Errorf("Incompatible API version with plugin %s. "+
+				"Plugin version: %s, Ours: %s\n\n"+
+				"The plugin must be rebuilt against a version of Packer "+
+				"with the same plugin API version.",
+				cmd.Path, parts[0], APIVersion)
 			return
 		}
 
@@ -345,7 +357,12 @@ func (c *Client) logStderr(r io.Reader) {
 			c.config.Stderr.Write([]byte(line))
 
 			line = strings.TrimRightFunc(line, unicode.IsSpace)
-			log.Printf("%s: %s", filepath.Base(c.config.Cmd.Path), line)
+			if c.config.Build != "" {
+				log.Printf("[build %s] %s: %s",
+					c.config.Build, filepath.Base(c.config.Cmd.Path), line)
+			} else {
+				log.Printf("%s: %s", filepath.Base(c.config.Cmd.Path), line)
+			}
 		}
 
 		if err == io.EOF {
@@ -54,6 +54,27 @@ func TestClientStart_badVersion(t *testing.T) {
 	if err == nil {
 		t.Fatal("err should not be nil")
 	}
+	if !strings.Contains(err.Error(), "Incompatible API version") {
+		t.Fatalf("bad: %s", err)
+	}
+}
+
+func TestClientStart_notPlugin(t *testing.T) {
+	config := &ClientConfig{
+		Cmd:          helperProcess("not-plugin"),
+		StartTimeout: 50 * time.Millisecond,
+	}
+
+	c := NewClient(config)
+	defer c.Kill()
+
+	_, err := c.Start()
+	if err == nil {
+		t.Fatal("err should not be nil")
+	}
+	if !strings.Contains(err.Error(), "not a Packer plugin") {
+		t.Fatalf("bad: %s", err)
+	}
 }
 
 func TestClient_Start_Timeout(t *testing.T) {
@@ -53,6 +53,9 @@ func TestHelperProcess(*testing.T) {
 	case "bad-version":
 		fmt.Printf("%s1|tcp|:1234\n", APIVersion)
 		<-make(chan int)
+	case "not-plugin":
+		fmt.Println("hello")
+		<-make(chan int)
 	case "builder":
 		server, err := Server()
 		if err != nil {
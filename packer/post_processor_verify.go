@@ -0,0 +1,159 @@
+package packer
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+)
+
+// checksumSidecarTypes are the extensions of sidecar files that hold the
+// checksum of the file they are named after, e.g. "disk.img.sha256".
+var checksumSidecarTypes = []string{"sha512", "sha256", "sha1", "md5"}
+
+// verifyingPostProcessor wraps a PostProcessor and verifies the checksums
+// of its input artifact's files before post-processing them.
+type verifyingPostProcessor struct {
+	PostProcessor
+}
+
+func (p *verifyingPostProcessor) PostProcess(ui Ui, artifact Artifact) (Artifact, bool, error) {
+	if err := VerifyArtifactChecksums(ui, artifact); err != nil {
+		return nil, false, err
+	}
+
+	return p.PostProcessor.PostProcess(ui, artifact)
+}
+
+// VerifyArtifactChecksums verifies the files of an artifact against the
+// checksums recorded for them. Checksums are read from the artifact's
+// "checksums" state, a map of file path to hex encoded checksum, or from a
+// sidecar file next to each file named after it with a checksum type
+// extension (".sha512", ".sha256", ".sha1" or ".md5"). The checksum type of
+// state values is inferred from their length. Files without a recorded
+// checksum are skipped.
+func VerifyArtifactChecksums(ui Ui, artifact Artifact) error {
+	recorded := checksumsState(artifact.State("checksums"))
+
+	for _, path := range artifact.Files() {
+		checksumType, expected, err := recordedChecksum(path, recorded)
+		if err != nil {
+			return err
+		}
+		if expected == "" {
+			log.Printf("No checksum recorded for %s, not verifying", path)
+			continue
+		}
+
+		ui.Message(fmt.Sprintf("Verifying %s checksum of %s", checksumType, path))
+		actual, err := fileChecksum(path, checksumType)
+		if err != nil {
+			return fmt.Errorf("Error calculating checksum of %s: %s", path, err)
+		}
+		if !strings.EqualFold(actual, expected) {
+			return fmt.Errorf(
+				"Checksum mismatch for %s: expected %s, got %s", path, expected, actual)
+		}
+	}
+
+	return nil
+}
+
+// checksumsState converts the "checksums" state of an artifact to a map of
+// file path to checksum. Artifacts of plugins are received over RPC, which
+// decodes the map as map[interface{}]interface{}.
+func checksumsState(v interface{}) map[string]string {
+	result := make(map[string]string)
+	switch v := v.(type) {
+	case map[string]string:
+		return v
+	case map[string]interface{}:
+		for k, e := range v {
+			if s, ok := e.(string); ok {
+				result[k] = s
+			}
+		}
+	case map[interface{}]interface{}:
+		for k, e := range v {
+			ks, kok := k.(string)
+			es, eok := e.(string)
+			if kok && eok {
+				result[ks] = es
+			}
+		}
+	}
+
+	return result
+}
+
+// recordedChecksum returns the checksum type and value recorded for the
+// file at path, or empty strings if there is none.
+func recordedChecksum(path string, recorded map[string]string) (string, string, error) {
+	if v, ok := recorded[path]; ok {
+		switch len(v) {
+		case md5.Size * 2:
+			return "md5", v, nil
+		case sha1.Size * 2:
+			return "sha1", v, nil
+		case sha256.Size * 2:
+			return "sha256", v, nil
+		case sha512.Size * 2:
+			return "sha512", v, nil
+		default:
+			return "", "", fmt.Errorf("Unknown checksum type for %s: %s", path, v)
+		}
+	}
+
+	for _, t := range checksumSidecarTypes {
+		contents, err := ioutil.ReadFile(path + "." + t)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", "", err
+		}
+
+		// Sidecar files are either just the checksum, or the output of
+		// tools like sha256sum: "<checksum>  <file>".
+		fields := strings.Fields(string(contents))
+		if len(fields) == 0 {
+			return "", "", fmt.Errorf("Empty checksum file: %s.%s", path, t)
+		}
+		return t, fields[0], nil
+	}
+
+	return "", "", nil
+}
+
+func fileChecksum(path, checksumType string) (string, error) {
+	var h hash.Hash
+	switch checksumType {
+	case "md5":
+		h = md5.New()
+	case "sha1":
+		h = sha1.New()
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
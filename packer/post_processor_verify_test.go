@@ -0,0 +1,87 @@
+package packer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testChecksumSha256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func testChecksumFile(t *testing.T) (string, func()) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	path := filepath.Join(td, "disk.img")
+	if err := ioutil.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return path, func() { os.RemoveAll(td) }
+}
+
+func TestVerifyArtifactChecksums_state(t *testing.T) {
+	path, cleanup := testChecksumFile(t)
+	defer cleanup()
+
+	artifact := &MockArtifact{
+		FilesValue:  []string{path},
+		StateValues: map[string]interface{}{"checksums": map[string]string{path: testChecksumSha256}},
+	}
+	if err := VerifyArtifactChecksums(TestUi(t), artifact); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	artifact.StateValues["checksums"] = map[string]string{path: "d41d8cd98f00b204e9800998ecf8427e"}
+	if err := VerifyArtifactChecksums(TestUi(t), artifact); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestVerifyArtifactChecksums_sidecar(t *testing.T) {
+	path, cleanup := testChecksumFile(t)
+	defer cleanup()
+
+	artifact := &MockArtifact{FilesValue: []string{path}}
+
+	// No checksum recorded
+	if err := VerifyArtifactChecksums(TestUi(t), artifact); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	sidecar := []byte(testChecksumSha256 + "  disk.img\n")
+	if err := ioutil.WriteFile(path+".sha256", sidecar, 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := VerifyArtifactChecksums(TestUi(t), artifact); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("corrupted"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := VerifyArtifactChecksums(TestUi(t), artifact); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestVerifyingPostProcessor(t *testing.T) {
+	path, cleanup := testChecksumFile(t)
+	defer cleanup()
+
+	artifact := &MockArtifact{
+		FilesValue:  []string{path},
+		StateValues: map[string]interface{}{"checksums": map[string]string{path: "bad"}},
+	}
+
+	pp := &MockPostProcessor{}
+	if _, _, err := (&verifyingPostProcessor{pp}).PostProcess(TestUi(t), artifact); err == nil {
+		t.Fatal("should have error")
+	}
+	if pp.PostProcessCalled {
+		t.Fatal("post-processor should not run")
+	}
+}
@@ -2,6 +2,7 @@ package packer
 
 import (
 	"fmt"
+	"log"
 	"sync"
 	"time"
 )
@@ -156,3 +157,125 @@ func (p *PausedProvisioner) Cancel() {
 func (p *PausedProvisioner) provision(result chan<- error, ui Ui, comm Communicator) {
 	result <- p.Provisioner.Provision(ui, comm)
 }
+
+// TimeoutProvisioner is a Provisioner implementation that cancels the
+// provisioner and fails if it doesn't finish within the timeout.
+type TimeoutProvisioner struct {
+	Timeout     time.Duration
+	Provisioner Provisioner
+}
+
+// timeoutCancelWait is how long a timed out provisioner is given to stop
+// after it is cancelled.
+var timeoutCancelWait = 1 * time.Minute
+
+func (p *TimeoutProvisioner) Prepare(raws ...interface{}) error {
+	return p.Provisioner.Prepare(raws...)
+}
+
+func (p *TimeoutProvisioner) Provision(ui Ui, comm Communicator) error {
+	provDoneCh := make(chan error, 1)
+	go func() {
+		provDoneCh <- p.Provisioner.Provision(ui, comm)
+	}()
+
+	select {
+	case err := <-provDoneCh:
+		return err
+	case <-time.After(p.Timeout):
+	}
+
+	ui.Error(fmt.Sprintf("Provisioner didn't finish within %s, cancelling...", p.Timeout))
+	p.Provisioner.Cancel()
+	select {
+	case <-provDoneCh:
+	case <-time.After(timeoutCancelWait):
+		log.Printf("Provisioner didn't stop within %s of being cancelled", timeoutCancelWait)
+	}
+
+	return &provisionerTimeoutError{Timeout: p.Timeout}
+}
+
+// provisionerTimeoutError is the error of a provisioner that timed out.
+type provisionerTimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *provisionerTimeoutError) Error() string {
+	return fmt.Sprintf("Provisioner timed out after %s", e.Timeout)
+}
+
+func (p *TimeoutProvisioner) Cancel() {
+	p.Provisioner.Cancel()
+}
+
+// RetriedProvisioner is a Provisioner implementation that runs the
+// provisioner again when it fails, up to MaxRetries times. Provisioners
+// that timed out aren't retried: they have been cancelled, and the plugins
+// of cancelled provisioners exit.
+type RetriedProvisioner struct {
+	MaxRetries  int
+	Provisioner Provisioner
+
+	cancelCh chan struct{}
+	lock     sync.Mutex
+}
+
+// provisionerRetryDelay is how long to wait before retrying a failed
+// provisioner.
+var provisionerRetryDelay = 5 * time.Second
+
+func (p *RetriedProvisioner) Prepare(raws ...interface{}) error {
+	return p.Provisioner.Prepare(raws...)
+}
+
+func (p *RetriedProvisioner) Provision(ui Ui, comm Communicator) error {
+	p.lock.Lock()
+	cancelCh := make(chan struct{})
+	p.cancelCh = cancelCh
+	p.lock.Unlock()
+
+	defer func() {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		if p.cancelCh == cancelCh {
+			p.cancelCh = nil
+		}
+	}()
+
+	for i := 0; ; i++ {
+		err := p.Provisioner.Provision(ui, comm)
+		if err == nil || i >= p.MaxRetries {
+			return err
+		}
+		if _, ok := err.(*provisionerTimeoutError); ok {
+			ui.Error("Provisioner timed out, not retrying since it was cancelled")
+			return err
+		}
+
+		select {
+		case <-cancelCh:
+			return err
+		default:
+		}
+
+		ui.Error(fmt.Sprintf(
+			"Provisioner failed, retrying (%d/%d): %s", i+1, p.MaxRetries, err))
+		select {
+		case <-time.After(provisionerRetryDelay):
+		case <-cancelCh:
+			return err
+		}
+	}
+}
+
+func (p *RetriedProvisioner) Cancel() {
+	p.lock.Lock()
+	if p.cancelCh != nil {
+		close(p.cancelCh)
+		p.cancelCh = nil
+	}
+	p.lock.Unlock()
+
+	p.Provisioner.Cancel()
+}
@@ -1,6 +1,8 @@
 package packer
 
 import (
+	"errors"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -189,3 +191,155 @@ func TestPausedProvisionerCancel(t *testing.T) {
 		t.Fatal("cancel should be called")
 	}
 }
+
+func TestTimeoutProvisioner_impl(t *testing.T) {
+	var _ Provisioner = new(TimeoutProvisioner)
+}
+
+func TestTimeoutProvisionerProvision(t *testing.T) {
+	mock := new(MockProvisioner)
+	prov := &TimeoutProvisioner{
+		Timeout:     time.Minute,
+		Provisioner: mock,
+	}
+
+	if err := prov.Provision(testUi(), new(MockCommunicator)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !mock.ProvCalled {
+		t.Fatal("prov should be called")
+	}
+	if mock.CancelCalled {
+		t.Fatal("cancel should not be called")
+	}
+}
+
+func TestTimeoutProvisionerProvision_timeout(t *testing.T) {
+	mock := new(MockProvisioner)
+	prov := &TimeoutProvisioner{
+		Timeout:     10 * time.Millisecond,
+		Provisioner: mock,
+	}
+
+	mock.ProvFunc = func() error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}
+
+	err := prov.Provision(testUi(), new(MockCommunicator))
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("bad: %s", err)
+	}
+	if !mock.CancelCalled {
+		t.Fatal("cancel should be called")
+	}
+}
+
+func TestRetriedProvisioner_impl(t *testing.T) {
+	var _ Provisioner = new(RetriedProvisioner)
+}
+
+func TestRetriedProvisionerProvision(t *testing.T) {
+	old := provisionerRetryDelay
+	provisionerRetryDelay = time.Millisecond
+	defer func() { provisionerRetryDelay = old }()
+
+	calls := 0
+	mock := new(MockProvisioner)
+	mock.ProvFunc = func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("flaky")
+		}
+		return nil
+	}
+
+	prov := &RetriedProvisioner{
+		MaxRetries:  2,
+		Provisioner: mock,
+	}
+	if err := prov.Provision(testUi(), new(MockCommunicator)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if calls != 3 {
+		t.Fatalf("bad calls: %d", calls)
+	}
+}
+
+func TestRetriedProvisionerProvision_fail(t *testing.T) {
+	old := provisionerRetryDelay
+	provisionerRetryDelay = time.Millisecond
+	defer func() { provisionerRetryDelay = old }()
+
+	calls := 0
+	mock := new(MockProvisioner)
+	mock.ProvFunc = func() error {
+		calls++
+		return errors.New("broken")
+	}
+
+	prov := &RetriedProvisioner{
+		MaxRetries:  2,
+		Provisioner: mock,
+	}
+	err := prov.Provision(testUi(), new(MockCommunicator))
+	if err == nil || err.Error() != "broken" {
+		t.Fatalf("bad: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("bad calls: %d", calls)
+	}
+}
+
+// exitingProvisioner is a Provisioner that behaves like a plugin, which
+// exits once it is cancelled.
+type exitingProvisioner struct {
+	calls    int
+	cancelCh chan struct{}
+}
+
+func (p *exitingProvisioner) Prepare(...interface{}) error {
+	return nil
+}
+
+func (p *exitingProvisioner) Provision(Ui, Communicator) error {
+	p.calls++
+	select {
+	case <-p.cancelCh:
+		return errors.New("plugin exited")
+	default:
+	}
+
+	<-p.cancelCh
+	return errors.New("cancelled")
+}
+
+func (p *exitingProvisioner) Cancel() {
+	close(p.cancelCh)
+}
+
+func TestRetriedProvisionerProvision_timeout(t *testing.T) {
+	old := provisionerRetryDelay
+	provisionerRetryDelay = time.Millisecond
+	defer func() { provisionerRetryDelay = old }()
+
+	exiting := &exitingProvisioner{cancelCh: make(chan struct{})}
+	prov := &RetriedProvisioner{
+		MaxRetries: 2,
+		Provisioner: &TimeoutProvisioner{
+			Timeout:     10 * time.Millisecond,
+			Provisioner: exiting,
+		},
+	}
+
+	err := prov.Provision(testUi(), new(MockCommunicator))
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("bad: %v", err)
+	}
+	if exiting.calls != 1 {
+		t.Fatalf("bad calls: %d", exiting.calls)
+	}
+}
@@ -1,9 +1,15 @@
 package rpc
 
 import (
-	"github.com/mitchellh/packer/packer"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/mitchellh/packer/common"
+	"github.com/mitchellh/packer/packer"
 )
 
 func TestArtifactRPC(t *testing.T) {
@@ -39,3 +45,64 @@ func TestArtifactRPC(t *testing.T) {
 func TestArtifact_Implements(t *testing.T) {
 	var _ packer.Artifact = new(artifact)
 }
+
+func TestArtifactRPC_verifyChecksums(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	path := filepath.Join(td, "disk.img")
+	if err := ioutil.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	a := &packer.MockArtifact{
+		FilesValue: []string{path},
+		StateValues: map[string]interface{}{
+			"checksums": map[string]string{
+				path: "d41d8cd98f00b204e9800998ecf8427e",
+			},
+		},
+	}
+
+	client, server := testClientServer(t)
+	defer client.Close()
+	defer server.Close()
+	server.RegisterArtifact(a)
+
+	// The checksum doesn't match, so the checksums must have been
+	// received over RPC to fail the verification.
+	err = packer.VerifyArtifactChecksums(packer.TestUi(t), client.Artifact())
+	if err == nil {
+		t.Fatal("should have error")
+	}
+	if !strings.Contains(err.Error(), "mismatch") {
+		t.Fatalf("bad: %s", err)
+	}
+
+	a.StateValues["checksums"] = map[string]string{
+		path: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+	}
+	err = packer.VerifyArtifactChecksums(packer.TestUi(t), client.Artifact())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestArtifactRPC_diskSize(t *testing.T) {
+	a := &packer.MockArtifact{
+		StateValues: map[string]interface{}{"diskSize": uint64(40960)},
+	}
+
+	client, server := testClientServer(t)
+	defer client.Close()
+	defer server.Close()
+	server.RegisterArtifact(a)
+
+	data := common.NewArtifactStateData(client.Artifact())
+	if data.DiskSize != 40960 {
+		t.Fatalf("bad: %#v", data)
+	}
+}
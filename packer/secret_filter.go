@@ -0,0 +1,61 @@
+package packer
+
+import (
+	"io"
+	"strings"
+	"sync"
+)
+
+// SecretRedacted replaces the values of sensitive variables in the output
+// and the logs.
+const SecretRedacted = "<sensitive>"
+
+// SecretFilter redacts the values of sensitive variables from strings.
+// It is also an io.Writer that redacts what is written to Output, so the
+// log can be written through it.
+type SecretFilter struct {
+	Output io.Writer
+
+	secrets map[string]struct{}
+	l       sync.RWMutex
+}
+
+// LogSecretFilter is the SecretFilter used for the UIs and the log.
+var LogSecretFilter SecretFilter
+
+// Set adds secrets to redact. Empty secrets are ignored.
+func (f *SecretFilter) Set(secrets ...string) {
+	f.l.Lock()
+	defer f.l.Unlock()
+
+	if f.secrets == nil {
+		f.secrets = make(map[string]struct{})
+	}
+	for _, s := range secrets {
+		if s != "" {
+			f.secrets[s] = struct{}{}
+		}
+	}
+}
+
+// FilterString returns s with all secrets redacted.
+func (f *SecretFilter) FilterString(s string) string {
+	f.l.RLock()
+	defer f.l.RUnlock()
+
+	for secret := range f.secrets {
+		s = strings.Replace(s, secret, SecretRedacted, -1)
+	}
+
+	return s
+}
+
+func (f *SecretFilter) Write(p []byte) (int, error) {
+	// The log writes each message in a single call, so secrets aren't
+	// split across writes.
+	if _, err := io.WriteString(f.Output, f.FilterString(string(p))); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,25 @@
+package packer
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSecretFilter(t *testing.T) {
+	var buf bytes.Buffer
+	f := &SecretFilter{Output: &buf}
+	f.Set("hunter2", "")
+
+	if actual := f.FilterString("password is hunter2"); actual != "password is <sensitive>" {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	f.Write([]byte("Executing: qemu -pass hunter2\n"))
+	if buf.String() != "Executing: qemu -pass <sensitive>\n" {
+		t.Fatalf("bad: %s", buf.String())
+	}
+
+	if actual := f.FilterString("nothing to hide"); actual != "nothing to hide" {
+		t.Fatalf("bad: %s", actual)
+	}
+}
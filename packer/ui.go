@@ -3,6 +3,7 @@ package packer
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -74,6 +75,32 @@ type MachineReadableUi struct {
 	Writer io.Writer
 }
 
+// EventUi is a UI that forwards everything to another UI and also writes
+// every message and machine-readable output as a JSON event, one per line,
+// to the given Writer. This lets CI systems follow a build, such as which
+// step is running and how long it took, next to the human-readable output.
+type EventUi struct {
+	Ui     Ui
+	Writer io.Writer
+	l      sync.Mutex
+}
+
+// JSONUi is a UI that only outputs machine-readable output to the given
+// Writer, like MachineReadableUi, but writes every output as a JSON
+// event, one per line, instead of the comma-separated format.
+type JSONUi struct {
+	Writer io.Writer
+	l      sync.Mutex
+}
+
+// UiEvent is a single event written by EventUi and JSONUi.
+type UiEvent struct {
+	Timestamp int64    `json:"timestamp"`
+	Target    string   `json:"target,omitempty"`
+	Type      string   `json:"type"`
+	Data      []string `json:"data"`
+}
+
 func (u *ColoredUi) Ask(query string) (string, error) {
 	return u.Ui.Ask(u.colorize(query, u.Color, true))
 }
@@ -222,6 +249,7 @@ func (rw *BasicUi) Say(message string) {
 	rw.l.Lock()
 	defer rw.l.Unlock()
 
+	message = LogSecretFilter.FilterString(message)
 	log.Printf("ui: %s", message)
 	_, err := fmt.Fprint(rw.Writer, message+"\n")
 	if err != nil {
@@ -233,6 +261,7 @@ func (rw *BasicUi) Message(message string) {
 	rw.l.Lock()
 	defer rw.l.Unlock()
 
+	message = LogSecretFilter.FilterString(message)
 	log.Printf("ui: %s", message)
 	_, err := fmt.Fprint(rw.Writer, message+"\n")
 	if err != nil {
@@ -249,6 +278,7 @@ func (rw *BasicUi) Error(message string) {
 		writer = rw.Writer
 	}
 
+	message = LogSecretFilter.FilterString(message)
 	log.Printf("ui error: %s", message)
 	_, err := fmt.Fprint(writer, message+"\n")
 	if err != nil {
@@ -260,6 +290,101 @@ func (rw *BasicUi) Machine(t string, args ...string) {
 	log.Printf("machine readable: %s %#v", t, args)
 }
 
+func (u *EventUi) Ask(query string) (string, error) {
+	return u.Ui.Ask(query)
+}
+
+func (u *EventUi) Say(message string) {
+	u.event("", "ui", "say", message)
+	u.Ui.Say(message)
+}
+
+func (u *EventUi) Message(message string) {
+	u.event("", "ui", "message", message)
+	u.Ui.Message(message)
+}
+
+func (u *EventUi) Error(message string) {
+	u.event("", "ui", "error", message)
+	u.Ui.Error(message)
+}
+
+func (u *EventUi) Machine(category string, args ...string) {
+	// Determine if we have a target, and split it off
+	target := ""
+	t := category
+	if commaIdx := strings.Index(t, ","); commaIdx > -1 {
+		target = t[0:commaIdx]
+		t = t[commaIdx+1:]
+	}
+
+	u.event(target, t, args...)
+	u.Ui.Machine(category, args...)
+}
+
+func (u *EventUi) event(target, t string, args ...string) {
+	u.l.Lock()
+	defer u.l.Unlock()
+	writeUiEvent(u.Writer, target, t, args...)
+}
+
+func (u *JSONUi) Ask(query string) (string, error) {
+	return "", errors.New("machine-readable UI can't ask")
+}
+
+func (u *JSONUi) Say(message string) {
+	u.Machine("ui", "say", message)
+}
+
+func (u *JSONUi) Message(message string) {
+	u.Machine("ui", "message", message)
+}
+
+func (u *JSONUi) Error(message string) {
+	u.Machine("ui", "error", message)
+}
+
+func (u *JSONUi) Machine(category string, args ...string) {
+	// Determine if we have a target, and split it off
+	target := ""
+	if commaIdx := strings.Index(category, ","); commaIdx > -1 {
+		target = category[0:commaIdx]
+		category = category[commaIdx+1:]
+	}
+
+	u.l.Lock()
+	defer u.l.Unlock()
+	writeUiEvent(u.Writer, target, category, args...)
+}
+
+// filterSecrets returns a copy of args with all secrets redacted.
+func filterSecrets(args []string) []string {
+	result := make([]string, len(args))
+	for i, v := range args {
+		result[i] = LogSecretFilter.FilterString(v)
+	}
+
+	return result
+}
+
+// writeUiEvent writes a single UiEvent as a line of JSON to w.
+func writeUiEvent(w io.Writer, target, t string, args ...string) {
+	data, err := json.Marshal(&UiEvent{
+		Timestamp: time.Now().UTC().Unix(),
+		Target:    target,
+		Type:      t,
+		Data:      filterSecrets(args),
+	})
+	if err != nil {
+		log.Printf("[ERR] Failed to encode UI event: %s", err)
+		return
+	}
+
+	if _, err := fmt.Fprintf(w, "%s\n", data); err != nil {
+		log.Printf("[ERR] Failed to write UI event: %s", err)
+	}
+}
+
 func (u *MachineReadableUi) Ask(query string) (string, error) {
 	return "", errors.New("machine-readable UI can't ask")
 }
@@ -289,7 +414,8 @@ func (u *MachineReadableUi) Machine(category string, args ...string) {
 
 	// Prepare the args
 	for i, v := range args {
-		args[i] = strings.Replace(v, ",", "%!(PACKER_COMMA)", -1)
+		args[i] = LogSecretFilter.FilterString(v)
+		args[i] = strings.Replace(args[i], ",", "%!(PACKER_COMMA)", -1)
 		args[i] = strings.Replace(args[i], "\r", "\\r", -1)
 		args[i] = strings.Replace(args[i], "\n", "\\n", -1)
 	}
@@ -2,7 +2,9 @@ package packer
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -286,3 +288,77 @@ func TestMachineReadableUi(t *testing.T) {
 		t.Fatalf("bad: %#v", data)
 	}
 }
+
+func TestEventUi_ImplUi(t *testing.T) {
+	var raw interface{}
+	raw = &EventUi{}
+	if _, ok := raw.(Ui); !ok {
+		t.Fatalf("EventUi must implement Ui")
+	}
+}
+
+func TestEventUi(t *testing.T) {
+	bufferUi := testUi()
+	buf := new(bytes.Buffer)
+	ui := &EventUi{Ui: bufferUi, Writer: buf}
+
+	ui.Say("foo")
+	ui.Machine("mitchellh,step-started", "StepFoo")
+
+	if out := readWriter(bufferUi); out != "foo\n" {
+		t.Fatalf("bad: %q", out)
+	}
+
+	dec := json.NewDecoder(buf)
+	var e UiEvent
+	if err := dec.Decode(&e); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if e.Type != "ui" || !reflect.DeepEqual(e.Data, []string{"say", "foo"}) {
+		t.Fatalf("bad: %#v", e)
+	}
+
+	e = UiEvent{}
+	if err := dec.Decode(&e); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if e.Target != "mitchellh" || e.Type != "step-started" || !reflect.DeepEqual(e.Data, []string{"StepFoo"}) {
+		t.Fatalf("bad: %#v", e)
+	}
+	if e.Timestamp == 0 {
+		t.Fatalf("bad: %#v", e)
+	}
+}
+
+func TestJSONUi_ImplUi(t *testing.T) {
+	var raw interface{}
+	raw = &JSONUi{}
+	if _, ok := raw.(Ui); !ok {
+		t.Fatalf("JSONUi must implement Ui")
+	}
+}
+
+func TestJSONUi(t *testing.T) {
+	buf := new(bytes.Buffer)
+	ui := &JSONUi{Writer: buf}
+
+	ui.Say("foo,bar\n")
+	ui.Machine("mitchellh,artifact", "0", "id", "foo")
+
+	dec := json.NewDecoder(buf)
+	var e UiEvent
+	if err := dec.Decode(&e); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if e.Target != "" || e.Type != "ui" || !reflect.DeepEqual(e.Data, []string{"say", "foo,bar\n"}) {
+		t.Fatalf("bad: %#v", e)
+	}
+
+	e = UiEvent{}
+	if err := dec.Decode(&e); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if e.Target != "mitchellh" || e.Type != "artifact" || !reflect.DeepEqual(e.Data, []string{"0", "id", "foo"}) {
+		t.Fatalf("bad: %#v", e)
+	}
+}
@@ -5,12 +5,21 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/mitchellh/packer/packer"
 )
 
 const BuilderId = "packer.post-processor.artifice"
 
 type Artifact struct {
 	files []string
+
+	// builderId overrides BuilderId when set.
+	builderId string
+
+	// source is the artifact whose files were replaced. Its state is passed
+	// through to downstream post-processors.
+	source packer.Artifact
 }
 
 func NewArtifact(files []string) (*Artifact, error) {
@@ -31,6 +40,9 @@ func NewArtifact(files []string) (*Artifact, error) {
 }
 
 func (a *Artifact) BuilderId() string {
+	if a.builderId != "" {
+		return a.builderId
+	}
 	return BuilderId
 }
 
@@ -48,7 +60,10 @@ func (a *Artifact) String() string {
 }
 
 func (a *Artifact) State(name string) interface{} {
-	return nil
+	if a.source == nil {
+		return nil
+	}
+	return a.source.State(name)
 }
 
 func (a *Artifact) Destroy() error {
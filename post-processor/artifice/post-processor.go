@@ -19,8 +19,9 @@ import (
 type Config struct {
 	common.PackerConfig `mapstructure:",squash"`
 
-	Files []string `mapstructure:"files"`
-	Keep  bool     `mapstructure:"keep_input_artifact"`
+	BuilderId string   `mapstructure:"builder_id"`
+	Files     []string `mapstructure:"files"`
+	Keep      bool     `mapstructure:"keep_input_artifact"`
 
 	ctx interpolate.Context
 }
@@ -48,13 +49,18 @@ func (p *PostProcessor) Configure(raws ...interface{}) error {
 	return nil
 }
 
-func (p *PostProcessor) PostProcess(ui packer.Ui, artifact packer.Artifact) (packer.Artifact, bool, error) {
-	if len(artifact.Files()) > 0 {
-		ui.Say(fmt.Sprintf("Discarding artifact files: %s", strings.Join(artifact.Files(), ", ")))
+func (p *PostProcessor) PostProcess(ui packer.Ui, source packer.Artifact) (packer.Artifact, bool, error) {
+	if len(source.Files()) > 0 {
+		ui.Say(fmt.Sprintf("Discarding artifact files: %s", strings.Join(source.Files(), ", ")))
 	}
 
 	artifact, err := NewArtifact(p.config.Files)
+	if err != nil {
+		return nil, true, err
+	}
+	artifact.builderId = p.config.BuilderId
+	artifact.source = source
 	ui.Say(fmt.Sprintf("Using these artifact files: %s", strings.Join(artifact.Files(), ", ")))
 
-	return artifact, true, err
+	return artifact, true, nil
 }
@@ -1 +1,50 @@
 package artifice
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mitchellh/packer/packer"
+)
+
+func TestPostProcessor_ImplementsPostProcessor(t *testing.T) {
+	var _ packer.PostProcessor = new(PostProcessor)
+}
+
+func TestPostProcessorPostProcess(t *testing.T) {
+	f, err := ioutil.TempFile("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	var p PostProcessor
+	err = p.Configure(map[string]interface{}{
+		"builder_id": "transcend.qemu",
+		"files":      []string{f.Name()},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	source := &packer.MockArtifact{
+		FilesValue:  []string{"foo"},
+		StateValues: map[string]interface{}{"diskName": "disk.qcow2"},
+	}
+	artifact, _, err := p.PostProcess(packer.TestUi(t), source)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if artifact.BuilderId() != "transcend.qemu" {
+		t.Fatalf("bad: %s", artifact.BuilderId())
+	}
+	if files := artifact.Files(); len(files) != 1 || files[0] != f.Name() {
+		t.Fatalf("bad: %#v", files)
+	}
+	if v := artifact.State("diskName"); v != "disk.qcow2" {
+		t.Fatalf("bad: %#v", v)
+	}
+}
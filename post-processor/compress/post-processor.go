@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
 	"runtime"
@@ -40,6 +41,7 @@ type Config struct {
 	Format            string `mapstructure:"format"`
 	CompressionLevel  int    `mapstructure:"compression_level"`
 	KeepInputArtifact bool   `mapstructure:"keep_input_artifact"`
+	Parallelism       int    `mapstructure:"parallelism"`
 
 	// Derived fields
 	Archive   string
@@ -75,6 +77,14 @@ func (p *PostProcessor) Configure(raws ...interface{}) error {
 		p.config.OutputPath = "packer_{{.BuildName}}_{{.BuilderType}}"
 	}
 
+	if p.config.Parallelism == 0 {
+		p.config.Parallelism = runtime.GOMAXPROCS(-1)
+	}
+	if p.config.Parallelism < 0 {
+		errs = packer.MultiErrorAppend(
+			errs, fmt.Errorf("parallelism must be a positive number"))
+	}
+
 	if p.config.CompressionLevel > pgzip.BestCompression {
 		p.config.CompressionLevel = pgzip.BestCompression
 	}
@@ -102,9 +112,13 @@ func (p *PostProcessor) Configure(raws ...interface{}) error {
 func (p *PostProcessor) PostProcess(ui packer.Ui, artifact packer.Artifact) (packer.Artifact, bool, error) {
 
 	// These are extra variables that will be made available for interpolation.
-	p.config.ctx.Data = map[string]string{
+	stateData := common.NewArtifactStateData(artifact)
+	p.config.ctx.Data = map[string]interface{}{
 		"BuildName":   p.config.PackerBuildName,
 		"BuilderType": p.config.PackerBuilderType,
+		"DiskFormat":  stateData.DiskFormat,
+		"DiskName":    stateData.DiskName,
+		"DiskSize":    stateData.DiskSize,
 	}
 
 	target, err := interpolate.Render(p.config.OutputPath, &p.config.ctx)
@@ -134,18 +148,30 @@ func (p *PostProcessor) PostProcess(ui packer.Ui, artifact packer.Artifact) (pac
 	switch p.config.Algorithm {
 	case "bgzf":
 		ui.Say(fmt.Sprintf("Using bgzf compression with %d cores for %s",
-			runtime.GOMAXPROCS(-1), target))
-		output, err = makeBGZFWriter(outputFile, p.config.CompressionLevel)
+			p.config.Parallelism, target))
+		output, err = makeBGZFWriter(outputFile, p.config.CompressionLevel, p.config.Parallelism)
 		defer output.Close()
 	case "lz4":
-		ui.Say(fmt.Sprintf("Using lz4 compression with %d cores for %s",
-			runtime.GOMAXPROCS(-1), target))
+		ui.Say(fmt.Sprintf("Using lz4 compression for %s", target))
 		output, err = makeLZ4Writer(outputFile, p.config.CompressionLevel)
 		defer output.Close()
 	case "pgzip":
 		ui.Say(fmt.Sprintf("Using pgzip compression with %d cores for %s",
-			runtime.GOMAXPROCS(-1), target))
-		output, err = makePgzipWriter(outputFile, p.config.CompressionLevel)
+			p.config.Parallelism, target))
+		output, err = makePgzipWriter(outputFile, p.config.CompressionLevel, p.config.Parallelism)
+		defer output.Close()
+	case "xz", "zstd":
+		ui.Say(fmt.Sprintf("Using %s compression with %d threads for %s",
+			p.config.Algorithm, p.config.Parallelism, target))
+		args := []string{"-c", "-q", fmt.Sprintf("-T%d", p.config.Parallelism)}
+		if p.config.CompressionLevel > 0 {
+			args = append(args, fmt.Sprintf("-%d", p.config.CompressionLevel))
+		}
+		output, err = makeCommandWriter(outputFile, p.config.Algorithm, args...)
+		if err != nil {
+			return nil, false, fmt.Errorf(
+				"Unable to start %s: %s", p.config.Algorithm, err)
+		}
 		defer output.Close()
 	default:
 		output = outputFile
@@ -195,6 +221,17 @@ func (p *PostProcessor) PostProcess(ui packer.Ui, artifact packer.Artifact) (pac
 		}
 	}
 
+	// Close the compressor explicitly, the external compressors only report
+	// whether they succeeded when they exit.
+	if output != outputFile {
+		if err := output.Close(); err != nil {
+			return nil, keep, fmt.Errorf("Error finishing %s: %s", compression, err)
+		}
+	}
+	if err := outputFile.Close(); err != nil {
+		return nil, keep, fmt.Errorf("Error writing archive %s: %s", target, err)
+	}
+
 	ui.Say(fmt.Sprintf("Archive %s completed", target))
 
 	return newArtifact, keep, nil
@@ -209,6 +246,8 @@ func (config *Config) detectFromFilename() {
 		"gz":   "pgzip",
 		"lz4":  "lz4",
 		"bgzf": "bgzf",
+		"xz":   "xz",
+		"zst":  "zstd",
 	}
 
 	if config.Format == "" {
@@ -257,8 +296,8 @@ func (config *Config) detectFromFilename() {
 	return
 }
 
-func makeBGZFWriter(output io.WriteCloser, compressionLevel int) (io.WriteCloser, error) {
-	bgzfWriter, err := bgzf.NewWriterLevel(output, compressionLevel, runtime.GOMAXPROCS(-1))
+func makeBGZFWriter(output io.WriteCloser, compressionLevel, parallelism int) (io.WriteCloser, error) {
+	bgzfWriter, err := bgzf.NewWriterLevel(output, compressionLevel, parallelism)
 	if err != nil {
 		return nil, ErrInvalidCompressionLevel
 	}
@@ -273,15 +312,50 @@ func makeLZ4Writer(output io.WriteCloser, compressionLevel int) (io.WriteCloser,
 	return lzwriter, nil
 }
 
-func makePgzipWriter(output io.WriteCloser, compressionLevel int) (io.WriteCloser, error) {
+func makePgzipWriter(output io.WriteCloser, compressionLevel, parallelism int) (io.WriteCloser, error) {
 	gzipWriter, err := pgzip.NewWriterLevel(output, compressionLevel)
 	if err != nil {
 		return nil, ErrInvalidCompressionLevel
 	}
-	gzipWriter.SetConcurrency(500000, runtime.GOMAXPROCS(-1))
+	gzipWriter.SetConcurrency(500000, parallelism)
 	return gzipWriter, nil
 }
 
+// commandWriter compresses by piping everything written to it through an
+// external command, such as xz or zstd, whose output goes to a file.
+type commandWriter struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+}
+
+func makeCommandWriter(output *os.File, name string, args ...string) (io.WriteCloser, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = output
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return &commandWriter{cmd: cmd, stdin: stdin}, nil
+}
+
+func (w *commandWriter) Write(p []byte) (int, error) {
+	return w.stdin.Write(p)
+}
+
+// Close closes the command's input and waits for it to finish writing.
+func (w *commandWriter) Close() error {
+	if err := w.stdin.Close(); err != nil {
+		return err
+	}
+	return w.cmd.Wait()
+}
+
 func createTarArchive(files []string, output io.WriteCloser) error {
 	archive := tar.NewWriter(output)
 	defer archive.Close()
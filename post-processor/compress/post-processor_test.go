@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -53,6 +55,25 @@ func TestDetectFilename(t *testing.T) {
 	if lotsOfDots.Algorithm != "lz4" {
 		t.Error("Expected to find lz4 algorithm setting")
 	}
+
+	// Test external compressors
+	xzFilename := Config{OutputPath: "test.tar.xz"}
+	xzFilename.detectFromFilename()
+	if xzFilename.Archive != "tar" {
+		t.Error("Expected to find tar archive setting")
+	}
+	if xzFilename.Algorithm != "xz" {
+		t.Error("Expected to find xz algorithm setting")
+	}
+
+	zstdFilename := Config{OutputPath: "test.tar.zst"}
+	zstdFilename.detectFromFilename()
+	if zstdFilename.Archive != "tar" {
+		t.Error("Expected to find tar archive setting")
+	}
+	if zstdFilename.Algorithm != "zstd" {
+		t.Error("Expected to find zstd algorithm setting")
+	}
 }
 
 const expectedFileContents = "Hello world!"
@@ -150,6 +171,78 @@ func TestCompressOptions(t *testing.T) {
 	}
 }
 
+func TestCompressExternal(t *testing.T) {
+	for _, name := range []string{"xz", "zstd"} {
+		if _, err := exec.LookPath(name); err != nil {
+			t.Logf("%s not found, skipping", name)
+			continue
+		}
+
+		filename := "package." + name
+		config := fmt.Sprintf(`
+		{
+		    "post-processors": [
+		        {
+		            "type": "compress",
+		            "output": %q,
+		            "format": %q,
+		            "parallelism": 2
+		        }
+		    ]
+		}
+		`, filename, strings.Replace(name, "zstd", "zst", 1))
+
+		artifact := testArchive(t, config)
+		defer artifact.Destroy()
+
+		data, err := exec.Command(name, "-d", "-c", filename).Output()
+		if err != nil {
+			t.Fatalf("%s: unable to decompress %s: %s", name, filename, err)
+		}
+
+		if string(data) != expectedFileContents {
+			t.Errorf("Expected:\n%s\nFound:\n%s\n", expectedFileContents, data)
+		}
+	}
+}
+
+func TestCompressExternal_fails(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	// An xz that fails after reading all of its input
+	script := "#!/bin/sh\ncat > /dev/null\nexit 1\n"
+	if err := ioutil.WriteFile(filepath.Join(td, "xz"), []byte(script), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", td+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	ui, artifact, err := setup(t)
+	if err != nil {
+		t.Fatalf("Error bootstrapping test: %s", err)
+	}
+	defer artifact.Destroy()
+	defer os.Remove("package.xz")
+
+	compressor := PostProcessor{}
+	err = compressor.Configure(map[string]interface{}{"output": "package.xz"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, _, err := compressor.PostProcess(ui, artifact); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
 func TestCompressInterpolation(t *testing.T) {
 	const config = `
 	{
@@ -16,8 +16,9 @@ const BuilderId = "packer.post-processor.docker-import"
 type Config struct {
 	common.PackerConfig `mapstructure:",squash"`
 
-	Repository string `mapstructure:"repository"`
-	Tag        string `mapstructure:"tag"`
+	Changes    []string `mapstructure:"changes"`
+	Repository string   `mapstructure:"repository"`
+	Tag        string   `mapstructure:"tag"`
 
 	ctx interpolate.Context
 }
@@ -62,7 +63,7 @@ func (p *PostProcessor) PostProcess(ui packer.Ui, artifact packer.Artifact) (pac
 
 	ui.Message("Importing image: " + artifact.Id())
 	ui.Message("Repository: " + importRepo)
-	id, err := driver.Import(artifact.Files()[0], importRepo)
+	id, err := driver.Import(artifact.Files()[0], p.config.Changes, importRepo)
 	if err != nil {
 		return nil, false, err
 	}
@@ -29,3 +29,16 @@ func testUi() *packer.BasicUi {
 func TestPostProcessor_ImplementsPostProcessor(t *testing.T) {
 	var _ packer.PostProcessor = new(PostProcessor)
 }
+
+func TestPostProcessorConfigure_changes(t *testing.T) {
+	var p PostProcessor
+	c := testConfig()
+	c["changes"] = []string{"CMD /bin/sh", "LABEL foo=bar"}
+	if err := p.Configure(c); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(p.config.Changes) != 2 || p.config.Changes[1] != "LABEL foo=bar" {
+		t.Fatalf("bad: %#v", p.config.Changes)
+	}
+}
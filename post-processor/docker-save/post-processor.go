@@ -3,7 +3,11 @@ package dockersave
 import (
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
+	"github.com/klauspost/pgzip"
 	"github.com/mitchellh/packer/builder/docker"
 	"github.com/mitchellh/packer/common"
 	"github.com/mitchellh/packer/helper/config"
@@ -18,7 +22,8 @@ const BuilderId = "packer.post-processor.docker-save"
 type Config struct {
 	common.PackerConfig `mapstructure:",squash"`
 
-	Path string `mapstructure:"path"`
+	Compression string `mapstructure:"compression"`
+	Path        string `mapstructure:"path"`
 
 	ctx interpolate.Context
 }
@@ -29,20 +34,53 @@ type PostProcessor struct {
 	config Config
 }
 
+// pathTemplate is the data available when rendering path.
+type pathTemplate struct {
+	BuildName string
+	ImageId   string
+}
+
 func (p *PostProcessor) Configure(raws ...interface{}) error {
 	err := config.Decode(&p.config, &config.DecodeOpts{
 		Interpolate:        true,
 		InterpolateContext: &p.config.ctx,
 		InterpolateFilter: &interpolate.RenderFilter{
-			Exclude: []string{},
+			Exclude: []string{"path"},
 		},
 	}, raws...)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	if p.config.Compression == "" {
+		switch {
+		case strings.HasSuffix(p.config.Path, ".gz"), strings.HasSuffix(p.config.Path, ".tgz"):
+			p.config.Compression = "gzip"
+		case strings.HasSuffix(p.config.Path, ".zst"):
+			p.config.Compression = "zstd"
+		default:
+			p.config.Compression = "none"
+		}
+	}
+
+	errs := new(packer.MultiError)
+	switch p.config.Compression {
+	case "none", "gzip", "zstd":
+	default:
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf(
+			"compression must be one of none, gzip or zstd, got: %s", p.config.Compression))
+	}
 
+	if err := interpolate.Validate(p.config.Path, &p.config.ctx); err != nil {
+		errs = packer.MultiErrorAppend(
+			errs, fmt.Errorf("Error parsing path template: %s", err))
+	}
+
+	if len(errs.Errors) > 0 {
+		return errs
+	}
+
+	return nil
 }
 
 func (p *PostProcessor) PostProcess(ui packer.Ui, artifact packer.Artifact) (packer.Artifact, bool, error) {
@@ -54,7 +92,18 @@ func (p *PostProcessor) PostProcess(ui packer.Ui, artifact packer.Artifact) (pac
 		return nil, false, err
 	}
 
-	path := p.config.Path
+	p.config.ctx.Data = &pathTemplate{
+		BuildName: p.config.PackerBuildName,
+		ImageId:   artifact.Id(),
+	}
+	path, err := interpolate.Render(p.config.Path, &p.config.ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("Error interpolating path: %s", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, false, fmt.Errorf("Error creating output directory: %s", err)
+	}
 
 	// Open the file that we're going to write to
 	f, err := os.Create(path)
@@ -71,15 +120,46 @@ func (p *PostProcessor) PostProcess(ui packer.Ui, artifact packer.Artifact) (pac
 
 	ui.Message("Saving image: " + artifact.Id())
 
-	if err := driver.SaveImage(artifact.Id(), f); err != nil {
-		f.Close()
+	err = p.save(driver, artifact.Id(), f)
+	f.Close()
+	if err != nil {
 		os.Remove(f.Name())
-
 		return nil, false, err
 	}
 
-	f.Close()
 	ui.Message("Saved to: " + path)
 
 	return artifact, true, nil
 }
+
+// save writes the image to f, compressing it as configured.
+func (p *PostProcessor) save(driver docker.Driver, id string, f *os.File) error {
+	switch p.config.Compression {
+	case "gzip":
+		w := pgzip.NewWriter(f)
+		if err := driver.SaveImage(id, w); err != nil {
+			w.Close()
+			return err
+		}
+		return w.Close()
+	case "zstd":
+		cmd := exec.Command("zstd", "-q", "-c", "-T0")
+		cmd.Stdout = f
+		cmd.Stderr = os.Stderr
+		w, err := cmd.StdinPipe()
+		if err != nil {
+			return err
+		}
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("Error starting zstd: %s", err)
+		}
+		err = driver.SaveImage(id, w)
+		w.Close()
+		if waitErr := cmd.Wait(); err == nil && waitErr != nil {
+			err = fmt.Errorf("Error compressing with zstd: %s", waitErr)
+		}
+		return err
+	default:
+		return driver.SaveImage(id, f)
+	}
+}
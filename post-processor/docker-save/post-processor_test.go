@@ -2,8 +2,16 @@ package dockersave
 
 import (
 	"bytes"
-	"github.com/mitchellh/packer/packer"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/mitchellh/packer/builder/docker"
+	"github.com/mitchellh/packer/packer"
+	"github.com/mitchellh/packer/post-processor/docker-import"
 )
 
 func testConfig() map[string]interface{} {
@@ -29,3 +37,74 @@ func testUi() *packer.BasicUi {
 func TestPostProcessor_ImplementsPostProcessor(t *testing.T) {
 	var _ packer.PostProcessor = new(PostProcessor)
 }
+
+func TestPostProcessorConfigure_compression(t *testing.T) {
+	cases := map[string]string{
+		"foo.tar":     "none",
+		"foo.tar.gz":  "gzip",
+		"foo.tgz":     "gzip",
+		"foo.tar.zst": "zstd",
+	}
+	for path, expected := range cases {
+		var p PostProcessor
+		c := testConfig()
+		c["path"] = path
+		if err := p.Configure(c); err != nil {
+			t.Fatalf("%s: err: %s", path, err)
+		}
+		if p.config.Compression != expected {
+			t.Fatalf("%s: bad: %s", path, p.config.Compression)
+		}
+	}
+
+	var p PostProcessor
+	c := testConfig()
+	c["path"] = "foo.tar"
+	c["compression"] = "bzip2"
+	if err := p.Configure(c); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestPostProcessorPostProcess_gzip(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	var p PostProcessor
+	c := testConfig()
+	c["path"] = filepath.Join(td, "{{.BuildName}}-{{.ImageId}}.tar.gz")
+	if err := p.Configure(c); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	p.config.PackerBuildName = "test"
+	p.Driver = &docker.MockDriver{SaveImageReader: strings.NewReader("image")}
+
+	artifact := &packer.MockArtifact{
+		BuilderIdValue: dockerimport.BuilderId,
+		IdValue:        "foo",
+	}
+	if _, _, err := p.PostProcess(testUi(), artifact); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	f, err := os.Open(filepath.Join(td, "test-foo.tar.gz"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	contents, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(contents) != "image" {
+		t.Fatalf("bad: %s", contents)
+	}
+}
@@ -5,17 +5,20 @@ import "fmt"
 const BuilderId = "packer.post-processor.manifest"
 
 type ArtifactFile struct {
-	Name string `json:"name"`
-	Size int64  `json:"size"`
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum,omitempty"`
 }
 
 type Artifact struct {
-	BuildName     string         `json:"name"`
-	BuilderType   string         `json:"builder_type"`
-	BuildTime     int64          `json:"build_time"`
-	ArtifactFiles []ArtifactFile `json:"files"`
-	ArtifactId    string         `json:"artifact_id"`
-	PackerRunUUID string         `json:"packer_run_uuid"`
+	BuildName     string            `json:"name"`
+	BuilderType   string            `json:"builder_type"`
+	BuildTime     int64             `json:"build_time"`
+	ArtifactFiles []ArtifactFile    `json:"files"`
+	ArtifactId    string            `json:"artifact_id"`
+	PackerRunUUID string            `json:"packer_run_uuid"`
+	ChecksumType  string            `json:"checksum_type,omitempty"`
+	CustomData    map[string]string `json:"custom_data,omitempty"`
 }
 
 func (a *Artifact) BuilderId() string {
@@ -1,8 +1,10 @@
 package manifest
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
@@ -18,9 +20,11 @@ import (
 type Config struct {
 	common.PackerConfig `mapstructure:",squash"`
 
-	OutputPath string `mapstructure:"output"`
-	StripPath  bool   `mapstructure:"strip_path"`
-	ctx        interpolate.Context
+	ChecksumType string            `mapstructure:"checksum_type"`
+	CustomData   map[string]string `mapstructure:"custom_data"`
+	OutputPath   string            `mapstructure:"output"`
+	StripPath    bool              `mapstructure:"strip_path"`
+	ctx          interpolate.Context
 }
 
 type PostProcessor struct {
@@ -52,9 +56,28 @@ func (p *PostProcessor) Configure(raws ...interface{}) error {
 		return fmt.Errorf("Error parsing target template: %s", err)
 	}
 
+	if p.config.ChecksumType != "" && common.HashForType(p.config.ChecksumType) == nil {
+		return fmt.Errorf("Unsupported checksum_type: %s", p.config.ChecksumType)
+	}
+
 	return nil
 }
 
+// fileChecksum returns the hex encoded checksum of the file at path.
+func fileChecksum(path, checksumType string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := common.HashForType(checksumType)
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 func (p *PostProcessor) PostProcess(ui packer.Ui, source packer.Artifact) (packer.Artifact, bool, error) {
 	artifact := &Artifact{}
 
@@ -67,6 +90,12 @@ func (p *PostProcessor) PostProcess(ui packer.Ui, source packer.Artifact) (packe
 		if fi, err = os.Stat(name); err == nil {
 			af.Size = fi.Size()
 		}
+		if p.config.ChecksumType != "" && err == nil && !fi.IsDir() {
+			ui.Message(fmt.Sprintf("Calculating %s checksum of %s", p.config.ChecksumType, name))
+			if af.Checksum, err = fileChecksum(name, p.config.ChecksumType); err != nil {
+				return source, true, fmt.Errorf("Unable to checksum %s: %s", name, err)
+			}
+		}
 		if p.config.StripPath {
 			af.Name = filepath.Base(name)
 		} else {
@@ -78,6 +107,8 @@ func (p *PostProcessor) PostProcess(ui packer.Ui, source packer.Artifact) (packe
 	artifact.BuilderType = p.config.PackerBuilderType
 	artifact.BuildName = p.config.PackerBuildName
 	artifact.BuildTime = time.Now().Unix()
+	artifact.ChecksumType = p.config.ChecksumType
+	artifact.CustomData = p.config.CustomData
 	// Since each post-processor runs in a different process we need a way to
 	// coordinate between various post-processors in a single packer run. We do
 	// this by setting a UUID per run and tracking this in the manifest file.
@@ -0,0 +1,86 @@
+package manifest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/packer/packer"
+)
+
+func TestPostProcessorConfigure_checksumType(t *testing.T) {
+	var p PostProcessor
+	if err := p.Configure(map[string]interface{}{"checksum_type": "sha256"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	p = PostProcessor{}
+	if err := p.Configure(map[string]interface{}{"checksum_type": "crc32"}); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestPostProcessorPostProcess(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	file := filepath.Join(td, "disk.img")
+	if err := ioutil.WriteFile(file, []byte("hello"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	output := filepath.Join(td, "manifest.json")
+	var p PostProcessor
+	err = p.Configure(map[string]interface{}{
+		"checksum_type": "sha256",
+		"custom_data":   map[string]string{"release": "stable"},
+		"output":        output,
+		"strip_path":    true,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	source := &packer.MockArtifact{
+		IdValue:    "foo",
+		FilesValue: []string{file},
+	}
+	if _, _, err := p.PostProcess(packer.TestUi(t), source); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	contents, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var manifest ManifestFile
+	if err := json.Unmarshal(contents, &manifest); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(manifest.Builds) != 1 {
+		t.Fatalf("bad: %#v", manifest.Builds)
+	}
+	build := manifest.Builds[0]
+	if build.ArtifactId != "foo" || build.ChecksumType != "sha256" {
+		t.Fatalf("bad: %#v", build)
+	}
+	if build.CustomData["release"] != "stable" {
+		t.Fatalf("bad: %#v", build.CustomData)
+	}
+
+	expected := ArtifactFile{
+		Name:     "disk.img",
+		Size:     5,
+		Checksum: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+	}
+	if len(build.ArtifactFiles) != 1 || build.ArtifactFiles[0] != expected {
+		t.Fatalf("bad: %#v", build.ArtifactFiles)
+	}
+}
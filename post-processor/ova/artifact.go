@@ -0,0 +1,36 @@
+package ova
+
+import (
+	"fmt"
+	"os"
+)
+
+const BuilderId = "packer.post-processor.ova"
+
+type Artifact struct {
+	path string
+}
+
+func (*Artifact) BuilderId() string {
+	return BuilderId
+}
+
+func (*Artifact) Id() string {
+	return ""
+}
+
+func (a *Artifact) Files() []string {
+	return []string{a.path}
+}
+
+func (a *Artifact) String() string {
+	return fmt.Sprintf("OVA: %s", a.path)
+}
+
+func (*Artifact) State(name string) interface{} {
+	return nil
+}
+
+func (a *Artifact) Destroy() error {
+	return os.Remove(a.path)
+}
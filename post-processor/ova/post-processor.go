@@ -0,0 +1,233 @@
+package ova
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/packer/builder/qemu"
+	"github.com/mitchellh/packer/common"
+	"github.com/mitchellh/packer/helper/config"
+	"github.com/mitchellh/packer/packer"
+	"github.com/mitchellh/packer/template/interpolate"
+)
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+
+	Cpus        int    `mapstructure:"cpus"`
+	Memory      int    `mapstructure:"memory"`
+	OutputPath  string `mapstructure:"output"`
+	QemuImgPath string `mapstructure:"qemu_img_path"`
+	VMName      string `mapstructure:"vm_name"`
+
+	ctx interpolate.Context
+}
+
+type PostProcessor struct {
+	config Config
+}
+
+// outputPathTemplate is the data available when rendering output.
+type outputPathTemplate struct {
+	common.ArtifactStateData
+
+	BuildName string
+}
+
+func (p *PostProcessor) Configure(raws ...interface{}) error {
+	err := config.Decode(&p.config, &config.DecodeOpts{
+		Interpolate:        true,
+		InterpolateContext: &p.config.ctx,
+		InterpolateFilter: &interpolate.RenderFilter{
+			Exclude: []string{"output"},
+		},
+	}, raws...)
+	if err != nil {
+		return err
+	}
+
+	if p.config.Cpus == 0 {
+		p.config.Cpus = 1
+	}
+	if p.config.Memory == 0 {
+		p.config.Memory = 512
+	}
+	if p.config.OutputPath == "" {
+		p.config.OutputPath = "packer_{{.BuildName}}.ova"
+	}
+	if p.config.QemuImgPath == "" {
+		p.config.QemuImgPath = "qemu-img"
+	}
+	if p.config.VMName == "" {
+		p.config.VMName = p.config.PackerBuildName
+	}
+
+	errs := new(packer.MultiError)
+	if p.config.Cpus < 0 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("cpus must be positive"))
+	}
+	if p.config.Memory < 0 {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("memory must be positive"))
+	}
+	if err := interpolate.Validate(p.config.OutputPath, &p.config.ctx); err != nil {
+		errs = packer.MultiErrorAppend(
+			errs, fmt.Errorf("Error parsing output template: %s", err))
+	}
+
+	if len(errs.Errors) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (p *PostProcessor) PostProcess(ui packer.Ui, artifact packer.Artifact) (packer.Artifact, bool, error) {
+	if artifact.BuilderId() != qemu.BuilderId {
+		return nil, false, fmt.Errorf(
+			"Unknown artifact type: %s\nCan only export QEMU builder artifacts.",
+			artifact.BuilderId())
+	}
+
+	stateData := common.NewArtifactStateData(artifact)
+	var source string
+	for _, path := range artifact.Files() {
+		if filepath.Base(path) == stateData.DiskName {
+			source = path
+			break
+		}
+	}
+	if source == "" {
+		return nil, false, fmt.Errorf("No disk image found in artifact")
+	}
+
+	p.config.ctx.Data = &outputPathTemplate{
+		ArtifactStateData: stateData,
+		BuildName:         p.config.PackerBuildName,
+	}
+	outputPath, err := interpolate.Render(p.config.OutputPath, &p.config.ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("Error rendering output template: %s", err)
+	}
+
+	name := p.config.VMName
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(outputPath), filepath.Ext(outputPath))
+	}
+
+	dir, err := ioutil.TempDir("", "packer-ova")
+	if err != nil {
+		return nil, false, err
+	}
+	defer os.RemoveAll(dir)
+
+	// Convert the disk to a stream optimized VMDK, the format OVAs use
+	diskFile := name + "-disk1.vmdk"
+	diskPath := filepath.Join(dir, diskFile)
+	ui.Message(fmt.Sprintf("Converting %s to VMDK", source))
+	cmd := exec.Command(p.config.QemuImgPath, "convert",
+		"-O", "vmdk", "-o", "subformat=streamOptimized", source, diskPath)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, false, fmt.Errorf("Error converting disk: %s\n\n%s", err, out)
+	}
+
+	info, err := os.Stat(diskPath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ovf, err := renderOVF(&ovfData{
+		Name:     name,
+		Cpus:     p.config.Cpus,
+		Memory:   p.config.Memory,
+		DiskFile: diskFile,
+		DiskSize: info.Size(),
+		Capacity: int64(stateData.DiskSize),
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("Error rendering OVF: %s", err)
+	}
+	ovfFile := name + ".ovf"
+	if err := ioutil.WriteFile(filepath.Join(dir, ovfFile), ovf, 0644); err != nil {
+		return nil, false, err
+	}
+
+	ui.Message(fmt.Sprintf("Creating OVA: %s", outputPath))
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return nil, false, err
+	}
+	if err := writeOVA(outputPath, dir, name+".mf", []string{ovfFile, diskFile}); err != nil {
+		os.Remove(outputPath)
+		return nil, false, fmt.Errorf("Error creating OVA: %s", err)
+	}
+
+	return &Artifact{path: outputPath}, false, nil
+}
+
+// writeOVA tars the files in dir into an OVA at path, in order, followed by
+// a manifest with their SHA-1 checksums. The OVF descriptor must come first.
+func writeOVA(path, dir, manifestName string, files []string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	tw := tar.NewWriter(f)
+	var manifest bytes.Buffer
+	for _, name := range files {
+		sum, err := addFile(tw, filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&manifest, "SHA1(%s)= %x\n", name, sum)
+	}
+
+	err = tw.WriteHeader(&tar.Header{
+		Name: manifestName,
+		Mode: 0644,
+		Size: int64(manifest.Len()),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := tw.Write(manifest.Bytes()); err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
+
+// addFile adds the file at path to the tar and returns its SHA-1.
+func addFile(tw *tar.Writer, path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return nil, err
+	}
+
+	h := sha1.New()
+	if _, err := io.Copy(io.MultiWriter(tw, h), f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,124 @@
+package ova
+
+import (
+	"archive/tar"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/packer/packer"
+)
+
+func TestPostProcessor_ImplementsPostProcessor(t *testing.T) {
+	var _ packer.PostProcessor = new(PostProcessor)
+}
+
+func TestPostProcessorConfigure(t *testing.T) {
+	var p PostProcessor
+	if err := p.Configure(map[string]interface{}{}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if p.config.Cpus != 1 || p.config.Memory != 512 || p.config.QemuImgPath != "qemu-img" {
+		t.Fatalf("bad: %#v", p.config)
+	}
+
+	p = PostProcessor{}
+	if err := p.Configure(map[string]interface{}{"cpus": -1}); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestPostProcessorPostProcess_badArtifact(t *testing.T) {
+	var p PostProcessor
+	if err := p.Configure(map[string]interface{}{}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	artifact := &packer.MockArtifact{BuilderIdValue: "mitchellh.virtualbox"}
+	if _, _, err := p.PostProcess(packer.TestUi(t), artifact); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestRenderOVF(t *testing.T) {
+	ovf, err := renderOVF(&ovfData{
+		Name:     "a&b",
+		Cpus:     2,
+		Memory:   2048,
+		DiskFile: "vm-disk1.vmdk",
+		DiskSize: 1234,
+		Capacity: 40960,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for _, expected := range []string{
+		`ovf:href="vm-disk1.vmdk" ovf:size="1234"`,
+		`ovf:capacity="40960"`,
+		`<Name>a&amp;b</Name>`,
+		`<rasd:VirtualQuantity>2</rasd:VirtualQuantity>`,
+		`<rasd:VirtualQuantity>2048</rasd:VirtualQuantity>`,
+	} {
+		if !strings.Contains(string(ovf), expected) {
+			t.Fatalf("expected %q in:\n%s", expected, ovf)
+		}
+	}
+}
+
+func TestWriteOVA(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	if err := ioutil.WriteFile(filepath.Join(td, "vm.ovf"), []byte("ovf"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(td, "vm-disk1.vmdk"), []byte("disk"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	path := filepath.Join(td, "vm.ova")
+	if err := writeOVA(path, td, "vm.mf", []string{"vm.ovf", "vm-disk1.vmdk"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer f.Close()
+
+	var names []string
+	var manifest []byte
+	r := tar.NewReader(f)
+	for {
+		header, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		names = append(names, header.Name)
+		if header.Name == "vm.mf" {
+			manifest, _ = ioutil.ReadAll(r)
+		}
+	}
+
+	if !reflect.DeepEqual(names, []string{"vm.ovf", "vm-disk1.vmdk", "vm.mf"}) {
+		t.Fatalf("bad: %#v", names)
+	}
+
+	expected := "SHA1(vm.ovf)= d988847776ab2aeb9174257e9a6858bd024b6fdc\n" +
+		"SHA1(vm-disk1.vmdk)= a07bdcbcbb025d14688be45f90b3b7128d4f9170\n"
+	if string(manifest) != expected {
+		t.Fatalf("bad: %s", manifest)
+	}
+}
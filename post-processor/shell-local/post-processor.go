@@ -51,6 +51,10 @@ type PostProcessor struct {
 type ExecuteCommandTemplate struct {
 	Vars   string
 	Script string
+
+	// The input artifact's ID and files
+	ArtifactId    string
+	ArtifactFiles []string
 }
 
 func (p *PostProcessor) Configure(raws ...interface{}) error {
@@ -162,13 +166,15 @@ func (p *PostProcessor) PostProcess(ui packer.Ui, artifact packer.Artifact) (pac
 	}
 
 	// Create environment variables to set before executing the command
-	flattenedEnvVars := p.createFlattenedEnvVars()
+	flattenedEnvVars := p.createFlattenedEnvVars(artifact)
 
 	for _, script := range scripts {
 
 		p.config.ctx.Data = &ExecuteCommandTemplate{
-			Vars:   flattenedEnvVars,
-			Script: script,
+			Vars:          flattenedEnvVars,
+			Script:        script,
+			ArtifactId:    artifact.Id(),
+			ArtifactFiles: artifact.Files(),
 		}
 
 		command, err := interpolate.Render(p.config.ExecuteCommand, &p.config.ctx)
@@ -201,7 +207,7 @@ func (p *PostProcessor) PostProcess(ui packer.Ui, artifact packer.Artifact) (pac
 	return artifact, true, nil
 }
 
-func (p *PostProcessor) createFlattenedEnvVars() (flattened string) {
+func (p *PostProcessor) createFlattenedEnvVars(artifact packer.Artifact) (flattened string) {
 	flattened = ""
 	envVars := make(map[string]string)
 
@@ -209,6 +215,26 @@ func (p *PostProcessor) createFlattenedEnvVars() (flattened string) {
 	envVars["PACKER_BUILD_NAME"] = fmt.Sprintf("%s", p.config.PackerBuildName)
 	envVars["PACKER_BUILDER_TYPE"] = fmt.Sprintf("%s", p.config.PackerBuilderType)
 
+	// Details of the input artifact, where it has them
+	if artifact != nil {
+		stateData := common.NewArtifactStateData(artifact)
+		artifactVars := map[string]string{
+			"PACKER_ARTIFACT_ID":          artifact.Id(),
+			"PACKER_ARTIFACT_BUILDER_ID":  artifact.BuilderId(),
+			"PACKER_ARTIFACT_FILES":       strings.Join(artifact.Files(), " "),
+			"PACKER_ARTIFACT_DISK_NAME":   stateData.DiskName,
+			"PACKER_ARTIFACT_DISK_FORMAT": stateData.DiskFormat,
+		}
+		if stateData.DiskSize != 0 {
+			artifactVars["PACKER_ARTIFACT_DISK_SIZE"] = fmt.Sprintf("%d", stateData.DiskSize)
+		}
+		for k, v := range artifactVars {
+			if v != "" {
+				envVars[k] = strings.Replace(v, "'", `'"'"'`, -1)
+			}
+		}
+	}
+
 	// Split vars into key/value components
 	for _, envVar := range p.config.Vars {
 		keyValue := strings.SplitN(envVar, "=", 2)
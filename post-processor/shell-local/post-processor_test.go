@@ -246,9 +246,38 @@ func TestPostProcessor_createFlattenedEnvVars(t *testing.T) {
 
 	for i, expectedValue := range expected {
 		p.config.Vars = userEnvVarTests[i]
-		flattenedEnvVars = p.createFlattenedEnvVars()
+		flattenedEnvVars = p.createFlattenedEnvVars(nil)
 		if flattenedEnvVars != expectedValue {
 			t.Fatalf("expected flattened env vars to be: %s, got %s.", expectedValue, flattenedEnvVars)
 		}
 	}
 }
+
+func TestPostProcessor_createFlattenedEnvVarsArtifact(t *testing.T) {
+	p := new(PostProcessor)
+	p.Configure(testConfig())
+	p.config.PackerBuildName = "vmware"
+	p.config.PackerBuilderType = "iso"
+
+	artifact := &packer.MockArtifact{
+		BuilderIdValue: "transcend.qemu",
+		FilesValue:     []string{"output/disk.qcow2", "output/it's.txt"},
+		IdValue:        "foo",
+		StateValues: map[string]interface{}{
+			"diskName": "disk.qcow2",
+			"diskType": "qcow2",
+			"diskSize": uint64(40960),
+		},
+	}
+
+	expected := `PACKER_ARTIFACT_BUILDER_ID='transcend.qemu' ` +
+		`PACKER_ARTIFACT_DISK_FORMAT='qcow2' ` +
+		`PACKER_ARTIFACT_DISK_NAME='disk.qcow2' ` +
+		`PACKER_ARTIFACT_DISK_SIZE='40960' ` +
+		`PACKER_ARTIFACT_FILES='output/disk.qcow2 output/it'"'"'s.txt' ` +
+		`PACKER_ARTIFACT_ID='foo' ` +
+		`PACKER_BUILDER_TYPE='iso' PACKER_BUILD_NAME='vmware' `
+	if v := p.createFlattenedEnvVars(artifact); v != expected {
+		t.Fatalf("bad: %s", v)
+	}
+}
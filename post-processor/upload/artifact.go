@@ -0,0 +1,47 @@
+package upload
+
+import (
+	"fmt"
+	"strings"
+)
+
+const BuilderId = "packer.post-processor.upload"
+
+type Artifact struct {
+	// urls are the locations the files were uploaded to
+	urls []string
+
+	// checksums maps each URL to the hex encoded SHA-256 of its contents
+	checksums map[string]string
+}
+
+func (*Artifact) BuilderId() string {
+	return BuilderId
+}
+
+func (a *Artifact) Id() string {
+	return strings.Join(a.urls, ",")
+}
+
+func (*Artifact) Files() []string {
+	return nil
+}
+
+func (a *Artifact) String() string {
+	return fmt.Sprintf("Uploaded artifacts to: %s", strings.Join(a.urls, ", "))
+}
+
+func (a *Artifact) State(name string) interface{} {
+	switch name {
+	case "urls":
+		return a.urls
+	case "checksums":
+		return a.checksums
+	default:
+		return nil
+	}
+}
+
+func (*Artifact) Destroy() error {
+	return nil
+}
@@ -0,0 +1,249 @@
+package upload
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	awscommon "github.com/mitchellh/packer/builder/amazon/common"
+	"github.com/mitchellh/packer/common"
+	"github.com/mitchellh/packer/helper/config"
+	"github.com/mitchellh/packer/packer"
+	"github.com/mitchellh/packer/template/interpolate"
+)
+
+type Config struct {
+	common.PackerConfig    `mapstructure:",squash"`
+	awscommon.AccessConfig `mapstructure:",squash"`
+
+	// The URL to upload the artifact's files under. Supported schemes are
+	// http, https and s3.
+	URL string `mapstructure:"url"`
+
+	// Extra headers to send with each HTTP upload.
+	Headers map[string]string `mapstructure:"headers"`
+
+	// The number of times to try each upload.
+	Retries uint `mapstructure:"retries"`
+
+	ctx interpolate.Context
+}
+
+type PostProcessor struct {
+	config Config
+}
+
+// urlTemplate is the data available when rendering url.
+type urlTemplate struct {
+	ArtifactId  string
+	BuildName   string
+	BuilderType string
+}
+
+func (p *PostProcessor) Configure(raws ...interface{}) error {
+	err := config.Decode(&p.config, &config.DecodeOpts{
+		Interpolate:        true,
+		InterpolateContext: &p.config.ctx,
+		InterpolateFilter: &interpolate.RenderFilter{
+			Exclude: []string{"url"},
+		},
+	}, raws...)
+	if err != nil {
+		return err
+	}
+
+	if p.config.Retries == 0 {
+		p.config.Retries = 3
+	}
+
+	errs := new(packer.MultiError)
+	if p.config.URL == "" {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf("url must be set"))
+	} else if err := interpolate.Validate(p.config.URL, &p.config.ctx); err != nil {
+		errs = packer.MultiErrorAppend(
+			errs, fmt.Errorf("Error parsing url template: %s", err))
+	} else {
+		switch scheme := strings.SplitN(p.config.URL, "://", 2)[0]; scheme {
+		case "http", "https":
+		case "s3":
+			errs = packer.MultiErrorAppend(errs, p.config.AccessConfig.Prepare(&p.config.ctx)...)
+		default:
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf(
+				"url must use http, https or s3, got: %s", p.config.URL))
+		}
+	}
+
+	if len(errs.Errors) > 0 {
+		return errs
+	}
+
+	log.Println(common.ScrubConfig(p.config, p.config.AccessKey, p.config.SecretKey))
+	return nil
+}
+
+func (p *PostProcessor) PostProcess(ui packer.Ui, artifact packer.Artifact) (packer.Artifact, bool, error) {
+	if len(artifact.Files()) == 0 {
+		return nil, false, fmt.Errorf("Artifact has no files to upload")
+	}
+
+	p.config.ctx.Data = &urlTemplate{
+		ArtifactId:  artifact.Id(),
+		BuildName:   p.config.PackerBuildName,
+		BuilderType: p.config.PackerBuilderType,
+	}
+	rawURL, err := interpolate.Render(p.config.URL, &p.config.ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("Error rendering url template: %s", err)
+	}
+	base, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, false, fmt.Errorf("Error parsing url %s: %s", rawURL, err)
+	}
+
+	var uploader *s3manager.Uploader
+	if base.Scheme == "s3" {
+		config, err := p.config.Config()
+		if err != nil {
+			return nil, false, err
+		}
+		session, err := session.NewSession(config)
+		if err != nil {
+			return nil, false, err
+		}
+		uploader = s3manager.NewUploader(session)
+	}
+
+	result := &Artifact{checksums: make(map[string]string)}
+	for _, file := range artifact.Files() {
+		checksum, err := fileChecksum(file)
+		if err != nil {
+			return nil, false, fmt.Errorf("Error calculating checksum of %s: %s", file, err)
+		}
+
+		target := *base
+		target.Path = path.Join(base.Path, filepath.Base(file))
+		ui.Message(fmt.Sprintf("Uploading %s to %s", file, target.String()))
+
+		err = common.Retry(2, 30, p.config.Retries, func() (bool, error) {
+			var err error
+			if uploader != nil {
+				err = uploadS3(uploader, &target, file, checksum)
+			} else {
+				err = p.uploadHTTP(&target, file, checksum)
+			}
+			if err != nil {
+				if _, ok := err.(permanentError); ok {
+					return false, err
+				}
+				log.Printf("Error uploading %s, retrying: %s", file, err)
+				ui.Message(fmt.Sprintf("Upload of %s failed, retrying: %s", file, err))
+				return false, nil
+			}
+			return true, nil
+		})
+		if err == common.RetryExhaustedError {
+			return nil, false, fmt.Errorf(
+				"Failed to upload %s after %d attempts", file, p.config.Retries)
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("Failed to upload %s: %s", file, err)
+		}
+
+		result.urls = append(result.urls, target.String())
+		result.checksums[target.String()] = checksum
+	}
+
+	return result, true, nil
+}
+
+// permanentError is an upload error that retrying won't fix.
+type permanentError struct {
+	error
+}
+
+// uploadHTTP PUTs the file to target. The SHA-256 of the contents is sent
+// in a Digest header so the server can verify the upload.
+func (p *PostProcessor) uploadHTTP(target *url.URL, file, checksum string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return permanentError{err}
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return permanentError{err}
+	}
+
+	req, err := http.NewRequest("PUT", target.String(), f)
+	if err != nil {
+		return permanentError{err}
+	}
+	req.ContentLength = info.Size()
+
+	sum, _ := hex.DecodeString(checksum)
+	req.Header.Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(sum))
+	for k, v := range p.config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("server returned %s", resp.Status)
+	}
+	if resp.StatusCode >= 300 {
+		return permanentError{fmt.Errorf("server returned %s", resp.Status)}
+	}
+
+	return nil
+}
+
+// uploadS3 uploads the file to the s3://bucket/key URL in target, storing
+// its SHA-256 in the object's metadata.
+func uploadS3(uploader *s3manager.Uploader, target *url.URL, file, checksum string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return permanentError{err}
+	}
+	defer f.Close()
+
+	_, err = uploader.Upload(&s3manager.UploadInput{
+		Body:     f,
+		Bucket:   aws.String(target.Host),
+		Key:      aws.String(strings.TrimPrefix(target.Path, "/")),
+		Metadata: map[string]*string{"sha256": aws.String(checksum)},
+	})
+	return err
+}
+
+// fileChecksum returns the hex encoded SHA-256 of the file's contents.
+func fileChecksum(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,149 @@
+package upload
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/packer/packer"
+)
+
+func testConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"url": "https://example.com/boxes/{{.BuildName}}",
+	}
+}
+
+func TestPostProcessor_ImplementsPostProcessor(t *testing.T) {
+	var _ packer.PostProcessor = new(PostProcessor)
+}
+
+func TestPostProcessorConfigure(t *testing.T) {
+	var p PostProcessor
+	if err := p.Configure(testConfig()); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if p.config.Retries != 3 {
+		t.Fatalf("bad: %d", p.config.Retries)
+	}
+
+	c := testConfig()
+	delete(c, "url")
+	p = PostProcessor{}
+	if err := p.Configure(c); err == nil {
+		t.Fatal("should have error")
+	}
+
+	c = testConfig()
+	c["url"] = "ftp://example.com/boxes"
+	p = PostProcessor{}
+	if err := p.Configure(c); err == nil {
+		t.Fatal("should have error")
+	}
+
+	c = testConfig()
+	c["url"] = "s3://bucket/boxes"
+	c["region"] = "us-east-1"
+	p = PostProcessor{}
+	if err := p.Configure(c); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestPostProcessorPostProcess_http(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	file := filepath.Join(td, "disk.img")
+	if err := ioutil.WriteFile(file, []byte("hello"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var gotPath, gotDigest, gotToken, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		gotPath = r.URL.Path
+		gotDigest = r.Header.Get("Digest")
+		gotToken = r.Header.Get("X-Token")
+		gotBody = string(body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	var p PostProcessor
+	err = p.Configure(map[string]interface{}{
+		"url":     server.URL + "/boxes/{{.BuildName}}",
+		"headers": map[string]string{"X-Token": "secret"},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	p.config.PackerBuildName = "test"
+
+	source := &packer.MockArtifact{FilesValue: []string{file}}
+	artifact, keep, err := p.PostProcess(packer.TestUi(t), source)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !keep {
+		t.Fatal("should keep input artifact")
+	}
+
+	if gotPath != "/boxes/test/disk.img" {
+		t.Fatalf("bad path: %s", gotPath)
+	}
+	if gotDigest != "SHA-256=LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=" {
+		t.Fatalf("bad digest: %s", gotDigest)
+	}
+	if gotToken != "secret" || gotBody != "hello" {
+		t.Fatalf("bad: %s %s", gotToken, gotBody)
+	}
+
+	expected := server.URL + "/boxes/test/disk.img"
+	if artifact.Id() != expected {
+		t.Fatalf("bad: %s", artifact.Id())
+	}
+	checksums := artifact.State("checksums").(map[string]string)
+	if checksums[expected] != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Fatalf("bad: %#v", checksums)
+	}
+}
+
+func TestPostProcessorPostProcess_httpPermanentError(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	file := filepath.Join(td, "disk.img")
+	if err := ioutil.WriteFile(file, []byte("hello"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	var p PostProcessor
+	if err := p.Configure(map[string]interface{}{"url": server.URL}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	source := &packer.MockArtifact{FilesValue: []string{file}}
+	if _, _, err := p.PostProcess(packer.TestUi(t), source); err == nil {
+		t.Fatal("should have error")
+	}
+	if requests != 1 {
+		t.Fatalf("client errors should not be retried, got %d requests", requests)
+	}
+}
@@ -7,10 +7,11 @@ import (
 )
 
 type Provider struct {
-	Name        string `json:"name"`
-	Url         string `json:"url,omitempty"`
-	HostedToken string `json:"hosted_token,omitempty"`
-	UploadUrl   string `json:"upload_url,omitempty"`
+	Name         string `json:"name"`
+	Architecture string `json:"architecture,omitempty"`
+	Url          string `json:"url,omitempty"`
+	HostedToken  string `json:"hosted_token,omitempty"`
+	UploadUrl    string `json:"upload_url,omitempty"`
 }
 
 type stepCreateProvider struct {
@@ -29,6 +30,10 @@ func (s *stepCreateProvider) Run(state multistep.StateBag) multistep.StepAction
 
 	provider := &Provider{Name: providerName}
 
+	if architecture, ok := state.Get("artifact").(packer.Artifact).State("architecture").(string); ok {
+		provider.Architecture = architecture
+	}
+
 	if downloadUrl != "" {
 		provider.Url = downloadUrl
 	}
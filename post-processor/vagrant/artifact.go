@@ -10,6 +10,9 @@ const BuilderId = "mitchellh.post-processor.vagrant"
 type Artifact struct {
 	Path     string
 	Provider string
+
+	// Architecture is the architecture the box was built for, if known.
+	Architecture string
 }
 
 func NewArtifact(provider, path string) *Artifact {
@@ -36,6 +39,9 @@ func (a *Artifact) String() string {
 }
 
 func (a *Artifact) State(name string) interface{} {
+	if name == "architecture" && a.Architecture != "" {
+		return a.Architecture
+	}
 	return nil
 }
 
@@ -19,3 +19,15 @@ func TestArtifact_Id(t *testing.T) {
 		t.Fatalf("should return name as Id")
 	}
 }
+
+func TestArtifact_State(t *testing.T) {
+	artifact := NewArtifact("libvirt", "./")
+	if artifact.State("architecture") != nil {
+		t.Fatalf("should be nil without architecture")
+	}
+
+	artifact.Architecture = "arm64"
+	if artifact.State("architecture") != "arm64" {
+		t.Fatalf("bad: %#v", artifact.State("architecture"))
+	}
+}
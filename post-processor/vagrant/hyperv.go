@@ -55,7 +55,7 @@ func (p *HypervProvider) Process(ui packer.Ui, artifact packer.Artifact, dir str
 
 		dstPath := filepath.Join(dstDir, filepath.Base(path))
 
-		if err = CopyContents(dstPath, path); err != nil {
+		if err = LinkFile(dstPath, path); err != nil {
 			ui.Message(fmt.Sprintf("err in copying: %s to %s", path, dstPath))
 			return
 		}
@@ -20,7 +20,7 @@ func (p *LibVirtProvider) Process(ui packer.Ui, artifact packer.Artifact, dir st
 		if strings.HasSuffix(path, "/"+diskName) {
 			ui.Message(fmt.Sprintf("Copying from artifact: %s", path))
 			dstPath := filepath.Join(dir, "box.img")
-			if err = CopyContents(dstPath, path); err != nil {
+			if err = LinkFile(dstPath, path); err != nil {
 				return
 			}
 		}
@@ -49,7 +49,7 @@ func (p *ParallelsProvider) Process(ui packer.Ui, artifact packer.Artifact, dir
 		dstPath := filepath.Join(dir, pvmPath)
 
 		ui.Message(fmt.Sprintf("Copying: %s", path))
-		if err = CopyContents(dstPath, path); err != nil {
+		if err = LinkFile(dstPath, path); err != nil {
 			return
 		}
 	}
@@ -33,11 +33,16 @@ var builtins = map[string]string{
 type Config struct {
 	common.PackerConfig `mapstructure:",squash"`
 
-	CompressionLevel    int      `mapstructure:"compression_level"`
-	Include             []string `mapstructure:"include"`
-	OutputPath          string   `mapstructure:"output"`
+	Architecture        string                 `mapstructure:"architecture"`
+	BoxDir              string                 `mapstructure:"box_dir"`
+	CompressionLevel    int                    `mapstructure:"compression_level"`
+	Description         string                 `mapstructure:"description"`
+	Include             []string               `mapstructure:"include"`
+	Metadata            map[string]interface{} `mapstructure:"metadata"`
+	OutputPath          string                 `mapstructure:"output"`
 	Override            map[string]interface{}
 	VagrantfileTemplate string `mapstructure:"vagrantfile_template"`
+	Version             string `mapstructure:"version"`
 
 	ctx interpolate.Context
 }
@@ -78,27 +83,48 @@ func (p *PostProcessor) PostProcessProvider(name string, provider Provider, ui p
 	ui.Say(fmt.Sprintf("Creating Vagrant box for '%s' provider", name))
 
 	config.ctx.Data = &outputPathTemplate{
-		ArtifactId: artifact.Id(),
-		BuildName:  config.PackerBuildName,
-		Provider:   name,
+		ArtifactStateData: common.NewArtifactStateData(artifact),
+		ArtifactId:        artifact.Id(),
+		Architecture:      config.Architecture,
+		BuildName:         config.PackerBuildName,
+		Provider:          name,
 	}
 	outputPath, err := interpolate.Render(config.OutputPath, &config.ctx)
 	if err != nil {
 		return nil, false, err
 	}
 
-	// Create a temporary directory for us to build the contents of the box in
-	dir, err := ioutil.TempDir("", "packer")
+	// Build the contents of the box in the configured box directory, or
+	// in a temporary directory if there is none.
+	dir, err := interpolate.Render(config.BoxDir, &config.ctx)
 	if err != nil {
 		return nil, false, err
 	}
-	defer os.RemoveAll(dir)
 
-	// Copy all of the includes files into the temporary directory
+	reuseIncludes := false
+	if dir == "" {
+		dir, err = ioutil.TempDir("", "packer")
+		if err != nil {
+			return nil, false, err
+		}
+		defer os.RemoveAll(dir)
+	} else {
+		ui.Message(fmt.Sprintf("Using box directory: %s", dir))
+		reuseIncludes, err = PrepareBoxDir(dir, config.Include, config.CompressionLevel)
+		if err != nil {
+			return nil, false, fmt.Errorf("Error preparing box directory: %s", err)
+		}
+	}
+
+	// Copy all of the includes files into the box directory
 	for _, src := range config.Include {
+		if reuseIncludes {
+			break
+		}
+
 		ui.Message(fmt.Sprintf("Copying from include: %s", src))
 		dst := filepath.Join(dir, filepath.Base(src))
-		if err := CopyContents(dst, src); err != nil {
+		if err := LinkFile(dst, src); err != nil {
 			err = fmt.Errorf("Error copying include file: %s\n\n%s", src, err)
 			return nil, false, err
 		}
@@ -110,7 +136,9 @@ func (p *PostProcessor) PostProcessProvider(name string, provider Provider, ui p
 		return nil, false, err
 	}
 
-	// Write the metadata we got
+	// Write the metadata we got, along with any extra metadata from the
+	// configuration. The provider's own values always win.
+	metadata = config.extraMetadata(metadata)
 	if err := WriteMetadata(dir, metadata); err != nil {
 		return nil, false, err
 	}
@@ -143,11 +171,23 @@ func (p *PostProcessor) PostProcessProvider(name string, provider Provider, ui p
 	}
 
 	// Create the box
-	if err := DirToBox(outputPath, dir, ui, config.CompressionLevel); err != nil {
+	if config.BoxDir != "" {
+		includes := make([]string, len(config.Include))
+		for i, src := range config.Include {
+			includes[i] = filepath.Base(src)
+		}
+
+		err = IncrementalDirToBox(outputPath, dir, includes, ui, config.CompressionLevel)
+	} else {
+		err = DirToBox(outputPath, dir, ui, config.CompressionLevel)
+	}
+	if err != nil {
 		return nil, false, err
 	}
 
-	return NewArtifact(name, outputPath), provider.KeepInputArtifact(), nil
+	result := NewArtifact(name, outputPath)
+	result.Architecture = config.Architecture
+	return result, provider.KeepInputArtifact(), nil
 }
 
 func (p *PostProcessor) PostProcess(ui packer.Ui, artifact packer.Artifact) (packer.Artifact, bool, error) {
@@ -175,6 +215,7 @@ func (p *PostProcessor) configureSingle(c *Config, raws ...interface{}) error {
 		InterpolateContext: &c.ctx,
 		InterpolateFilter: &interpolate.RenderFilter{
 			Exclude: []string{
+				"box_dir",
 				"output",
 			},
 		},
@@ -201,6 +242,11 @@ func (p *PostProcessor) configureSingle(c *Config, raws ...interface{}) error {
 	}
 
 	var errs *packer.MultiError
+	if _, ok := c.Metadata["provider"]; ok {
+		errs = packer.MultiErrorAppend(errs, fmt.Errorf(
+			"metadata can't set 'provider', it is set by the post-processor"))
+	}
+
 	if c.VagrantfileTemplate != "" {
 		_, err := os.Stat(c.VagrantfileTemplate)
 		if err != nil {
@@ -209,6 +255,29 @@ func (p *PostProcessor) configureSingle(c *Config, raws ...interface{}) error {
 		}
 	}
 
+	includes := make(map[string]string)
+	for _, src := range c.Include {
+		info, err := os.Stat(src)
+		if err != nil {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf(
+				"include file '%s' does not exist", src))
+			continue
+		}
+		if info.IsDir() {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf(
+				"include file '%s' is a directory", src))
+			continue
+		}
+
+		base := filepath.Base(src)
+		if other, ok := includes[base]; ok {
+			errs = packer.MultiErrorAppend(errs, fmt.Errorf(
+				"include files '%s' and '%s' have the same name", other, src))
+			continue
+		}
+		includes[base] = src
+	}
+
 	if errs != nil && len(errs.Errors) > 0 {
 		return errs
 	}
@@ -216,6 +285,29 @@ func (p *PostProcessor) configureSingle(c *Config, raws ...interface{}) error {
 	return nil
 }
 
+// extraMetadata returns the provider metadata merged on top of the metadata,
+// architecture, version and description from the configuration.
+func (c *Config) extraMetadata(metadata map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for k, v := range c.Metadata {
+		result[k] = v
+	}
+	if c.Architecture != "" {
+		result["architecture"] = c.Architecture
+	}
+	if c.Version != "" {
+		result["version"] = c.Version
+	}
+	if c.Description != "" {
+		result["description"] = c.Description
+	}
+	for k, v := range metadata {
+		result[k] = v
+	}
+
+	return result
+}
+
 func providerForName(name string) Provider {
 	switch name {
 	case "aws":
@@ -240,9 +332,12 @@ func providerForName(name string) Provider {
 // OutputPathTemplate is the structure that is availalable within the
 // OutputPath variables.
 type outputPathTemplate struct {
-	ArtifactId string
-	BuildName  string
-	Provider   string
+	common.ArtifactStateData
+
+	ArtifactId   string
+	Architecture string
+	BuildName    string
+	Provider     string
 }
 
 type vagrantfileTemplate struct {
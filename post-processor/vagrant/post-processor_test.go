@@ -6,6 +6,8 @@ import (
 	"github.com/mitchellh/packer/packer"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -145,6 +147,95 @@ func TestPostProcessorPrepare_vagrantfileTemplateExists(t *testing.T) {
 	}
 }
 
+func TestPostProcessorPrepare_include(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	first := filepath.Join(dir, "LICENSE")
+	if err := ioutil.WriteFile(first, []byte("license"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	second := filepath.Join(dir, "sub", "LICENSE")
+	if err := ioutil.WriteFile(second, []byte("license"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Good
+	var p PostProcessor
+	c := testConfig()
+	c["include"] = []string{first}
+	if err := p.Configure(c); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Missing file
+	c["include"] = []string{filepath.Join(dir, "nope")}
+	if err := p.Configure(c); err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Directory
+	c["include"] = []string{filepath.Join(dir, "sub")}
+	if err := p.Configure(c); err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Same name
+	c["include"] = []string{first, second}
+	if err := p.Configure(c); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestPostProcessorPrepare_metadata(t *testing.T) {
+	var p PostProcessor
+	c := testConfig()
+	c["metadata"] = map[string]interface{}{"provider": "foo"}
+	if err := p.Configure(c); err == nil {
+		t.Fatal("should have error")
+	}
+
+	c["metadata"] = map[string]interface{}{"foo": "bar"}
+	if err := p.Configure(c); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestConfigExtraMetadata(t *testing.T) {
+	c := &Config{
+		Architecture: "amd64",
+		Description:  "a box",
+		Metadata: map[string]interface{}{
+			"foo":    "bar",
+			"format": "raw",
+		},
+		Version: "1.2.3",
+	}
+
+	result := c.extraMetadata(map[string]interface{}{
+		"provider": "libvirt",
+		"format":   "qcow2",
+	})
+	expected := map[string]interface{}{
+		"architecture": "amd64",
+		"description":  "a box",
+		"foo":          "bar",
+		"format":       "qcow2",
+		"provider":     "libvirt",
+		"version":      "1.2.3",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
 func TestPostProcessorPostProcess_badId(t *testing.T) {
 	artifact := &packer.MockArtifact{
 		BuilderIdValue: "invalid.packer",
@@ -6,9 +6,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 
 	"github.com/klauspost/pgzip"
@@ -51,53 +53,138 @@ func CopyContents(dst, src string) error {
 	return nil
 }
 
+// LinkFile makes src available at dst without duplicating its contents by
+// creating a symlink, which DirToBox follows when building the box. If the
+// link can't be created (e.g. on Windows without the privilege to create
+// symlinks), the contents are copied instead.
+func LinkFile(dst, src string) error {
+	absSrc, err := filepath.Abs(src)
+	if err != nil {
+		return err
+	}
+
+	dstDir, _ := filepath.Split(dst)
+	if dstDir != "" {
+		if err := os.MkdirAll(dstDir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Symlink(absSrc, dst); err != nil {
+		log.Printf("Error linking %s to %s, copying instead: %s", src, dst, err)
+		return CopyContents(dst, src)
+	}
+
+	return nil
+}
+
 // DirToBox takes the directory and compresses it into a Vagrant-compatible
 // box. This function does not perform checks to verify that dir is
 // actually a proper box. This is an expected precondition.
 func DirToBox(dst, dir string, ui packer.Ui, level int) error {
 	log.Printf("Turning dir into box: %s => %s", dir, dst)
 
-	// Make the containing directory, if it does not already exist
-	err := os.MkdirAll(filepath.Dir(dst), 0755)
+	dstF, err := createBoxFile(dst)
 	if err != nil {
 		return err
 	}
+	defer dstF.Close()
 
-	dstF, err := os.Create(dst)
+	return writeBoxArchive(dstF, dir, ui, level, nil, true)
+}
+
+// IncrementalDirToBox is like DirToBox, but keeps the compressed contents
+// of the files named in includes in a cache within dir. The cache is
+// reused as long as it exists, so only the remaining files of the box are
+// compressed again. The box is written as two concatenated archives, which
+// Vagrant reads as one.
+func IncrementalDirToBox(dst, dir string, includes []string, ui packer.Ui, level int) error {
+	log.Printf("Turning dir into box incrementally: %s => %s", dir, dst)
+
+	included := make(map[string]bool)
+	for _, name := range includes {
+		included[name] = true
+	}
+
+	cachePath := filepath.Join(dir, boxIncludesCacheName)
+	if _, err := os.Stat(cachePath); os.IsNotExist(err) {
+		log.Printf("Creating include cache for box: %s", cachePath)
+		tmpPath := cachePath + ".tmp"
+		cacheF, err := os.Create(tmpPath)
+		if err != nil {
+			return err
+		}
+
+		err = writeBoxArchive(cacheF, dir, ui, level, func(name string) bool {
+			return included[name]
+		}, false)
+		cacheF.Close()
+		if err != nil {
+			os.Remove(tmpPath)
+			return err
+		}
+
+		if err := os.Rename(tmpPath, cachePath); err != nil {
+			return err
+		}
+	} else if ui != nil {
+		ui.Message("Reusing compressed include files")
+	}
+
+	dstF, err := createBoxFile(dst)
 	if err != nil {
 		return err
 	}
 	defer dstF.Close()
 
-	var dstWriter io.WriteCloser = dstF
+	cacheF, err := os.Open(cachePath)
+	if err != nil {
+		return err
+	}
+	defer cacheF.Close()
+
+	if _, err := io.Copy(dstF, cacheF); err != nil {
+		return err
+	}
+
+	return writeBoxArchive(dstF, dir, ui, level, func(name string) bool {
+		return !included[name] && !isBoxCacheFile(name)
+	}, true)
+}
+
+// createBoxFile creates the box file at dst, along with the directory
+// containing it if it does not already exist.
+func createBoxFile(dst string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return nil, err
+	}
+
+	return os.Create(dst)
+}
+
+// writeBoxArchive writes the files in dir for which include returns true
+// (or all files if include is nil) as a tar archive to w, compressing it
+// with gzip unless level is flate.NoCompression. If end is false, the tar
+// end-of-archive marker is left out so more files can follow.
+func writeBoxArchive(w io.WriteCloser, dir string, ui packer.Ui, level int, include func(name string) bool, end bool) error {
+	var dstWriter io.WriteCloser = w
+	var gzipWriter io.WriteCloser
 	if level != flate.NoCompression {
 		log.Printf("Compressing with gzip compression level: %d", level)
-		gzipWriter, err := makePgzipWriter(dstWriter, level)
+		var err error
+		gzipWriter, err = makePgzipWriter(dstWriter, level)
 		if err != nil {
 			return err
 		}
-		defer gzipWriter.Close()
 
 		dstWriter = gzipWriter
 	}
 
 	tarWriter := tar.NewWriter(dstWriter)
-	defer tarWriter.Close()
 
-	// This is the walk func that tars each of the files in the dir
-	tarWalk := func(path string, info os.FileInfo, prevErr error) error {
-		// If there was a prior error, return it
-		if prevErr != nil {
-			return prevErr
-		}
-
-		// Skip directories
-		if info.IsDir() {
-			log.Printf("Skipping directory '%s' for box '%s'", path, dst)
-			return nil
-		}
-
-		log.Printf("Box add: '%s' to '%s'", path, dst)
+	// addFile adds a single file to the tar with the given file info
+	addFile := func(path, name string, info os.FileInfo) error {
+		log.Printf("Box add: '%s' as '%s'", path, name)
 		f, err := os.Open(path)
 		if err != nil {
 			return err
@@ -113,10 +200,7 @@ func DirToBox(dst, dir string, ui packer.Ui, level int) error {
 		// be a relative path to the root. Otherwise, the tar ends up
 		// being a bunch of files in the root, even if they're actually
 		// nested in a dir in the original "dir" param.
-		header.Name, err = filepath.Rel(dir, path)
-		if err != nil {
-			return err
-		}
+		header.Name = name
 
 		if ui != nil {
 			ui.Message(fmt.Sprintf("Compressing: %s", header.Name))
@@ -133,8 +217,59 @@ func DirToBox(dst, dir string, ui packer.Ui, level int) error {
 		return nil
 	}
 
+	// This is the walk func that tars each of the files in the dir
+	tarWalk := func(path string, info os.FileInfo, prevErr error) error {
+		// If there was a prior error, return it
+		if prevErr != nil {
+			return prevErr
+		}
+
+		// Skip directories
+		if info.IsDir() {
+			log.Printf("Skipping directory '%s' for box", path)
+			return nil
+		}
+
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if include != nil && !include(name) {
+			return nil
+		}
+
+		// Files linked in with LinkFile are added with the contents of
+		// the file they point to.
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			if target.IsDir() {
+				return fmt.Errorf("Can't add linked directory '%s' to box", path)
+			}
+			return addFile(path, name, target)
+		}
+
+		return addFile(path, name, info)
+	}
+
 	// Tar.gz everything up
-	return filepath.Walk(dir, tarWalk)
+	err := filepath.Walk(dir, tarWalk)
+	if err == nil {
+		if end {
+			err = tarWriter.Close()
+		} else {
+			err = tarWriter.Flush()
+		}
+	}
+	if gzipWriter != nil {
+		if closeErr := gzipWriter.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
 }
 
 // WriteMetadata writes the "metadata.json" file for a Vagrant box.
@@ -161,3 +296,128 @@ func makePgzipWriter(output io.WriteCloser, compressionLevel int) (io.WriteClose
 	gzipWriter.SetConcurrency(500000, runtime.GOMAXPROCS(-1))
 	return gzipWriter, nil
 }
+
+const (
+	// boxIncludesCacheName is the name of the file within a box directory
+	// that holds the compressed include files of an incremental box.
+	boxIncludesCacheName = ".packer-includes.cache"
+
+	// boxIncludesManifestName is the name of the file within a box
+	// directory that describes the include files the cache was built from.
+	boxIncludesManifestName = ".packer-includes.json"
+)
+
+// boxIncludesManifest describes the include files of a box directory and
+// how they were compressed, so changes can be detected between builds.
+type boxIncludesManifest struct {
+	CompressionLevel int              `json:"compression_level"`
+	Files            []boxIncludeFile `json:"files"`
+}
+
+type boxIncludeFile struct {
+	Name    string `json:"name"`
+	Source  string `json:"source"`
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mod_time"`
+}
+
+func isBoxCacheFile(name string) bool {
+	return name == boxIncludesCacheName || name == boxIncludesManifestName
+}
+
+// PrepareBoxDir readies dir to be reused for building a box incrementally.
+// If the include files and compression level are unchanged since the last
+// build, the include files and their compressed cache are kept and true is
+// returned. Every other file in dir, such as disk images and metadata from
+// the previous build, is removed. A non-empty dir that wasn't prepared by a
+// previous build is an error.
+func PrepareBoxDir(dir string, includes []string, level int) (bool, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return false, err
+	}
+
+	manifest := &boxIncludesManifest{CompressionLevel: level}
+	for _, src := range includes {
+		info, err := os.Stat(src)
+		if err != nil {
+			return false, err
+		}
+		absSrc, err := filepath.Abs(src)
+		if err != nil {
+			return false, err
+		}
+
+		manifest.Files = append(manifest.Files, boxIncludeFile{
+			Name:    filepath.Base(src),
+			Source:  absSrc,
+			Size:    info.Size(),
+			ModTime: info.ModTime().UnixNano(),
+		})
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+
+	// Only clean directories that were prepared by a previous build, so
+	// that a mistaken box_dir can't wipe unrelated files.
+	manifestPath := filepath.Join(dir, boxIncludesManifestName)
+	if _, err := os.Stat(manifestPath); os.IsNotExist(err) && len(entries) > 0 {
+		return false, fmt.Errorf(
+			"Box directory %s isn't empty and wasn't created by Packer, "+
+				"refusing to remove its contents", dir)
+	}
+
+	reuse := false
+	if contents, err := ioutil.ReadFile(manifestPath); err == nil {
+		var previous boxIncludesManifest
+		if err := json.Unmarshal(contents, &previous); err != nil {
+			log.Printf("Ignoring invalid box include manifest: %s", err)
+		} else {
+			reuse = reflect.DeepEqual(manifest, &previous)
+		}
+	}
+
+	keep := make(map[string]bool)
+	if reuse {
+		keep[boxIncludesCacheName] = true
+		keep[boxIncludesManifestName] = true
+		for _, f := range manifest.Files {
+			keep[f.Name] = true
+		}
+
+		// Without the cache or any of the include files there is nothing
+		// worth reusing.
+		for name := range keep {
+			if _, err := os.Lstat(filepath.Join(dir, name)); err != nil {
+				reuse = false
+				keep = nil
+				break
+			}
+		}
+	}
+
+	for _, entry := range entries {
+		if keep[entry.Name()] {
+			continue
+		}
+
+		log.Printf("Removing from box dir: %s", entry.Name())
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return false, err
+		}
+	}
+
+	if !reuse {
+		contents, err := json.Marshal(manifest)
+		if err != nil {
+			return false, err
+		}
+		if err := ioutil.WriteFile(manifestPath, contents, 0644); err != nil {
+			return false, err
+		}
+	}
+
+	return reuse, nil
+}
@@ -0,0 +1,191 @@
+package vagrant
+
+import (
+	"archive/tar"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDirToBox_linkedFile(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	src := filepath.Join(td, "disk.img")
+	if err := ioutil.WriteFile(src, []byte("disk contents"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	dir := filepath.Join(td, "box")
+	if err := LinkFile(filepath.Join(dir, "box.img"), src); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	box := filepath.Join(td, "test.box")
+	if err := DirToBox(box, dir, nil, flate.NoCompression); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	f, err := os.Open(box)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer f.Close()
+
+	r := tar.NewReader(f)
+	header, err := r.Next()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if header.Name != "box.img" || header.Typeflag != tar.TypeReg {
+		t.Fatalf("bad: %#v", header)
+	}
+
+	contents, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(contents) != "disk contents" {
+		t.Fatalf("bad: %s", contents)
+	}
+
+	if _, err := r.Next(); err != io.EOF {
+		t.Fatalf("expected a single file, got: %s", err)
+	}
+}
+
+func TestIncrementalDirToBox(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	include := filepath.Join(td, "info.json")
+	if err := ioutil.WriteFile(include, []byte("info"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	dir := filepath.Join(td, "box")
+	box := filepath.Join(td, "test.box")
+	build := func(disk string) map[string]string {
+		reuse, err := PrepareBoxDir(dir, []string{include}, flate.BestSpeed)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !reuse {
+			if err := LinkFile(filepath.Join(dir, "info.json"), include); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "box.img"), []byte(disk), 0644); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		err = IncrementalDirToBox(box, dir, []string{"info.json"}, nil, flate.BestSpeed)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		return readBox(t, box)
+	}
+
+	files := build("first")
+	expected := map[string]string{"info.json": "info", "box.img": "first"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Fatalf("bad: %#v", files)
+	}
+
+	cache := filepath.Join(dir, boxIncludesCacheName)
+	before, err := os.Stat(cache)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	files = build("second")
+	expected["box.img"] = "second"
+	if !reflect.DeepEqual(files, expected) {
+		t.Fatalf("bad: %#v", files)
+	}
+
+	after, err := os.Stat(cache)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !after.ModTime().Equal(before.ModTime()) {
+		t.Fatal("include cache should be reused")
+	}
+
+	// Changing an include file rebuilds the cache
+	if err := ioutil.WriteFile(include, []byte("new info"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	files = build("third")
+	expected = map[string]string{"info.json": "new info", "box.img": "third"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Fatalf("bad: %#v", files)
+	}
+}
+
+func TestPrepareBoxDir_foreign(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	precious := filepath.Join(td, "precious.txt")
+	if err := ioutil.WriteFile(precious, []byte("foo"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, err := PrepareBoxDir(td, nil, flate.BestSpeed); err == nil {
+		t.Fatal("should error")
+	}
+	if _, err := os.Stat(precious); err != nil {
+		t.Fatalf("should not remove foreign files: %s", err)
+	}
+}
+
+// readBox returns the contents of the files in a gzipped box by name.
+func readBox(t *testing.T, path string) map[string]string {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer f.Close()
+
+	gzipReader, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer gzipReader.Close()
+
+	result := make(map[string]string)
+	r := tar.NewReader(gzipReader)
+	for {
+		header, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		contents, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		result[header.Name] = string(contents)
+	}
+
+	return result
+}
@@ -35,7 +35,7 @@ func (p *VBoxProvider) Process(ui packer.Ui, artifact packer.Artifact, dir strin
 		} else {
 			ui.Message(fmt.Sprintf("Copying from artifact: %s", path))
 			dstPath := filepath.Join(dir, filepath.Base(path))
-			if err = CopyContents(dstPath, path); err != nil {
+			if err = LinkFile(dstPath, path); err != nil {
 				return
 			}
 		}
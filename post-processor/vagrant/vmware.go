@@ -21,7 +21,7 @@ func (p *VMwareProvider) Process(ui packer.Ui, artifact packer.Artifact, dir str
 		ui.Message(fmt.Sprintf("Copying: %s", path))
 
 		dstPath := filepath.Join(dir, filepath.Base(path))
-		if err = CopyContents(dstPath, path); err != nil {
+		if err = LinkFile(dstPath, path); err != nil {
 			return
 		}
 	}
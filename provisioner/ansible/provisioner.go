@@ -175,12 +175,16 @@ func (p *Provisioner) Provision(ui packer.Ui, comm packer.Communicator) error {
 		return err
 	}
 
-	hostSigner, err := newSigner(p.config.SSHHostKeyFile)
 	// Remove the private key file
 	if len(k.privKeyFile) > 0 {
 		defer os.Remove(k.privKeyFile)
 	}
 
+	hostSigner, err := newSigner(p.config.SSHHostKeyFile)
+	if err != nil {
+		return fmt.Errorf("error creating host signer: %s", err)
+	}
+
 	keyChecker := ssh.CertChecker{
 		UserKeyFallback: func(conn ssh.ConnMetadata, pubKey ssh.PublicKey) (*ssh.Permissions, error) {
 			if user := conn.User(); user != p.config.User {
@@ -349,12 +353,15 @@ func (p *Provisioner) executeAnsible(ui packer.Ui, comm packer.Communicator, pri
 		}
 		wg.Done()
 	}
+
+	log.Printf("Executing Ansible: %s", strings.Join(cmd.Args, " "))
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
 	wg.Add(2)
 	go repeat(stdout)
 	go repeat(stderr)
-
-	log.Printf("Executing Ansible: %s", strings.Join(cmd.Args, " "))
-	cmd.Start()
 	wg.Wait()
 	err = cmd.Wait()
 	if err != nil {
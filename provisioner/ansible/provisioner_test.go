@@ -282,3 +282,18 @@ func TestAnsibleLongMessages(t *testing.T) {
 		t.Fatalf("err: %s", err)
 	}
 }
+
+func TestProvisioner_executeAnsibleStartError(t *testing.T) {
+	var p Provisioner
+	p.config.Command = "./packer-ansible-does-not-exist"
+	p.config.PlaybookFile = "playbook.yml"
+
+	ui := &packer.BasicUi{
+		Reader: new(bytes.Buffer),
+		Writer: new(bytes.Buffer),
+	}
+
+	if err := p.executeAnsible(ui, &packer.MockCommunicator{}, ""); err == nil {
+		t.Fatal("should error when ansible can't be started")
+	}
+}
@@ -0,0 +1,138 @@
+// This package implements a provisioner for Packer that pauses the build
+// until the user tells it to continue, so the machine can be inspected.
+package breakpoint
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mitchellh/packer/common"
+	"github.com/mitchellh/packer/helper/config"
+	"github.com/mitchellh/packer/packer"
+	"github.com/mitchellh/packer/template/interpolate"
+)
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+
+	// Note is shown when pausing at the breakpoint.
+	Note string `mapstructure:"note"`
+
+	// If true, the breakpoint doesn't pause the build.
+	Disable bool `mapstructure:"disable"`
+
+	// The communicator settings of the builder, passed by Packer.
+	Communicator map[string]string `mapstructure:"packer_communicator"`
+
+	ctx interpolate.Context
+}
+
+type Provisioner struct {
+	config Config
+
+	cancelCh   chan struct{}
+	cancelOnce sync.Once
+}
+
+func (p *Provisioner) Prepare(raws ...interface{}) error {
+	err := config.Decode(&p.config, &config.DecodeOpts{
+		Interpolate:        true,
+		InterpolateContext: &p.config.ctx,
+		InterpolateFilter: &interpolate.RenderFilter{
+			Exclude: []string{},
+		},
+	}, raws...)
+	if err != nil {
+		return err
+	}
+
+	p.cancelCh = make(chan struct{})
+	return nil
+}
+
+func (p *Provisioner) Provision(ui packer.Ui, comm packer.Communicator) error {
+	if p.config.Disable {
+		if p.config.Note != "" {
+			ui.Say(fmt.Sprintf(
+				"Skipping disabled breakpoint with note: %s", p.config.Note))
+		} else {
+			ui.Say("Skipping disabled breakpoint")
+		}
+
+		return nil
+	}
+
+	message := fmt.Sprintf(
+		"Pausing at breakpoint in build '%s' (%s).",
+		p.config.PackerBuildName, p.config.PackerBuilderType)
+	if p.config.Note != "" {
+		message += fmt.Sprintf(" Note: %s", p.config.Note)
+	}
+	ui.Say(message)
+	ui.Message("The machine is running and can be inspected. " + p.connectionInfo())
+
+	resultCh := make(chan error, 1)
+	go func() {
+		_, err := ui.Ask("Press enter to continue.")
+		resultCh <- err
+	}()
+
+	select {
+	case err := <-resultCh:
+		if err != nil {
+			return fmt.Errorf("Error waiting at breakpoint: %s", err)
+		}
+	case <-p.cancelCh:
+	}
+
+	return nil
+}
+
+func (p *Provisioner) Cancel() {
+	p.cancelOnce.Do(func() {
+		if p.cancelCh != nil {
+			close(p.cancelCh)
+		}
+	})
+}
+
+// connectionInfo returns how to connect to the machine, based on the
+// communicator settings of the builder. Most builders only know the host
+// once the machine is running, in which case the user is pointed to the
+// connection info saved in debug mode.
+func (p *Provisioner) connectionInfo() string {
+	comm := p.config.Communicator
+	commType := comm["communicator"]
+	if commType == "" {
+		commType = "ssh"
+	}
+
+	port := comm[commType+"_port"]
+	if port == "" {
+		port = "22"
+		if commType == "winrm" {
+			port = "5985"
+		}
+	}
+
+	info := fmt.Sprintf("Connect to it with %s", commType)
+	if host := comm[commType+"_host"]; host != "" {
+		info += fmt.Sprintf(" on %s:%s", host, port)
+	}
+	if user := comm[commType+"_username"]; user != "" {
+		info += fmt.Sprintf(" as %s", user)
+	}
+	info += "."
+
+	if comm[commType+"_host"] == "" {
+		if p.config.PackerDebug {
+			info += " The host and port are saved in debug-connection.txt " +
+				"in the output directory, or shown by the builder above."
+		} else {
+			info += " Run Packer with -debug to save the host and port to " +
+				"debug-connection.txt in the output directory."
+		}
+	}
+
+	return info
+}
@@ -0,0 +1,116 @@
+package breakpoint
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/packer/packer"
+)
+
+func testUi(input string) *packer.BasicUi {
+	return &packer.BasicUi{
+		Reader:      strings.NewReader(input),
+		Writer:      new(bytes.Buffer),
+		ErrorWriter: new(bytes.Buffer),
+	}
+}
+
+func TestProvisioner_Impl(t *testing.T) {
+	var raw interface{}
+	raw = &Provisioner{}
+	if _, ok := raw.(packer.Provisioner); !ok {
+		t.Fatalf("must be a Provisioner")
+	}
+}
+
+func TestProvisionerPrepare_InvalidKey(t *testing.T) {
+	var p Provisioner
+	config := map[string]interface{}{
+		"i_should_not_be_valid": true,
+	}
+
+	if err := p.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestProvisionerProvision(t *testing.T) {
+	var p Provisioner
+	config := map[string]interface{}{
+		"note": "check the logs",
+	}
+	if err := p.Prepare(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ui := testUi("\n")
+	if err := p.Provision(ui, new(packer.MockCommunicator)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	out := ui.Writer.(*bytes.Buffer).String()
+	if !strings.Contains(out, "check the logs") {
+		t.Fatalf("should show the note: %s", out)
+	}
+	if !strings.Contains(out, "Press enter to continue") {
+		t.Fatalf("should ask to continue: %s", out)
+	}
+}
+
+func TestProvisionerProvision_disable(t *testing.T) {
+	var p Provisioner
+	config := map[string]interface{}{
+		"disable": true,
+	}
+	if err := p.Prepare(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ui := testUi("")
+	if err := p.Provision(ui, new(packer.MockCommunicator)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	out := ui.Writer.(*bytes.Buffer).String()
+	if strings.Contains(out, "Press enter") {
+		t.Fatalf("should not ask to continue: %s", out)
+	}
+}
+
+func TestProvisionerProvision_connectionInfo(t *testing.T) {
+	var p Provisioner
+	config := map[string]interface{}{
+		"packer_communicator": map[string]interface{}{
+			"ssh_host":     "10.0.0.5",
+			"ssh_port":     2222,
+			"ssh_username": "vagrant",
+		},
+	}
+	if err := p.Prepare(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ui := testUi("\n")
+	if err := p.Provision(ui, new(packer.MockCommunicator)); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	out := ui.Writer.(*bytes.Buffer).String()
+	if !strings.Contains(out, "Connect to it with ssh on 10.0.0.5:2222 as vagrant.") {
+		t.Fatalf("should show how to connect: %s", out)
+	}
+}
+
+func TestProvisionerCancel(t *testing.T) {
+	// Cancelling before Prepare or twice must not panic
+	var p Provisioner
+	p.Cancel()
+
+	p = Provisioner{}
+	if err := p.Prepare(map[string]interface{}{}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	p.Cancel()
+	p.Cancel()
+}
@@ -0,0 +1,139 @@
+package file
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// validateExclude returns an error if one of the exclude patterns is
+// malformed.
+func validateExclude(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// excluded returns whether the path, relative to the directory being
+// uploaded, matches one of the exclude patterns. A pattern matches either
+// the whole relative path or just the last element of it, so ".git"
+// excludes every .git directory.
+func excluded(rel string, patterns []string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, p := range patterns {
+		p = strings.TrimSuffix(filepath.ToSlash(p), "/")
+		if ok, _ := path.Match(p, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, path.Base(rel)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// stageDir copies the directory src into a new temporary directory, leaving
+// out everything that matches the exclude patterns. It returns the
+// temporary directory, which the caller must remove, and the path to
+// upload in place of src. Communicators don't all support excluding files,
+// so uploading the staged copy works with all of them.
+func stageDir(src string, patterns []string) (string, string, error) {
+	td, err := ioutil.TempDir("", "packer-file")
+	if err != nil {
+		return "", "", err
+	}
+
+	root := filepath.Clean(src)
+	staged := filepath.Join(td, filepath.Base(root))
+	walkFn := func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if rel != "." && excluded(rel, patterns) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		dst := filepath.Join(staged, rel)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(dst, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			return stageSymlink(root, p, dst)
+		default:
+			return stageFile(p, dst, info.Mode().Perm())
+		}
+	}
+
+	if err := filepath.Walk(root, walkFn); err != nil {
+		os.RemoveAll(td)
+		return "", "", err
+	}
+
+	// Keep the trailing slash, since it decides whether the directory
+	// itself or only its contents are uploaded.
+	if strings.HasSuffix(src, "/") {
+		staged += "/"
+	}
+
+	return td, staged, nil
+}
+
+func stageFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// stageSymlink recreates the symlink src at dst. Relative links that point
+// out of the directory being staged are made absolute so they still point
+// to the same file.
+func stageSymlink(root, src, dst string) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+
+	if !filepath.IsAbs(target) {
+		resolved := filepath.Join(filepath.Dir(src), target)
+		rel, err := filepath.Rel(root, resolved)
+		if err != nil || rel == ".." ||
+			strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			target, err = filepath.Abs(resolved)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return os.Symlink(target, dst)
+}
@@ -0,0 +1,81 @@
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExcluded(t *testing.T) {
+	patterns := []string{".git", "test/fixtures/", "*.bak"}
+	cases := []struct {
+		Path   string
+		Result bool
+	}{
+		{"foo.txt", false},
+		{".git", true},
+		{"sub/.git", true},
+		{"test/fixtures", true},
+		{"test", false},
+		{"sub/foo.bak", true},
+		{"sub/foo.bak.txt", false},
+	}
+
+	for _, tc := range cases {
+		if actual := excluded(filepath.FromSlash(tc.Path), patterns); actual != tc.Result {
+			t.Fatalf("bad: %s: %v", tc.Path, actual)
+		}
+	}
+}
+
+func TestValidateExclude(t *testing.T) {
+	if err := validateExclude([]string{"*.bak", ".git"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := validateExclude([]string{"[oops"}); err == nil {
+		t.Fatal("should error on malformed pattern")
+	}
+}
+
+func TestStageDir(t *testing.T) {
+	src, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(src)
+
+	files := []string{"a.txt", ".git/config", "sub/b.txt", "sub/c.bak"}
+	for _, f := range files {
+		p := filepath.Join(src, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if err := ioutil.WriteFile(p, []byte(f), 0644); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	td, staged, err := stageDir(src+"/", []string{".git", "*.bak"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	if staged != filepath.Join(td, filepath.Base(src))+"/" {
+		t.Fatalf("bad staged path: %s", staged)
+	}
+
+	expected := map[string]bool{
+		"a.txt":       true,
+		".git/config": false,
+		"sub/b.txt":   true,
+		"sub/c.bak":   false,
+	}
+	for f, exists := range expected {
+		_, err := os.Stat(filepath.Join(staged, filepath.FromSlash(f)))
+		if (err == nil) != exists {
+			t.Fatalf("bad: %s: exists should be %v", f, exists)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package file
 import (
 	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
@@ -16,16 +17,25 @@ import (
 type Config struct {
 	common.PackerConfig `mapstructure:",squash"`
 
-	// The local path of the file to upload.
+	// The local path of the file to upload. When uploading, the sources
+	// may be glob patterns.
 	Source  string
 	Sources []string
 
+	// Patterns of files and directories to leave out when uploading
+	// a directory.
+	Exclude []string
+
 	// The remote path where the local file will be uploaded to.
 	Destination string
 
 	// Direction
 	Direction string
 
+	// If true, directories are transferred as a gzipped tar stream that
+	// tar on the remote machine packs or unpacks, when it is available.
+	Compress bool
+
 	ctx interpolate.Context
 }
 
@@ -61,13 +71,26 @@ func (p *Provisioner) Prepare(raws ...interface{}) error {
 
 	if p.config.Direction == "upload" {
 		for _, src := range p.config.Sources {
-			if _, err := os.Stat(src); err != nil {
+			matches, err := expandSource(src)
+			if err != nil {
 				errs = packer.MultiErrorAppend(errs,
 					fmt.Errorf("Bad source '%s': %s", src, err))
+				continue
+			}
+
+			if len(matches) > 1 && !strings.HasSuffix(p.config.Destination, "/") {
+				errs = packer.MultiErrorAppend(errs, fmt.Errorf(
+					"Source '%s' matches several files, so the destination "+
+						"must be a directory ending with a /.", src))
 			}
 		}
 	}
 
+	if err := validateExclude(p.config.Exclude); err != nil {
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("Bad exclude pattern: %s", err))
+	}
+
 	if len(p.config.Sources) < 1 {
 		errs = packer.MultiErrorAppend(errs,
 			errors.New("Source must be specified."))
@@ -113,6 +136,9 @@ func (p *Provisioner) ProvisionDownload(ui packer.Ui, comm packer.Communicator)
 		}
 		// if the src was a dir, download the dir
 		if strings.HasSuffix(src, "/") || strings.IndexAny(src, "*?[") >= 0 {
+			if p.config.Compress && strings.HasSuffix(src, "/") && tarAvailable(comm) {
+				return downloadDirTar(comm, src, dst)
+			}
 			return comm.DownloadDir(src, dst, nil)
 		}
 
@@ -132,7 +158,23 @@ func (p *Provisioner) ProvisionDownload(ui packer.Ui, comm packer.Communicator)
 }
 
 func (p *Provisioner) ProvisionUpload(ui packer.Ui, comm packer.Communicator) error {
+	var sources []string
 	for _, src := range p.config.Sources {
+		matches, err := expandSource(src)
+		if err != nil {
+			return fmt.Errorf("Bad source '%s': %s", src, err)
+		}
+
+		for _, m := range matches {
+			if m != src && excluded(filepath.Base(m), p.config.Exclude) {
+				continue
+			}
+
+			sources = append(sources, m)
+		}
+	}
+
+	for _, src := range sources {
 		dst := p.config.Destination
 
 		ui.Say(fmt.Sprintf("Uploading %s => %s", src, dst))
@@ -142,9 +184,13 @@ func (p *Provisioner) ProvisionUpload(ui packer.Ui, comm packer.Communicator) er
 			return err
 		}
 
-		// If we're uploading a directory, short circuit and do that
 		if info.IsDir() {
-			return comm.UploadDir(p.config.Destination, src, nil)
+			if err := p.uploadDir(comm, dst, src); err != nil {
+				ui.Error(fmt.Sprintf("Upload failed: %s", err))
+				return err
+			}
+
+			continue
 		}
 
 		// We're uploading a file...
@@ -172,6 +218,52 @@ func (p *Provisioner) ProvisionUpload(ui packer.Ui, comm packer.Communicator) er
 	return nil
 }
 
+// uploadDir uploads the directory src, leaving out what matches the
+// exclude patterns.
+func (p *Provisioner) uploadDir(comm packer.Communicator, dst, src string) error {
+	if p.config.Compress {
+		if tarAvailable(comm) {
+			return uploadDirTar(comm, dst, src, p.config.Exclude)
+		}
+
+		log.Printf("tar is not available on the machine, uploading %s uncompressed", src)
+	}
+
+	if len(p.config.Exclude) == 0 {
+		return comm.UploadDir(dst, src, nil)
+	}
+
+	td, staged, err := stageDir(src, p.config.Exclude)
+	if err != nil {
+		return fmt.Errorf("Error preparing directory upload: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	return comm.UploadDir(dst, staged, nil)
+}
+
+// expandSource returns the source itself if it exists, or otherwise the
+// files matching it as a glob pattern. It is an error if nothing matches.
+func expandSource(src string) ([]string, error) {
+	_, err := os.Stat(src)
+	if err == nil {
+		return []string{src}, nil
+	}
+	if strings.IndexAny(src, "*?[") < 0 {
+		return nil, err
+	}
+
+	matches, err := filepath.Glob(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, errors.New("no files match the pattern")
+	}
+
+	return matches, nil
+}
+
 func (p *Provisioner) Cancel() {
 	// Just hard quit. It isn't a big deal if what we're doing keeps
 	// running on the other side.
@@ -198,3 +198,73 @@ func TestProvisionDownloadMkdirAll(t *testing.T) {
 		}
 	}
 }
+
+func TestProvisionerPrepare_GlobSource(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	for _, f := range []string{"a.txt", "b.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(td, f), []byte(f), 0644); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	var p Provisioner
+	config := testConfig()
+	config["source"] = filepath.Join(td, "*.txt")
+	if err := p.Prepare(config); err == nil {
+		t.Fatal("should require a directory destination for several files")
+	}
+
+	p = Provisioner{}
+	config["destination"] = "/tmp/"
+	if err := p.Prepare(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	p = Provisioner{}
+	config["source"] = filepath.Join(td, "*.nothing")
+	if err := p.Prepare(config); err == nil {
+		t.Fatal("should require the glob to match")
+	}
+}
+
+func TestProvisionerProvision_GlobSourceExclude(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	for _, f := range []string{"a.txt", "b.bak"} {
+		if err := ioutil.WriteFile(filepath.Join(td, f), []byte(f), 0644); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	var p Provisioner
+	config := map[string]interface{}{
+		"source":      filepath.Join(td, "*"),
+		"destination": "/tmp/",
+		"exclude":     []string{"*.bak"},
+	}
+	if err := p.Prepare(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ui := &stubUi{}
+	comm := &packer.MockCommunicator{}
+	if err := p.Provision(ui, comm); err != nil {
+		t.Fatalf("should successfully provision: %s", err)
+	}
+
+	if strings.Contains(ui.sayMessages, "b.bak") {
+		t.Fatalf("should not upload excluded file: %s", ui.sayMessages)
+	}
+	if comm.UploadPath != "/tmp/a.txt" {
+		t.Fatalf("bad upload path: %s", comm.UploadPath)
+	}
+}
@@ -0,0 +1,246 @@
+package file
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/packer/packer"
+)
+
+// tarAvailable returns whether tar can be run on the remote machine.
+func tarAvailable(comm packer.Communicator) bool {
+	cmd := &packer.RemoteCmd{Command: "command -v tar"}
+	if err := comm.Start(cmd); err != nil {
+		return false
+	}
+	cmd.Wait()
+
+	return cmd.ExitStatus == 0
+}
+
+// uploadDirTar uploads the directory src to dst by streaming it as a
+// gzipped tar to tar running on the remote machine. Like UploadDir, the
+// directory itself is created in dst unless src ends with a slash.
+func uploadDirTar(comm packer.Communicator, dst, src string, exclude []string) error {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeTarGz(pw, src, exclude))
+	}()
+
+	var stderr bytes.Buffer
+	cmd := &packer.RemoteCmd{
+		Command: fmt.Sprintf("mkdir -p %s && tar -xzf - -C %s",
+			shellQuote(dst), shellQuote(dst)),
+		Stdin:   pr,
+		Stderr:  &stderr,
+	}
+	if err := comm.Start(cmd); err != nil {
+		pr.Close()
+		return err
+	}
+	cmd.Wait()
+	pr.Close()
+
+	if cmd.ExitStatus != 0 {
+		return fmt.Errorf(
+			"tar exited with status %d: %s", cmd.ExitStatus, stderr.String())
+	}
+
+	return nil
+}
+
+// downloadDirTar downloads the contents of the remote directory src into
+// dst by streaming it as a gzipped tar from tar running on the remote
+// machine.
+func downloadDirTar(comm packer.Communicator, src, dst string) error {
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+
+	pr, pw := io.Pipe()
+	var stderr bytes.Buffer
+	cmd := &packer.RemoteCmd{
+		Command: fmt.Sprintf("tar -czf - -C %s .", shellQuote(src)),
+		Stdout:  pw,
+		Stderr:  &stderr,
+	}
+	if err := comm.Start(cmd); err != nil {
+		return err
+	}
+
+	go func() {
+		cmd.Wait()
+		if cmd.ExitStatus != 0 {
+			pw.CloseWithError(fmt.Errorf(
+				"tar exited with status %d: %s", cmd.ExitStatus, stderr.String()))
+			return
+		}
+		pw.Close()
+	}()
+
+	err := extractTarGz(pr, dst)
+
+	// Drain the rest of the output so the command can finish
+	io.Copy(ioutil.Discard, pr)
+	return err
+}
+
+// writeTarGz writes the directory src as a gzipped tar to w, leaving out
+// everything that matches the exclude patterns. Unless src ends with a
+// slash, the entries are put in a directory named after src.
+func writeTarGz(w io.Writer, src string, exclude []string) error {
+	root := filepath.Clean(src)
+	prefix := ""
+	if !strings.HasSuffix(src, "/") {
+		prefix = filepath.Base(root)
+	}
+
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+	walkFn := func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." && prefix == "" {
+			return nil
+		}
+		if rel != "." && excluded(rel, exclude) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = path.Join(prefix, filepath.ToSlash(rel))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+		return err
+	}
+
+	if err := filepath.Walk(root, walkFn); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gzw.Close()
+}
+
+// extractTarGz extracts the gzipped tar read from r into dst. Symlinks are
+// created once everything else is extracted, and entries below a symlink
+// of the archive are rejected, so that nothing is written outside of dst
+// through a symlink.
+func extractTarGz(r io.Reader, dst string) error {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gzr.Close()
+
+	symlinks := make(map[string]string)
+	var symlinkNames []string
+
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.FromSlash(path.Clean(header.Name))
+		if name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) ||
+			filepath.IsAbs(name) {
+			return fmt.Errorf("invalid path in archive: %s", header.Name)
+		}
+		for dir := filepath.Dir(name); dir != "."; dir = filepath.Dir(dir) {
+			if _, ok := symlinks[dir]; ok {
+				return fmt.Errorf("invalid path below a symlink in archive: %s", header.Name)
+			}
+		}
+		target := filepath.Join(dst, name)
+		mode := os.FileMode(header.Mode).Perm()
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, mode); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if _, ok := symlinks[name]; !ok {
+				symlinkNames = append(symlinkNames, name)
+			}
+			symlinks[name] = header.Linkname
+		case tar.TypeReg, tar.TypeRegA:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, name := range symlinkNames {
+		if err := os.Symlink(symlinks[name], filepath.Join(dst, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// shellQuote quotes s to be used as a single argument in a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'"'"'`, -1) + "'"
+}
@@ -0,0 +1,167 @@
+package file
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/packer/packer"
+)
+
+func testTarDir(t *testing.T) string {
+	src, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	files := []string{"a.txt", ".git/config", "sub/b.txt"}
+	for _, f := range files {
+		p := filepath.Join(src, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if err := ioutil.WriteFile(p, []byte(f), 0644); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	return src
+}
+
+func TestWriteTarGz(t *testing.T) {
+	src := testTarDir(t)
+	defer os.RemoveAll(src)
+
+	cases := []struct {
+		Src      string
+		Expected map[string]bool
+	}{
+		{
+			src,
+			map[string]bool{
+				filepath.Base(src) + "/a.txt":       true,
+				filepath.Base(src) + "/sub/b.txt":   true,
+				filepath.Base(src) + "/.git/config": false,
+			},
+		},
+		{
+			src + "/",
+			map[string]bool{
+				"a.txt":       true,
+				"sub/b.txt":   true,
+				".git/config": false,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		dst, err := ioutil.TempDir("", "packer")
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		defer os.RemoveAll(dst)
+
+		var buf bytes.Buffer
+		if err := writeTarGz(&buf, tc.Src, []string{".git"}); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if err := extractTarGz(&buf, dst); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		for f, exists := range tc.Expected {
+			data, err := ioutil.ReadFile(filepath.Join(dst, filepath.FromSlash(f)))
+			if (err == nil) != exists {
+				t.Fatalf("bad: %s: exists should be %v", f, exists)
+			}
+			if exists && !strings.HasSuffix(f, string(data)) {
+				t.Fatalf("bad: %s: %s", f, data)
+			}
+		}
+	}
+}
+
+func TestExtractTarGz_symlink(t *testing.T) {
+	outside, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(outside)
+
+	dst, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dst)
+
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	tw.WriteHeader(&tar.Header{
+		Name:     "a",
+		Typeflag: tar.TypeSymlink,
+		Linkname: outside,
+	})
+	tw.WriteHeader(&tar.Header{
+		Name:     "a/passwd",
+		Typeflag: tar.TypeReg,
+		Mode:     0644,
+		Size:     3,
+	})
+	tw.Write([]byte("foo"))
+	tw.Close()
+	gzw.Close()
+
+	if err := extractTarGz(&buf, dst); err == nil {
+		t.Fatal("should error")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "passwd")); err == nil {
+		t.Fatal("should not write through the symlink")
+	}
+}
+
+func TestProvisionerProvision_UploadDirCompress(t *testing.T) {
+	src := testTarDir(t)
+	defer os.RemoveAll(src)
+
+	var p Provisioner
+	config := map[string]interface{}{
+		"source":      src + "/",
+		"destination": "/tmp/it's here",
+		"compress":    true,
+	}
+	if err := p.Prepare(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	comm := &packer.MockCommunicator{}
+	if err := p.Provision(&stubUi{}, comm); err != nil {
+		t.Fatalf("should successfully provision: %s", err)
+	}
+
+	expected := `mkdir -p '/tmp/it'"'"'s here' && tar -xzf - -C '/tmp/it'"'"'s here'`
+	if comm.StartCmd.Command != expected {
+		t.Fatalf("bad command: %s", comm.StartCmd.Command)
+	}
+	if comm.UploadDirSrc != "" {
+		t.Fatal("should not upload the directory uncompressed")
+	}
+
+	dst, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dst)
+
+	if err := extractTarGz(strings.NewReader(comm.StartStdin), dst); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "sub", "b.txt")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
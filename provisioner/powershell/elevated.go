@@ -1,6 +1,7 @@
 package powershell
 
 import (
+	"strings"
 	"text/template"
 )
 
@@ -12,7 +13,20 @@ type elevatedOptions struct {
 	EncodedCommand  string
 }
 
-var elevatedTemplate = template.Must(template.New("ElevatedCommand").Parse(`
+// psEscape escapes a string to be put in a single quoted PowerShell
+// string, in which every kind of single quote must be doubled.
+func psEscape(s string) string {
+	for _, q := range []string{"'", "\u2018", "\u2019", "\u201a", "\u201b"} {
+		s = strings.Replace(s, q, q+q, -1)
+	}
+	return s
+}
+
+// The user and password are escaped with the "html" function in the task
+// XML, since its escapes are valid XML as well.
+var elevatedTemplate = template.Must(template.New("ElevatedCommand").Funcs(template.FuncMap{
+	"psEscape": psEscape,
+}).Parse(`
 $name = "{{.TaskName}}"
 $log = "$env:SystemRoot\Temp\$name.out"
 $s = New-Object -ComObject "Schedule.Service"
@@ -26,7 +40,7 @@ $t.XmlText = @'
   </RegistrationInfo>
   <Principals>
     <Principal id="Author">
-      <UserId>{{.User}}</UserId>
+      <UserId>{{html .User}}</UserId>
       <LogonType>Password</LogonType>
       <RunLevel>HighestAvailable</RunLevel>
     </Principal>
@@ -60,7 +74,7 @@ $t.XmlText = @'
 '@
 if (Test-Path variable:global:ProgressPreference){$ProgressPreference="SilentlyContinue"}
 $f = $s.GetFolder("\")
-$f.RegisterTaskDefinition($name, $t, 6, "{{.User}}", "{{.Password}}", 1, $null) | Out-Null
+$f.RegisterTaskDefinition($name, $t, 6, '{{psEscape .User}}', '{{psEscape .Password}}', 1, $null) | Out-Null
 $t = $f.GetTask("\$name")
 $t.Run($null) | Out-Null
 $timeout = 10
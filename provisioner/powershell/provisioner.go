@@ -457,11 +457,14 @@ func (p *Provisioner) generateElevatedRunner(command string) (uploadedPath strin
 	})
 
 	if err != nil {
-		fmt.Printf("Error creating elevated template: %s", err)
-		return "", err
+		return "", fmt.Errorf("Error creating elevated template: %s", err)
 	}
 
 	tmpFile, err := ioutil.TempFile(os.TempDir(), "packer-elevated-shell.ps1")
+	if err != nil {
+		return "", fmt.Errorf("Error preparing elevated shell script: %s", err)
+	}
+	defer os.Remove(tmpFile.Name())
 	writer := bufio.NewWriter(tmpFile)
 	if _, err := writer.WriteString(string(buffer.Bytes())); err != nil {
 		return "", fmt.Errorf("Error preparing elevated shell script: %s", err)
@@ -685,6 +685,31 @@ func TestProvision_generateElevatedShellRunner(t *testing.T) {
 	}
 }
 
+func TestProvision_generateElevatedShellRunnerEscaping(t *testing.T) {
+	config := testConfig()
+	config["elevated_user"] = `DOMAIN\o'brien&co`
+	config["elevated_password"] = `pa$s"wo'rd`
+	p := new(Provisioner)
+	if err := p.Prepare(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	comm := new(packer.MockCommunicator)
+	p.communicator = comm
+	if _, err := p.generateElevatedRunner("whoami"); err != nil {
+		t.Fatalf("Did not expect error: %s", err.Error())
+	}
+
+	expected := []string{
+		`<UserId>DOMAIN\o&#39;brien&amp;co</UserId>`,
+		`'DOMAIN\o''brien&co', 'pa$s"wo''rd'`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(comm.UploadData, e) {
+			t.Fatalf("Expected %q in elevated runner:\n%s", e, comm.UploadData)
+		}
+	}
+}
+
 func TestRetryable(t *testing.T) {
 	config := testConfig()
 
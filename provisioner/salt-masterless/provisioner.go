@@ -58,6 +58,11 @@ type Config struct {
 	// Arguments to pass to salt-call
 	SaltCallArgs string `mapstructure:"salt_call_args"`
 
+	// The directory salt-call is in on the remote machine, such as the
+	// bin directory of a pinned Salt install. By default salt-call is
+	// looked up in the PATH.
+	SaltBinDir string `mapstructure:"salt_bin_dir"`
+
 	// Command line args passed onto salt-call
 	CmdArgs string ""
 
@@ -252,8 +257,13 @@ func (p *Provisioner) Provision(ui packer.Ui, comm packer.Communicator) error {
 		}
 	}
 
-	ui.Message(fmt.Sprintf("Running: salt-call --local %s", p.config.CmdArgs))
-	cmd := &packer.RemoteCmd{Command: p.sudo(fmt.Sprintf("salt-call --local %s", p.config.CmdArgs))}
+	saltCall := "salt-call"
+	if p.config.SaltBinDir != "" {
+		saltCall = filepath.ToSlash(filepath.Join(p.config.SaltBinDir, saltCall))
+	}
+
+	ui.Message(fmt.Sprintf("Running: %s --local %s", saltCall, p.config.CmdArgs))
+	cmd := &packer.RemoteCmd{Command: p.sudo(fmt.Sprintf("%s --local %s", saltCall, p.config.CmdArgs))}
 	if err = cmd.StartWithUi(comm, ui); err != nil || cmd.ExitStatus != 0 {
 		if err == nil {
 			err = fmt.Errorf("Bad exit status: %d", cmd.ExitStatus)
@@ -1,6 +1,7 @@
 package saltmasterless
 
 import (
+	"bytes"
 	"github.com/mitchellh/packer/packer"
 	"io/ioutil"
 	"os"
@@ -285,3 +286,27 @@ func TestProvisionerPrepare_LogLevel(t *testing.T) {
 		t.Fatal("-l debug should be set in CmdArgs")
 	}
 }
+
+func TestProvisionerProvision_SaltBinDir(t *testing.T) {
+	var p Provisioner
+	config := testConfig()
+	config["skip_bootstrap"] = true
+	config["salt_bin_dir"] = "/opt/salt/bin"
+
+	if err := p.Prepare(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	comm := &packer.MockCommunicator{}
+	ui := &packer.BasicUi{
+		Reader: new(bytes.Buffer),
+		Writer: new(bytes.Buffer),
+	}
+	if err := p.Provision(ui, comm); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !strings.HasPrefix(comm.StartCmd.Command, "sudo /opt/salt/bin/salt-call --local") {
+		t.Fatalf("bad command: %s", comm.StartCmd.Command)
+	}
+}
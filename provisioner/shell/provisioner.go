@@ -4,6 +4,7 @@ package shell
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -61,8 +62,15 @@ type Config struct {
 	// The command used to execute the script. The '{{ .Path }}' variable
 	// should be used to specify where the script goes, {{ .Vars }}
 	// can be used to inject the environment_vars into the environment.
+	// With use_env_var_file, '{{ .EnvVarFile }}' is the path of the file
+	// that exports them.
 	ExecuteCommand string `mapstructure:"execute_command"`
 
+	// If true, the environment variables are written to a file that is
+	// uploaded next to the script and sourced before it runs, instead of
+	// being set on the command line.
+	UseEnvVarFile bool `mapstructure:"use_env_var_file"`
+
 	// The timeout for retrying to start the process. Until this timeout
 	// is reached, if the provisioner can't start a process, it retries.
 	// This can be set high to allow for reboots.
@@ -74,6 +82,7 @@ type Config struct {
 	ExpectDisconnect *bool `mapstructure:"expect_disconnect"`
 
 	startRetryTimeout time.Duration
+	envVarFile        string
 	ctx               interpolate.Context
 }
 
@@ -82,8 +91,9 @@ type Provisioner struct {
 }
 
 type ExecuteCommandTemplate struct {
-	Vars string
-	Path string
+	Vars       string
+	EnvVarFile string
+	Path       string
 }
 
 func (p *Provisioner) Prepare(raws ...interface{}) error {
@@ -102,6 +112,9 @@ func (p *Provisioner) Prepare(raws ...interface{}) error {
 
 	if p.config.ExecuteCommand == "" {
 		p.config.ExecuteCommand = "chmod +x {{.Path}}; {{.Vars}} {{.Path}}"
+		if p.config.UseEnvVarFile {
+			p.config.ExecuteCommand = "chmod +x {{.Path}}; . {{.EnvVarFile}} && {{.Path}}"
+		}
 	}
 
 	if p.config.ExpectDisconnect == nil {
@@ -134,6 +147,11 @@ func (p *Provisioner) Prepare(raws ...interface{}) error {
 			"%s/%s", p.config.RemoteFolder, p.config.RemoteFile)
 	}
 
+	if p.config.UseEnvVarFile {
+		p.config.envVarFile = fmt.Sprintf(
+			"%s/varfile_%d.sh", p.config.RemoteFolder, rand.Intn(9999))
+	}
+
 	if p.config.Scripts == nil {
 		p.config.Scripts = make([]string, 0)
 	}
@@ -225,6 +243,7 @@ func (p *Provisioner) Provision(ui packer.Ui, comm packer.Communicator) error {
 
 	// Create environment variables to set before executing the command
 	flattenedEnvVars := p.createFlattenedEnvVars()
+	envVarFileContent := p.createEnvVarFileContent()
 
 	for _, path := range scripts {
 		ui.Say(fmt.Sprintf("Provisioning with shell script: %s", path))
@@ -238,8 +257,9 @@ func (p *Provisioner) Provision(ui packer.Ui, comm packer.Communicator) error {
 
 		// Compile the command
 		p.config.ctx.Data = &ExecuteCommandTemplate{
-			Vars: flattenedEnvVars,
-			Path: p.config.RemotePath,
+			Vars:       flattenedEnvVars,
+			EnvVarFile: p.config.envVarFile,
+			Path:       p.config.RemotePath,
 		}
 		command, err := interpolate.Render(p.config.ExecuteCommand, &p.config.ctx)
 		if err != nil {
@@ -266,6 +286,17 @@ func (p *Provisioner) Provision(ui packer.Ui, comm packer.Communicator) error {
 				return fmt.Errorf("Error uploading script: %s", err)
 			}
 
+			// The environment variable file is uploaded with each script
+			// since a reboot may have cleaned up the remote folder.
+			if p.config.UseEnvVarFile {
+				err := comm.Upload(p.config.envVarFile,
+					strings.NewReader(envVarFileContent), nil)
+				if err != nil {
+					return fmt.Errorf(
+						"Error uploading environment variable file: %s", err)
+				}
+			}
+
 			cmd = &packer.RemoteCmd{
 				Command: fmt.Sprintf("chmod 0755 %s", p.config.RemotePath),
 			}
@@ -299,9 +330,13 @@ func (p *Provisioner) Provision(ui packer.Ui, comm packer.Communicator) error {
 			// Delete the temporary file we created. We retry this a few times
 			// since if the above rebooted we have to wait until the reboot
 			// completes.
+			rmPaths := p.config.RemotePath
+			if p.config.UseEnvVarFile {
+				rmPaths += " " + p.config.envVarFile
+			}
 			err = p.retryable(func() error {
 				cmd = &packer.RemoteCmd{
-					Command: fmt.Sprintf("rm -f %s", p.config.RemotePath),
+					Command: fmt.Sprintf("rm -f %s", rmPaths),
 				}
 				if err := comm.Start(cmd); err != nil {
 					return fmt.Errorf(
@@ -363,7 +398,30 @@ func (p *Provisioner) retryable(f func() error) error {
 }
 
 func (p *Provisioner) createFlattenedEnvVars() (flattened string) {
-	flattened = ""
+	keys, envVars := p.escapedEnvVars()
+
+	// Re-assemble vars surrounding value with single quotes and flatten
+	for _, key := range keys {
+		flattened += fmt.Sprintf("%s='%s' ", key, envVars[key])
+	}
+	return
+}
+
+// createEnvVarFileContent returns the contents of the file that exports
+// the environment variables when use_env_var_file is set.
+func (p *Provisioner) createEnvVarFileContent() string {
+	keys, envVars := p.escapedEnvVars()
+
+	var buf bytes.Buffer
+	for _, key := range keys {
+		fmt.Fprintf(&buf, "export %s='%s'\n", key, envVars[key])
+	}
+	return buf.String()
+}
+
+// escapedEnvVars returns the sorted names of the environment variables to
+// set and their values, escaped to be put in single quotes.
+func (p *Provisioner) escapedEnvVars() ([]string, map[string]string) {
 	envVars := make(map[string]string)
 
 	// Always available Packer provided env vars
@@ -389,9 +447,5 @@ func (p *Provisioner) createFlattenedEnvVars() (flattened string) {
 	}
 	sort.Strings(keys)
 
-	// Re-assemble vars surrounding value with single quotes and flatten
-	for _, key := range keys {
-		flattened += fmt.Sprintf("%s='%s' ", key, envVars[key])
-	}
-	return
+	return keys, envVars
 }
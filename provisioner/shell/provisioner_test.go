@@ -286,6 +286,36 @@ func TestProvisioner_createFlattenedEnvVars(t *testing.T) {
 	}
 }
 
+func TestProvisioner_createEnvVarFileContent(t *testing.T) {
+	config := testConfig()
+	config["use_env_var_file"] = true
+	config["environment_vars"] = []string{"FOO=bar's", "BAZ=qux"}
+
+	p := new(Provisioner)
+	if err := p.Prepare(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	p.config.PackerBuildName = "vmware"
+	p.config.PackerBuilderType = "iso"
+
+	expected := `export BAZ='qux'
+export FOO='bar'"'"'s'
+export PACKER_BUILDER_TYPE='iso'
+export PACKER_BUILD_NAME='vmware'
+`
+	if actual := p.createEnvVarFileContent(); actual != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	if !strings.HasPrefix(p.config.envVarFile, "/tmp/varfile_") {
+		t.Fatalf("bad env var file: %s", p.config.envVarFile)
+	}
+	if !strings.Contains(p.config.ExecuteCommand, ". {{.EnvVarFile}}") {
+		t.Fatalf("bad execute command: %s", p.config.ExecuteCommand)
+	}
+}
+
 func TestProvisioner_RemoteFolderSetSuccessfully(t *testing.T) {
 	config := testConfig()
 
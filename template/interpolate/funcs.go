@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"text/template"
@@ -34,8 +35,12 @@ var FuncGens = map[string]FuncGenerator{
 	"uuid":         funcGenUuid,
 	"user":         funcGenUser,
 
-	"upper": funcGenPrimitive(strings.ToUpper),
-	"lower": funcGenPrimitive(strings.ToLower),
+	"upper":         funcGenPrimitive(strings.ToUpper),
+	"lower":         funcGenPrimitive(strings.ToLower),
+	"replace":       funcGenPrimitive(replace),
+	"regex_replace": funcGenPrimitive(regexReplace),
+	"split":         funcGenPrimitive(split),
+	"join":          funcGenPrimitive(join),
 }
 
 // FuncGenerator is a function that given a context generates a template
@@ -152,3 +157,27 @@ func funcGenUuid(ctx *Context) interface{} {
 		return uuid.TimeOrderedUUID()
 	}
 }
+
+// The string functions below take the string to work on as the last
+// argument, so they can be used at the end of a pipeline.
+
+func replace(old, new, s string) string {
+	return strings.Replace(s, old, new, -1)
+}
+
+func regexReplace(expr, repl, s string) (string, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return "", err
+	}
+
+	return re.ReplaceAllString(s, repl), nil
+}
+
+func split(sep, s string) []string {
+	return strings.Split(s, sep)
+}
+
+func join(sep string, elems []string) string {
+	return strings.Join(elems, sep)
+}
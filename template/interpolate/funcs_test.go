@@ -257,3 +257,59 @@ func TestFuncUser(t *testing.T) {
 		}
 	}
 }
+
+func TestFuncStrings(t *testing.T) {
+	cases := []struct {
+		Input  string
+		Output string
+	}{
+		{
+			`{{replace "." "-" "1.2.3"}}`,
+			`1-2-3`,
+		},
+
+		{
+			`{{user "version" | replace "." ""}}`,
+			`123`,
+		},
+
+		{
+			`{{regex_replace "^([0-9]+)\\..*$" "v$1" "1.2.3"}}`,
+			`v1`,
+		},
+
+		{
+			`{{index (split "." "1.2.3") 1}}`,
+			`2`,
+		},
+
+		{
+			`{{split "." "1.2.3" | join "-"}}`,
+			`1-2-3`,
+		},
+	}
+
+	ctx := &Context{
+		UserVariables: map[string]string{
+			"version": "1.2.3",
+		},
+	}
+	for _, tc := range cases {
+		i := &I{Value: tc.Input}
+		result, err := i.Render(ctx)
+		if err != nil {
+			t.Fatalf("Input: %s\n\nerr: %s", tc.Input, err)
+		}
+
+		if result != tc.Output {
+			t.Fatalf("Input: %s\n\nGot: %s", tc.Input, result)
+		}
+	}
+}
+
+func TestFuncRegexReplace_invalid(t *testing.T) {
+	i := &I{Value: `{{regex_replace "(" "" "foo"}}`}
+	if _, err := i.Render(&Context{}); err == nil {
+		t.Fatal("should error")
+	}
+}
@@ -2,6 +2,7 @@ package interpolate
 
 import (
 	"fmt"
+	"regexp"
 	"text/template"
 	"text/template/parse"
 )
@@ -40,3 +41,78 @@ func functionsCalledWalk(raw parse.Node, r map[string]struct{}) {
 		panic(fmt.Sprintf("unknown type: %T", node))
 	}
 }
+
+// undefinedFuncRe matches the error text/template returns when a template
+// calls a function that isn't in its function map.
+var undefinedFuncRe = regexp.MustCompile(`function "([^"]+)" not defined`)
+
+// UserVariablesCalled returns a map (to be used as a set) of the names of
+// the user variables that are read with the "user" function in the given
+// template string. Functions that are only available in some contexts,
+// such as those added by builders, are allowed.
+func UserVariablesCalled(v string) (map[string]struct{}, error) {
+	funcs := Funcs(nil)
+
+	var tpl *template.Template
+	for {
+		var err error
+		tpl, err = template.New("root").Funcs(funcs).Parse(v)
+		if err == nil {
+			break
+		}
+
+		// Define unknown functions as we find them, since we only
+		// care about the structure of the template.
+		m := undefinedFuncRe.FindStringSubmatch(err.Error())
+		if m == nil {
+			return nil, err
+		}
+		if _, ok := funcs[m[1]]; ok {
+			return nil, err
+		}
+		funcs[m[1]] = func(...interface{}) string { return "" }
+	}
+
+	result := make(map[string]struct{})
+	userVariablesCalledWalk(tpl.Tree.Root, result)
+	return result, nil
+}
+
+func userVariablesCalledWalk(raw parse.Node, r map[string]struct{}) {
+	switch node := raw.(type) {
+	case *parse.ActionNode:
+		userVariablesCalledWalk(node.Pipe, r)
+	case *parse.CommandNode:
+		if in, ok := node.Args[0].(*parse.IdentifierNode); ok && in.Ident == "user" {
+			if len(node.Args) > 1 {
+				if s, ok := node.Args[1].(*parse.StringNode); ok {
+					r[s.Text] = struct{}{}
+				}
+			}
+		}
+
+		for _, n := range node.Args[1:] {
+			userVariablesCalledWalk(n, r)
+		}
+	case *parse.IfNode:
+		userVariablesCalledWalk(&node.BranchNode, r)
+	case *parse.RangeNode:
+		userVariablesCalledWalk(&node.BranchNode, r)
+	case *parse.WithNode:
+		userVariablesCalledWalk(&node.BranchNode, r)
+	case *parse.BranchNode:
+		userVariablesCalledWalk(node.Pipe, r)
+		userVariablesCalledWalk(node.List, r)
+		if node.ElseList != nil {
+			userVariablesCalledWalk(node.ElseList, r)
+		}
+	case *parse.ListNode:
+		for _, n := range node.Nodes {
+			userVariablesCalledWalk(n, r)
+		}
+	case *parse.PipeNode:
+		for _, n := range node.Cmds {
+			userVariablesCalledWalk(n, r)
+		}
+	}
+}
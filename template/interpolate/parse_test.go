@@ -37,3 +37,52 @@ func TestFunctionsCalled(t *testing.T) {
 		}
 	}
 }
+
+func TestUserVariablesCalled(t *testing.T) {
+	cases := []struct {
+		Input  string
+		Result map[string]struct{}
+	}{
+		{
+			"foo",
+			map[string]struct{}{},
+		},
+
+		{
+			"foo {{user `bar`}} {{ user \"baz\" | upper }}",
+			map[string]struct{}{
+				"bar": {},
+				"baz": {},
+			},
+		},
+
+		{
+			"{{ if .Foo }}{{ user `a` }}{{ else }}{{ build_name }}{{ end }}",
+			map[string]struct{}{
+				"a": {},
+			},
+		},
+
+		{
+			"{{ clean_name (user `name`) }}",
+			map[string]struct{}{
+				"name": {},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		actual, err := UserVariablesCalled(tc.Input)
+		if err != nil {
+			t.Fatalf("err: %s\n\n%s", tc.Input, err)
+		}
+
+		if !reflect.DeepEqual(actual, tc.Result) {
+			t.Fatalf("bad: %v\n\ngot: %#v", tc.Input, actual)
+		}
+	}
+
+	if _, err := UserVariablesCalled("{{ user `a` "); err == nil {
+		t.Fatal("expected error for invalid template")
+	}
+}
@@ -29,6 +29,8 @@ type rawTemplate struct {
 	Provisioners   []map[string]interface{}
 	Variables      map[string]interface{}
 
+	SensitiveVariables []string `mapstructure:"sensitive-variables"`
+
 	RawContents []byte
 }
 
@@ -53,16 +55,51 @@ func (r *rawTemplate) Template() (*Template, error) {
 		// Variable is required if the value is exactly nil
 		v.Required = rawV == nil
 
-		// Weak decode the default if we have one
-		if err := r.decoder(&v.Default, nil).Decode(rawV); err != nil {
+		// Lists and maps are typed variables, their defaults are stored
+		// JSON encoded. Everything else is weak decoded into a string.
+		var typed interface{}
+		switch rawV.(type) {
+		case []interface{}:
+			v.Type = VariableTypeList
+			typed = new([]string)
+		case map[string]interface{}:
+			v.Type = VariableTypeMap
+			typed = new(map[string]string)
+		default:
+			typed = &v.Default
+		}
+
+		if err := r.decoder(typed, nil).Decode(rawV); err != nil {
 			errs = multierror.Append(errs, fmt.Errorf(
 				"variable %s: %s", k, err))
 			continue
 		}
 
+		if v.Type != "" {
+			def, err := json.Marshal(typed)
+			if err != nil {
+				errs = multierror.Append(errs, fmt.Errorf(
+					"variable %s: %s", k, err))
+				continue
+			}
+			v.Default = string(def)
+		}
+
 		result.Variables[k] = &v
 	}
 
+	// Mark the sensitive variables
+	for _, k := range r.SensitiveVariables {
+		v, ok := result.Variables[k]
+		if !ok {
+			errs = multierror.Append(errs, fmt.Errorf(
+				"sensitive variable %s: not a variable", k))
+			continue
+		}
+
+		v.Sensitive = true
+	}
+
 	// Let's start by gathering all the builders
 	if len(r.Builders) > 0 {
 		result.Builders = make(map[string]*Builder, len(r.Builders))
@@ -141,6 +178,7 @@ func (r *rawTemplate) Template() (*Template, error) {
 			delete(c, "except")
 			delete(c, "only")
 			delete(c, "keep_input_artifact")
+			delete(c, "verify_checksums")
 			delete(c, "type")
 			if len(c) > 0 {
 				pp.Config = c
@@ -173,9 +211,11 @@ func (r *rawTemplate) Template() (*Template, error) {
 
 		// Copy the configuration
 		delete(v, "except")
+		delete(v, "max_retries")
 		delete(v, "only")
 		delete(v, "override")
 		delete(v, "pause_before")
+		delete(v, "timeout")
 		delete(v, "type")
 		if len(v) > 0 {
 			p.Config = v
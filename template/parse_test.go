@@ -8,6 +8,10 @@ import (
 	"time"
 )
 
+func boolPointer(v bool) *bool {
+	return &v
+}
+
 func TestParse(t *testing.T) {
 	cases := []struct {
 		File   string
@@ -68,6 +72,32 @@ func TestParse(t *testing.T) {
 			false,
 		},
 
+		{
+			"parse-provisioner-timeout.json",
+			&Template{
+				Provisioners: []*Provisioner{
+					{
+						Type:    "something",
+						Timeout: 5 * time.Minute,
+					},
+				},
+			},
+			false,
+		},
+
+		{
+			"parse-provisioner-max-retries.json",
+			&Template{
+				Provisioners: []*Provisioner{
+					{
+						Type:       "something",
+						MaxRetries: 3,
+					},
+				},
+			},
+			false,
+		},
+
 		{
 			"parse-provisioner-only.json",
 			&Template{
@@ -131,6 +161,29 @@ func TestParse(t *testing.T) {
 			false,
 		},
 
+		{
+			"parse-variable-typed.json",
+			&Template{
+				Variables: map[string]*Variable{
+					"isos": {
+						Default: `["a.iso","b.iso"]`,
+						Type:    VariableTypeList,
+					},
+					"tags": {
+						Default: `{"os":"linux"}`,
+						Type:    VariableTypeMap,
+					},
+				},
+			},
+			false,
+		},
+
+		{
+			"parse-variable-bad-list.json",
+			nil,
+			true,
+		},
+
 		{
 			"parse-variable-required.json",
 			&Template{
@@ -143,6 +196,28 @@ func TestParse(t *testing.T) {
 			false,
 		},
 
+		{
+			"parse-variable-sensitive.json",
+			&Template{
+				Variables: map[string]*Variable{
+					"foo": {
+						Default: "foo",
+					},
+					"password": {
+						Default:   "secret",
+						Sensitive: true,
+					},
+				},
+			},
+			false,
+		},
+
+		{
+			"parse-variable-sensitive-unknown.json",
+			nil,
+			true,
+		},
+
 		{
 			"parse-pp-basic.json",
 			&Template{
@@ -167,7 +242,22 @@ func TestParse(t *testing.T) {
 					{
 						{
 							Type:              "foo",
-							KeepInputArtifact: true,
+							KeepInputArtifact: boolPointer(true),
+						},
+					},
+				},
+			},
+			false,
+		},
+
+		{
+			"parse-pp-verify.json",
+			&Template{
+				PostProcessors: [][]*PostProcessor{
+					{
+						{
+							Type:            "foo",
+							VerifyChecksums: true,
 						},
 					},
 				},
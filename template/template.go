@@ -1,11 +1,13 @@
 package template
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
+	"github.com/mitchellh/packer/template/interpolate"
 )
 
 // Template represents the parsed template that is used to configure
@@ -39,9 +41,16 @@ type Builder struct {
 type PostProcessor struct {
 	OnlyExcept `mapstructure:",squash"`
 
-	Type              string
-	KeepInputArtifact bool `mapstructure:"keep_input_artifact"`
-	Config            map[string]interface{}
+	Type   string
+	Config map[string]interface{}
+
+	// KeepInputArtifact is nil unless keep_input_artifact was set, in which
+	// case it overrides whether the post-processor keeps its input.
+	KeepInputArtifact *bool `mapstructure:"keep_input_artifact"`
+
+	// VerifyChecksums verifies the input artifact's recorded checksums
+	// before running the post-processor.
+	VerifyChecksums bool `mapstructure:"verify_checksums"`
 }
 
 // Provisioner represents a provisioner within the template.
@@ -52,6 +61,8 @@ type Provisioner struct {
 	Config      map[string]interface{}
 	Override    map[string]interface{}
 	PauseBefore time.Duration `mapstructure:"pause_before"`
+	Timeout     time.Duration `mapstructure:"timeout"`
+	MaxRetries  int           `mapstructure:"max_retries"`
 }
 
 // Push represents the configuration for pushing the template to Atlas.
@@ -69,6 +80,41 @@ type Push struct {
 type Variable struct {
 	Default  string
 	Required bool
+
+	// Type is the type of the variable, VariableTypeList or
+	// VariableTypeMap, or empty for strings.
+	Type string
+
+	// Sensitive variables have their values redacted from the output
+	// and the logs.
+	Sensitive bool
+}
+
+// The types of variables that aren't strings. User variables are always
+// passed around as strings, so the values of lists and maps are JSON
+// encoded. helper/config decodes them into slices and maps.
+const (
+	VariableTypeList = "list"
+	VariableTypeMap  = "map"
+)
+
+// ValidateValue checks that a value given for the variable, such as with
+// -var, matches the type of the variable.
+func (v *Variable) ValidateValue(value string) error {
+	switch v.Type {
+	case VariableTypeList:
+		var list []string
+		if err := json.Unmarshal([]byte(value), &list); err != nil {
+			return fmt.Errorf("must be a JSON list of strings: %s", err)
+		}
+	case VariableTypeMap:
+		var m map[string]string
+		if err := json.Unmarshal([]byte(value), &m); err != nil {
+			return fmt.Errorf("must be a JSON object of strings: %s", err)
+		}
+	}
+
+	return nil
 }
 
 // OnlyExcept is a struct that is meant to be embedded that contains the
@@ -106,6 +152,11 @@ func (t *Template) Validate() error {
 			}
 		}
 
+		if p.MaxRetries < 0 {
+			err = multierror.Append(err, fmt.Errorf(
+				"provisioner %d: max_retries must not be negative", i+1))
+		}
+
 		// Validate overrides
 		for name := range p.Override {
 			if _, ok := t.Builders[name]; !ok {
@@ -132,6 +183,43 @@ func (t *Template) Validate() error {
 	return err
 }
 
+// UserVariablesCalled returns a map (to be used as a set) of the names of
+// the user variables that are read anywhere in the template. Strings that
+// aren't valid configuration templates are ignored, since they'll fail
+// when the template is used.
+func (t *Template) UserVariablesCalled() (map[string]struct{}, error) {
+	var raw interface{}
+	if err := json.Unmarshal(t.RawContents, &raw); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]struct{})
+	userVariablesCalledWalk(raw, result)
+	return result, nil
+}
+
+func userVariablesCalledWalk(raw interface{}, r map[string]struct{}) {
+	switch v := raw.(type) {
+	case string:
+		called, err := interpolate.UserVariablesCalled(v)
+		if err != nil {
+			return
+		}
+
+		for n := range called {
+			r[n] = struct{}{}
+		}
+	case []interface{}:
+		for _, e := range v {
+			userVariablesCalledWalk(e, r)
+		}
+	case map[string]interface{}:
+		for _, e := range v {
+			userVariablesCalledWalk(e, r)
+		}
+	}
+}
+
 // Skip says whether or not to skip the build with the given name.
 func (o *OnlyExcept) Skip(n string) bool {
 	if len(o.Only) > 0 {
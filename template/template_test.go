@@ -3,6 +3,7 @@ package template
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -38,6 +39,11 @@ func TestTemplateValidate(t *testing.T) {
 			true,
 		},
 
+		{
+			"validate-bad-prov-max-retries.json",
+			true,
+		},
+
 		{
 			"validate-good-prov-only.json",
 			false,
@@ -93,6 +99,27 @@ func TestTemplateValidate(t *testing.T) {
 	}
 }
 
+func TestTemplateUserVariablesCalled(t *testing.T) {
+	tpl, err := ParseFile(fixtureDir("user-variables-called.json"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual, err := tpl.UserVariablesCalled()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]struct{}{
+		"foo":    {},
+		"port":   {},
+		"nested": {},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
 func TestOnlyExceptSkip(t *testing.T) {
 	cases := []struct {
 		Only, Except []string